@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/youruser/yourproject/internal/adapter/repository/postgres"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+// runLedgerCheck implements the `ledger-check` subcommand, which re-sums the
+// journal and exits non-zero on any inconsistency
+func runLedgerCheck() int {
+	ctx := context.Background()
+	dbPool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to connect to database:", err)
+		return 1
+	}
+	defer dbPool.Close()
+
+	// The check only reads the journal, so it needs no payment service
+	ledger := services.NewLedgerService(postgres.NewLedgerRepository(dbPool), postgres.NewPaymentRepository(dbPool), nil)
+	mismatches, err := ledger.CheckConsistency(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "consistency check failed:", err)
+		return 1
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("ledger is consistent")
+		return 0
+	}
+
+	for _, m := range mismatches {
+		fmt.Println(m.String())
+	}
+	fmt.Fprintf(os.Stderr, "%d inconsistencies found\n", len(mismatches))
+	return 1
+}
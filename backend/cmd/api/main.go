@@ -5,36 +5,62 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/contrib/otelfiber"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/csrf"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/redis/go-redis/v9"
+	"github.com/youruser/yourproject/internal/adapter/cache/redisstore"
+	"github.com/youruser/yourproject/internal/adapter/email/smtp"
 	httphandler "github.com/youruser/yourproject/internal/adapter/handler/http"
 	"github.com/youruser/yourproject/internal/adapter/handler/http/middleware"
-	"github.com/youruser/yourproject/internal/adapter/payment/cardtocard"
+	"github.com/youruser/yourproject/internal/adapter/payment/idpay"
+	"github.com/youruser/yourproject/internal/adapter/payment/mellat"
+	"github.com/youruser/yourproject/internal/adapter/payment/nextpay"
+	"github.com/youruser/yourproject/internal/adapter/payment/payir"
+	"github.com/youruser/yourproject/internal/adapter/payment/saman"
+	"github.com/youruser/yourproject/internal/adapter/payment/sandbox"
 	"github.com/youruser/yourproject/internal/adapter/payment/vandar"
 	"github.com/youruser/yourproject/internal/adapter/payment/zarinpal"
+	"github.com/youruser/yourproject/internal/adapter/payout/manual"
+	"github.com/youruser/yourproject/internal/adapter/pdf"
 	"github.com/youruser/yourproject/internal/adapter/repository/postgres"
 	"github.com/youruser/yourproject/internal/adapter/sms/senator"
 	"github.com/youruser/yourproject/internal/adapter/storage/s3"
+	"github.com/youruser/yourproject/internal/adapter/webhook/httpsender"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
 	"github.com/youruser/yourproject/pkg/logger"
+	"github.com/youruser/yourproject/pkg/resilience"
 	"github.com/youruser/yourproject/pkg/telemetry"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 func main() {
 	// 1. Initialize Logger
 	logger.InitLogger()
+
+	// CLI subcommands
+	if len(os.Args) > 1 && os.Args[1] == "rbac-sync" {
+		os.Exit(runRBACSync(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ledger-check" {
+		os.Exit(runLedgerCheck())
+	}
+
 	logger.Log.Info("Starting application...")
 
 	// 2. Initialize Telemetry (OpenTelemetry)
@@ -62,26 +88,281 @@ func main() {
 
 	// Repositories
 	userRepo := postgres.NewUserRepository(dbPool)
+	rbacRepo := postgres.NewRBACRepository(dbPool)
+	orgRepo := postgres.NewOrganizationRepository(dbPool)
+	paymentRepo := postgres.NewPaymentRepository(dbPool)
+	refundRepo := postgres.NewRefundRepository(dbPool)
+	receiptRepo := postgres.NewCardReceiptRepository(dbPool)
+	ledgerRepo := postgres.NewLedgerRepository(dbPool)
+	riskRepo := postgres.NewRiskRepository(dbPool)
+	paymentReceiptRepo := postgres.NewPaymentReceiptRepository(dbPool)
+	billingRepo := postgres.NewBillingRepository(dbPool)
+	couponRepo := postgres.NewCouponRepository(dbPool)
+	payoutRepo := postgres.NewPayoutRepository(dbPool)
+	webhookRepo := postgres.NewWebhookRepository(dbPool)
+	permVersions := redisstore.NewPermissionVersionStore(rdb)
+
+	distributedLock := redisstore.NewLock(rdb)
+
+	// Reconcile roles and permissions with the declarative policy
+	syncRBACPolicyOnStartup(context.Background(), rbacRepo, permVersions, distributedLock)
+
+	// Background workers are stopped on shutdown through this context
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// Expired role grants are ignored by RBAC checks and swept periodically
+	sweepInterval, err := time.ParseDuration(os.Getenv("RBAC_GRANT_SWEEP_INTERVAL"))
+	if err != nil || sweepInterval <= 0 {
+		sweepInterval = 5 * time.Minute
+	}
+	go services.NewRoleGrantSweeper(rbacRepo, permVersions, sweepInterval).Run(workerCtx)
 
 	// 4. Initialize Adapters
-	s3Adapter, err := s3.NewS3Adapter()
+	// Breakers of every outbound dependency, listed at /api/admin/breakers
+	breakers := resilience.NewRegistry()
+	s3Adapter, err := s3.NewS3Adapter(guardClient(breakers, &http.Client{}, "s3"))
 	if err != nil {
 		logger.Log.Error("Failed to init S3 adapter", zap.Error(err))
 	}
-	
+
 	// Payment Adapters
 	zarinpalAdapter := zarinpal.NewZarinpalAdapter(os.Getenv("ZARINPAL_MERCHANT_ID"))
 	vandarAdapter := vandar.NewVandarAdapter(os.Getenv("VANDAR_API_KEY"))
-	cardToCardAdapter := cardtocard.NewCardToCardAdapter()
+	vandarAdapter.Business = os.Getenv("VANDAR_BUSINESS")
+	vandarAdapter.AccessToken = os.Getenv("VANDAR_ACCESS_TOKEN")
+	idpayAdapter := idpay.NewIDPayAdapter(os.Getenv("IDPAY_API_KEY"))
+	if baseURL := os.Getenv("IDPAY_BASE_URL"); baseURL != "" {
+		idpayAdapter.BaseURL = baseURL
+	}
+	idpayAdapter.Sandbox = os.Getenv("IDPAY_SANDBOX") == "true"
+	payirAdapter := payir.NewPayIRAdapter(os.Getenv("PAYIR_API_KEY"))
+	if baseURL := os.Getenv("PAYIR_BASE_URL"); baseURL != "" {
+		payirAdapter.BaseURL = baseURL
+	}
+	nextpayAdapter := nextpay.NewNextPayAdapter(os.Getenv("NEXTPAY_API_KEY"))
+	if baseURL := os.Getenv("NEXTPAY_BASE_URL"); baseURL != "" {
+		nextpayAdapter.BaseURL = baseURL
+	}
+	mellatTerminalID, _ := strconv.ParseInt(os.Getenv("MELLAT_TERMINAL_ID"), 10, 64)
+	mellatAdapter := mellat.NewMellatAdapter(mellatTerminalID, os.Getenv("MELLAT_USERNAME"), os.Getenv("MELLAT_PASSWORD"))
+	if baseURL := os.Getenv("MELLAT_BASE_URL"); baseURL != "" {
+		mellatAdapter.BaseURL = baseURL
+	}
+	samanAdapter := saman.NewSamanAdapter(os.Getenv("SAMAN_TERMINAL_ID"))
+	if baseURL := os.Getenv("SAMAN_BASE_URL"); baseURL != "" {
+		samanAdapter.BaseURL = baseURL
+	}
+	// Dev mode serves a fake IPG under /_dev/pay for the sandbox gateway;
+	// it must stay off in production
+	devMode := os.Getenv("DEV_MODE") == "true"
+	sandboxAdapter := sandbox.NewSandboxAdapter(os.Getenv("SANDBOX_GATEWAY_URL"))
+	if devMode && sandboxAdapter.BaseURL == "" {
+		sandboxAdapter.BaseURL = "http://localhost:8080/_dev/pay"
+	}
+
+	// Every outbound client gets its own breaker, timeouts and retries,
+	// configured with OUTBOUND_<NAME>_* variables
+	guardClient(breakers, zarinpalAdapter.Client, services.GatewayZarinpal)
+	guardClient(breakers, vandarAdapter.Client, services.GatewayVandar)
+	guardClient(breakers, idpayAdapter.Client, services.GatewayIDPay)
+	guardClient(breakers, payirAdapter.Client, services.GatewayPayIR)
+	guardClient(breakers, nextpayAdapter.Client, services.GatewayNextPay)
+	guardClient(breakers, mellatAdapter.Client, services.GatewayMellat)
+	guardClient(breakers, samanAdapter.Client, services.GatewaySaman)
+	guardClient(breakers, sandboxAdapter.Client, services.GatewaySandbox)
 
 	// SMS Adapter
 	smsAdapter := senator.NewSenatorAdapter()
+	guardClient(breakers, smsAdapter.Client, "senator")
 
-	// Handlers
-	authHandler := httphandler.NewAuthHandler(smsAdapter, rdb, userRepo)
+	// Email Adapter
+	emailAdapter := smtp.NewSMTPAdapter()
+
+	// WebSocket hub, also used to push events to users
 	wsHandler := httphandler.NewWebSocketHandler()
 	go wsHandler.Run()
 
+	// Services
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	var enabledGateways []string
+	if names := os.Getenv("PAYMENT_GATEWAYS"); names != "" {
+		enabledGateways = strings.Split(names, ",")
+	}
+	gatewayRegistry := services.NewGatewayRegistry(enabledGateways)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayZarinpal, DisplayName: "Zarinpal"}, zarinpalAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayVandar, DisplayName: "Vandar"}, vandarAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayIDPay, DisplayName: "IDPay"}, idpayAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayPayIR, DisplayName: "Pay.ir"}, payirAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayNextPay, DisplayName: "NextPay"}, nextpayAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewayMellat, DisplayName: "Behpardakht Mellat"}, mellatAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewaySaman, DisplayName: "Saman"}, samanAdapter)
+	gatewayRegistry.Register(services.GatewayInfo{Name: services.GatewaySandbox, DisplayName: "Sandbox"}, sandboxAdapter)
+
+	paymentService := services.NewPaymentService(paymentRepo, gatewayRegistry, wsHandler)
+	refundService := services.NewRefundService(paymentService, refundRepo, distributedLock)
+	riskService := services.NewRiskService(riskRules(), riskRepo, userRepo)
+	paymentService.SetRiskService(riskService)
+	couponService := services.NewCouponService(couponRepo)
+	paymentService.SetCouponService(couponService)
+	paymentService.OnStatusChange(couponService.HandlePayment)
+
+	// Merchant systems are told about payments and refunds through signed
+	// webhooks, sent from a queue in the database and retried with backoff
+	webhookPollInterval, err := time.ParseDuration(os.Getenv("WEBHOOK_POLL_INTERVAL"))
+	if err != nil || webhookPollInterval <= 0 {
+		webhookPollInterval = 10 * time.Second
+	}
+	webhookSender := httpsender.NewSender(os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true")
+	webhookService := services.NewWebhookService(webhookRepo, paymentRepo, webhookSender, services.WebhookConfig{
+		PollInterval: webhookPollInterval,
+		Concurrency:  4,
+		BatchSize:    100,
+	})
+	paymentService.OnStatusChange(webhookService.HandlePayment)
+	refundService.OnStatusChange(webhookService.HandleRefund)
+	go webhookService.Run(workerCtx)
+
+	// Receipts are checked against the bucket; without one they are refused
+	var fileStorage ports.FileStorage
+	if s3Adapter != nil {
+		fileStorage = s3Adapter
+	}
+	ledgerService := services.NewLedgerService(ledgerRepo, paymentRepo, paymentService)
+	paymentService.OnStatusChange(ledgerService.HandlePayment)
+	refundService.SetLedgerService(ledgerService)
+
+	// Payouts go out through Vandar settlements when its business API is
+	// configured, or as bank batch files finance uploads by hand
+	payoutSyncInterval, err := time.ParseDuration(os.Getenv("PAYOUT_SYNC_INTERVAL"))
+	if err != nil || payoutSyncInterval <= 0 {
+		payoutSyncInterval = 10 * time.Minute
+	}
+	payoutService := services.NewPayoutService(payoutRepo, ledgerService, distributedLock, services.PayoutConfig{
+		SyncInterval: payoutSyncInterval,
+		BatchSize:    100,
+	})
+	vandarSettlement := vandar.NewSettlementAdapter(vandarAdapter.Business, vandarAdapter.AccessToken)
+	guardClient(breakers, vandarSettlement.Client, "vandar_settlement")
+	payoutService.RegisterGateway(services.PayoutGatewayVandar, vandarSettlement)
+	payoutService.RegisterGateway(services.PayoutGatewayManual, manual.NewExportAdapter(os.Getenv("PAYOUT_SOURCE_SHEBA")))
+	cardToCardService := services.NewCardToCardService(paymentService, receiptRepo, userRepo, fileStorage, smsAdapter)
+
+	reconcileInterval, err := time.ParseDuration(os.Getenv("PAYMENT_RECONCILE_INTERVAL"))
+	if err != nil || reconcileInterval <= 0 {
+		reconcileInterval = 5 * time.Minute
+	}
+	reconcileStaleAfter, err := time.ParseDuration(os.Getenv("PAYMENT_RECONCILE_STALE_AFTER"))
+	if err != nil || reconcileStaleAfter <= 0 {
+		reconcileStaleAfter = 15 * time.Minute
+	}
+	reconcileExpireAfter, err := time.ParseDuration(os.Getenv("PAYMENT_RECONCILE_EXPIRE_AFTER"))
+	if err != nil || reconcileExpireAfter <= 0 {
+		reconcileExpireAfter = 2 * time.Hour
+	}
+	go services.NewPaymentReconciler(paymentService, distributedLock, services.ReconcilerConfig{
+		Interval:    reconcileInterval,
+		StaleAfter:  reconcileStaleAfter,
+		ExpireAfter: reconcileExpireAfter,
+		BatchSize:   100,
+	}).Run(workerCtx)
+	go payoutService.Run(workerCtx)
+
+	billingLinkSecret := os.Getenv("BILLING_LINK_SECRET")
+	if billingLinkSecret == "" {
+		logger.Log.Warn("BILLING_LINK_SECRET is not set; invoice pay links stop working on restart")
+		billingLinkSecret = uuid.NewString()
+	}
+	billingPayLinkBaseURL := os.Getenv("BILLING_PAY_LINK_BASE_URL")
+	if billingPayLinkBaseURL == "" {
+		billingPayLinkBaseURL = "http://localhost:8080/api/billing/invoices"
+	}
+	billingService := services.NewBillingService(billingRepo, paymentService, userRepo, emailAdapter, smsAdapter, services.BillingConfig{
+		PayLinkBaseURL: billingPayLinkBaseURL,
+		LinkSecret:     []byte(billingLinkSecret),
+	})
+	billingInterval, err := time.ParseDuration(os.Getenv("BILLING_INTERVAL"))
+	if err != nil || billingInterval <= 0 {
+		billingInterval = time.Hour
+	}
+	billingRenewalLead, err := time.ParseDuration(os.Getenv("BILLING_RENEWAL_LEAD"))
+	if err != nil || billingRenewalLead <= 0 {
+		billingRenewalLead = 72 * time.Hour
+	}
+	billingRemindEvery, err := time.ParseDuration(os.Getenv("BILLING_REMIND_EVERY"))
+	if err != nil || billingRemindEvery <= 0 {
+		billingRemindEvery = 24 * time.Hour
+	}
+	go services.NewBillingScheduler(billingService, distributedLock, services.BillingSchedulerConfig{
+		Interval:    billingInterval,
+		RenewalLead: billingRenewalLead,
+		RemindEvery: billingRemindEvery,
+		BatchSize:   100,
+	}).Run(workerCtx)
+
+	// PDF receipts need the bucket and a font with Persian glyphs
+	var receiptService *services.PaymentReceiptService
+	receiptRenderer, err := pdf.NewReceiptRenderer()
+	switch {
+	case err != nil:
+		logger.Log.Warn("Payment receipts are disabled", zap.Error(err))
+	case fileStorage == nil:
+		logger.Log.Warn("Payment receipts are disabled; storage is not configured")
+	default:
+		receiptLinkTTL, err := time.ParseDuration(os.Getenv("RECEIPT_LINK_TTL"))
+		if err != nil || receiptLinkTTL <= 0 {
+			receiptLinkTTL = 5 * time.Minute
+		}
+		receiptService = services.NewPaymentReceiptService(paymentReceiptRepo, paymentRepo, userRepo, receiptRenderer, fileStorage, receiptLinkTTL)
+		paymentService.OnStatusChange(receiptService.HandlePayment)
+	}
+	orgService := services.NewOrganizationService(orgRepo, rbacRepo, userRepo, permVersions, emailAdapter, smsAdapter, frontendURL+"/invitations/accept")
+
+	// Handlers
+	authHandler := httphandler.NewAuthHandler(smsAdapter, rdb, userRepo, rbacRepo, permVersions)
+	adminHandler := httphandler.NewAdminHandler(rbacRepo, permVersions)
+	adminHandler.Breakers = breakers
+	refundHandler := httphandler.NewRefundHandler(refundService, refundRepo)
+	receiptHandler := httphandler.NewCardReceiptHandler(cardToCardService, receiptRepo)
+	riskHandler := httphandler.NewRiskHandler(riskService, paymentService, refundService, paymentRepo)
+	payoutHandler := httphandler.NewPayoutHandler(payoutService, payoutRepo)
+	orgHandler := httphandler.NewOrganizationHandler(orgService, orgRepo)
+	callbackBaseURL := os.Getenv("PAYMENT_CALLBACK_BASE_URL")
+	if callbackBaseURL == "" {
+		callbackBaseURL = "http://localhost:8080/api/payments"
+	}
+	resultURL := os.Getenv("PAYMENT_RESULT_URL")
+	if resultURL == "" {
+		resultURL = frontendURL + "/payment/result"
+	}
+	walletHandler := httphandler.NewWalletHandler(ledgerService, userRepo, callbackBaseURL)
+	paymentHandler := httphandler.NewPaymentHandler(paymentService, paymentRepo, userRepo, gatewayRegistry, cardToCardService, callbackBaseURL, resultURL)
+	paymentHandler.Receipts = receiptService
+	billingHandler := httphandler.NewBillingHandler(billingService, billingRepo, gatewayRegistry, callbackBaseURL)
+	couponHandler := httphandler.NewCouponHandler(couponRepo, billingRepo)
+	webhookHandler := httphandler.NewWebhookHandler(webhookService, webhookRepo)
+	rbacMiddleware := middleware.NewRBACMiddleware(rbacRepo, permVersions)
+
+	tenantConfig := middleware.DefaultTenantConfig()
+	if strategies := os.Getenv("TENANT_RESOLUTION"); strategies != "" {
+		tenantConfig.Strategies = strings.Split(strategies, ",")
+	}
+	if header := os.Getenv("TENANT_HEADER"); header != "" {
+		tenantConfig.Header = header
+	}
+	tenantConfig.BaseDomain = os.Getenv("TENANT_BASE_DOMAIN")
+	tenantMiddleware := middleware.NewTenantMiddleware(orgRepo, tenantConfig)
+	subscriptionMiddleware := middleware.NewSubscriptionMiddleware(billingRepo)
+
+	idempotencyTTL, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || idempotencyTTL <= 0 {
+		idempotencyTTL = 24 * time.Hour
+	}
+	idempotency := middleware.NewIdempotencyMiddleware(redisstore.NewIdempotencyStore(rdb), idempotencyTTL)
+
 	// 5. Initialize Fiber App
 	app := fiber.New(fiber.Config{
 		AppName: "Go Clean Arch Boilerplate",
@@ -101,6 +382,12 @@ func main() {
 	// CSRF for non-API routes (if any) or configured for API
 	app.Use(csrf.New(csrf.Config{
 		KeyLookup: "header:X-CSRF-Token",
+		// Gateways post callbacks from their own pages without a token, as
+		// does the dev-mode fake IPG
+		Next: func(c *fiber.Ctx) bool {
+			return (strings.HasPrefix(c.Path(), "/api/payments/") && strings.HasSuffix(c.Path(), "/callback")) ||
+				(devMode && strings.HasPrefix(c.Path(), "/_dev/"))
+		},
 	}))
 	app.Use(otelfiber.Middleware()) // OpenTelemetry Middleware
 
@@ -139,6 +426,88 @@ func main() {
 	auth.Post("/2fa/enable", middleware.Protected(), authHandler.Enable2FA)
 	auth.Post("/2fa/verify", middleware.Protected(), authHandler.Verify2FALogin)
 
+	// Admin Routes
+	admin := api.Group("/admin", middleware.Protected(), rbacMiddleware.RequirePermission("admin:access"))
+	admin.Get("/role-grants", adminHandler.ListRoleGrants)
+	admin.Get("/users/:id/roles", adminHandler.GetUserRoleGrants)
+	admin.Post("/users/:id/roles", adminHandler.GrantRole)
+	admin.Delete("/users/:id/roles/:role", adminHandler.RevokeRole)
+	admin.Get("/authz/explain", adminHandler.ExplainAuthz)
+	admin.Get("/breakers", adminHandler.ListBreakers)
+
+	// Refunds (manual refunds wait in the queue until finance completes them)
+	canRefund := rbacMiddleware.RequirePermission("payments:refund")
+	admin.Get("/payments/:id/refunds", canRefund, refundHandler.ListForPayment)
+	admin.Post("/payments/:id/refunds", canRefund, refundHandler.Create)
+	admin.Get("/refunds", canRefund, refundHandler.List)
+	admin.Post("/refunds/:id/complete", canRefund, refundHandler.Complete)
+	admin.Post("/refunds/:id/reject", canRefund, refundHandler.Reject)
+	admin.Post("/wallets/:id/refunds", canRefund, walletHandler.AdminRefund)
+
+	// Card-to-card review queue
+	canReview := rbacMiddleware.RequirePermission("payments:review")
+	admin.Get("/card-receipts", canReview, receiptHandler.List)
+	admin.Get("/card-receipts/:id", canReview, receiptHandler.Get)
+	admin.Post("/card-receipts/:id/approve", canReview, receiptHandler.Approve)
+	admin.Post("/card-receipts/:id/reject", canReview, receiptHandler.Reject)
+
+	// Payments held by the risk checks; rejecting one refunds it
+	admin.Get("/risk/held", canReview, riskHandler.ListHeld)
+	admin.Post("/risk/held/:id/release", canReview, riskHandler.Release)
+	admin.Post("/risk/held/:id/reject", canReview, canRefund, riskHandler.Reject)
+	admin.Get("/risk/blocklist", canReview, riskHandler.ListBlocklist)
+	admin.Post("/risk/blocklist", canReview, riskHandler.Block)
+	admin.Delete("/risk/blocklist/:id", canReview, riskHandler.Unblock)
+
+	// Subscription plans
+	canManageBilling := rbacMiddleware.RequirePermission("billing:manage")
+	admin.Get("/plans", canManageBilling, billingHandler.AdminListPlans)
+	admin.Post("/plans", canManageBilling, billingHandler.AdminCreatePlan)
+	admin.Put("/plans/:id", canManageBilling, billingHandler.AdminUpdatePlan)
+
+	// Discount coupons
+	canManageCoupons := rbacMiddleware.RequirePermission("coupons:manage")
+	admin.Get("/coupons", canManageCoupons, couponHandler.List)
+	admin.Post("/coupons", canManageCoupons, couponHandler.Create)
+	admin.Get("/coupons/:id", canManageCoupons, couponHandler.Get)
+	admin.Put("/coupons/:id", canManageCoupons, couponHandler.Update)
+	admin.Delete("/coupons/:id", canManageCoupons, couponHandler.Delete)
+	admin.Get("/coupons/:id/redemptions", canManageCoupons, couponHandler.ListRedemptions)
+
+	// Payouts are approved by one reviewer and then dispatched in batches;
+	// finance settles those sent as bank files
+	canApprovePayouts := rbacMiddleware.RequirePermission("payouts:approve")
+	canProcessPayouts := rbacMiddleware.RequirePermission("payouts:process")
+	admin.Get("/payouts", canApprovePayouts, payoutHandler.AdminList)
+	admin.Post("/payouts/:id/approve", canApprovePayouts, payoutHandler.Approve)
+	admin.Post("/payouts/:id/reject", canApprovePayouts, payoutHandler.Reject)
+	admin.Get("/payouts/gateways", canProcessPayouts, payoutHandler.ListGateways)
+	admin.Post("/payouts/dispatch", canProcessPayouts, payoutHandler.Dispatch)
+	admin.Get("/payouts/batches/:id/file", canProcessPayouts, payoutHandler.BatchFile)
+	admin.Post("/payouts/:id/complete", canProcessPayouts, payoutHandler.Complete)
+	admin.Post("/payouts/:id/fail", canProcessPayouts, payoutHandler.Fail)
+
+	// App-wide webhook endpoints receive the events of every payment
+	canManageWebhooks := rbacMiddleware.RequirePermission("webhooks:manage")
+	registerWebhookRoutes(admin, canManageWebhooks, webhookHandler)
+
+	// Organization Routes
+	orgs := api.Group("/orgs", middleware.Protected())
+	orgs.Post("/", orgHandler.Create)
+	orgs.Get("/", orgHandler.List)
+	api.Post("/invitations/accept", middleware.Protected(), orgHandler.AcceptInvitation)
+
+	// Tenant Routes (roles are evaluated in the resolved organization)
+	tenant := orgs.Group("/:tenant", tenantMiddleware.Resolve())
+	tenant.Get("/", rbacMiddleware.RequirePermission("orgs:read"), orgHandler.Get)
+	tenant.Get("/members", rbacMiddleware.RequirePermission("orgs:read"), orgHandler.ListMembers)
+	tenant.Delete("/members/:userId", rbacMiddleware.RequirePermission("orgs:manage"), orgHandler.RemoveMember)
+	tenant.Post("/invitations", rbacMiddleware.RequirePermission("orgs:invite"), orgHandler.Invite)
+	// Payments started here belong to the organization and are announced
+	// to its webhook endpoints
+	tenant.Post("/payments", rbacMiddleware.RequirePermission("payments:write"), idempotency.Handle(), paymentHandler.Create)
+	registerWebhookRoutes(tenant, canManageWebhooks, webhookHandler)
+
 	// Example Protected Route
 	api.Get("/protected", middleware.Protected(), func(c *fiber.Ctx) error {
 		userID := c.Locals("user_id")
@@ -155,6 +524,10 @@ func main() {
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "No file uploaded"})
 		}
+		// Receipt images are only uploaded through their own route
+		if services.IsCardReceiptKey(fileHeader.Filename) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid filename"})
+		}
 
 		file, err := fileHeader.Open()
 		if err != nil {
@@ -188,7 +561,7 @@ func main() {
 	})
 
 	// Presigned URL Route
-	api.Post("/upload/presigned", middleware.Protected(), func(c *fiber.Ctx) error {
+	api.Post("/upload/presigned", middleware.Protected(), idempotency.Handle(), func(c *fiber.Ctx) error {
 		type Request struct {
 			Filename string `json:"filename"`
 		}
@@ -200,6 +573,10 @@ func main() {
 		if s3Adapter == nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Storage not configured"})
 		}
+		// Receipt images are only uploaded through their own route
+		if services.IsCardReceiptKey(req.Filename) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid filename"})
+		}
 
 		key, err := s3Adapter.GeneratePresignedURL(c.Context(), req.Filename, 300)
 		if err != nil {
@@ -210,70 +587,56 @@ func main() {
 	})
 
 	// Payment Routes
-	payments := api.Group("/payments", middleware.Protected())
-	
-	// Zarinpal Request
-	payments.Post("/zarinpal/request", func(c *fiber.Ctx) error {
-		type PaymentReq struct {
-			Amount int64 `json:"amount"`
-			Desc   string `json:"description"`
-		}
-		var req PaymentReq
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
-		}
-		
-		// In prod: Get user email from context/db
-		url, authority, err := zarinpalAdapter.RequestPayment(c.Context(), req.Amount, "http://localhost:3000/payment/callback", req.Desc, "user@example.com")
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		
-		return c.JSON(fiber.Map{"payment_url": url, "authority": authority})
-	})
+	// Gateway callbacks are public and must be registered before the
+	// protected group so its middleware does not run for them
+	api.Get("/payments/:gateway/callback", paymentHandler.Callback)
+	api.Post("/payments/:gateway/callback", paymentHandler.Callback)
 
-	// Vandar Request
-	payments.Post("/vandar/request", func(c *fiber.Ctx) error {
-		type PaymentReq struct {
-			Amount int64 `json:"amount"`
-			Desc   string `json:"description"`
-			Mobile string `json:"mobile"`
-		}
-		var req PaymentReq
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
-		}
-
-		url, token, err := vandarAdapter.RequestPayment(c.Context(), req.Amount, "http://localhost:3000/payment/callback/vandar", req.Desc, req.Mobile)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		return c.JSON(fiber.Map{"payment_url": url, "token": token})
+	payments := api.Group("/payments", middleware.Protected())
+	payments.Get("/gateways", paymentHandler.ListGateways)
+	payments.Post("/", idempotency.Handle(), paymentHandler.Create)
+	payments.Post("/card-to-card/upload", paymentHandler.CardToCardUpload)
+	payments.Post("/card-to-card", idempotency.Handle(), paymentHandler.SubmitCardToCard)
+	payments.Get("/:id", paymentHandler.Get)
+	payments.Get("/:id/receipt", paymentHandler.Receipt)
+
+	// Wallet Routes
+	wallet := api.Group("/wallet", middleware.Protected())
+	wallet.Get("/", walletHandler.Balance)
+	wallet.Get("/history", walletHandler.History)
+	wallet.Post("/topups", idempotency.Handle(), walletHandler.TopUp)
+	wallet.Post("/topups/:id/claim", walletHandler.ClaimTopUp)
+
+	// Payouts withdraw wallet credit to the user's bank account
+	payouts := api.Group("/payouts", middleware.Protected(), rbacMiddleware.RequirePermission("payouts:request"))
+	payouts.Get("/sheba/:sheba", payoutHandler.CheckSheba)
+	payouts.Post("/", idempotency.Handle(), payoutHandler.Create)
+	payouts.Get("/", payoutHandler.List)
+	payouts.Get("/:id", payoutHandler.Get)
+	payouts.Post("/:id/cancel", payoutHandler.Cancel)
+
+	// Billing Routes
+	api.Get("/billing/plans", billingHandler.ListPlans)
+	// Reminder pay links are signed and work without logging in
+	api.Get("/billing/invoices/:id/pay", billingHandler.PayLink)
+
+	billing := api.Group("/billing", middleware.Protected())
+	billing.Get("/subscription", billingHandler.GetSubscription)
+	billing.Post("/subscription", billingHandler.Subscribe)
+	billing.Post("/subscription/change", billingHandler.ChangePlan)
+	billing.Post("/subscription/cancel", billingHandler.Cancel)
+	billing.Get("/invoices", billingHandler.ListInvoices)
+	billing.Post("/invoices/:id/pay", idempotency.Handle(), billingHandler.PayInvoice)
+
+	// Example of a feature gated behind a plan
+	api.Get("/premium", middleware.Protected(), subscriptionMiddleware.RequireActiveSubscription("pro"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "Welcome to the premium area", "plan": c.Locals("plan")})
 	})
 
-	// Card to Card Submission
-	payments.Post("/card-to-card", func(c *fiber.Ctx) error {
-		type C2CReq struct {
-			Amount      int64  `json:"amount"`
-			ReceiptURL  string `json:"receipt_url"`
-			Description string `json:"description"`
-		}
-		var req C2CReq
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
-		}
-
-		// In prod: Get User ID from JWT claims
-		userID := "user-123" 
-		
-		txID, err := cardToCardAdapter.SubmitReceipt(c.Context(), userID, req.Amount, req.ReceiptURL, req.Description)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		return c.JSON(fiber.Map{"transaction_id": txID, "status": "pending_approval"})
-	})
+	// Fake IPG for the sandbox gateway
+	if devMode {
+		app.All("/_dev/pay/*", adaptor.HTTPHandler(http.StripPrefix("/_dev/pay", sandbox.NewServer())))
+	}
 
 	// 8. Graceful Shutdown
 	go func() {
@@ -288,6 +651,7 @@ func main() {
 	<-quit
 
 	logger.Log.Info("Shutting down server...")
+	stopWorkers()
 
 	// Give the server a deadline for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
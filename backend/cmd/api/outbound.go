@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+// outboundPolicy returns the resilience policy for a dependency, overriding
+// the defaults with OUTBOUND_<NAME>_* variables. Unset, invalid or
+// non-positive values keep the default.
+func outboundPolicy(name string) resilience.Policy {
+	prefix := "OUTBOUND_" + strings.ToUpper(name) + "_"
+	policy := resilience.DefaultPolicy()
+
+	envFloat := func(key string, target *float64) {
+		if f, err := strconv.ParseFloat(os.Getenv(prefix+key), 64); err == nil && f > 0 && f <= 1 {
+			*target = f
+		}
+	}
+	envDuration := func(key string, target *time.Duration) {
+		if d, err := time.ParseDuration(os.Getenv(prefix + key)); err == nil && d > 0 {
+			*target = d
+		}
+	}
+	envInt := func(key string, target *int) {
+		if n, err := strconv.Atoi(os.Getenv(prefix + key)); err == nil && n > 0 {
+			*target = n
+		}
+	}
+
+	envDuration("TIMEOUT", &policy.Timeout)
+	envDuration("WINDOW", &policy.Breaker.Window)
+	envFloat("FAILURE_RATE", &policy.Breaker.FailureRate)
+	envInt("MIN_REQUESTS", &policy.Breaker.MinRequests)
+	envDuration("RESET_TIMEOUT", &policy.Breaker.ResetTimeout)
+	envInt("HALF_OPEN_PROBES", &policy.Breaker.HalfOpenProbes)
+	envInt("MAX_ATTEMPTS", &policy.MaxAttempts)
+	envDuration("BASE_DELAY", &policy.BaseDelay)
+	envDuration("MAX_DELAY", &policy.MaxDelay)
+	return policy
+}
+
+// guardClient routes client's requests through the named dependency and
+// registers its breaker. The policy's per-attempt timeout replaces the
+// client's overall one, which would otherwise cut retries short.
+func guardClient(breakers *resilience.Registry, client *http.Client, name string) *http.Client {
+	dependency := resilience.NewDependency(name, outboundPolicy(name))
+	breakers.Register(dependency.Breaker())
+	client.Transport = resilience.NewTransport(dependency)
+	client.Timeout = 0
+	return client
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/youruser/yourproject/internal/adapter/cache/redisstore"
+	"github.com/youruser/yourproject/internal/adapter/policy"
+	"github.com/youruser/yourproject/internal/adapter/repository/postgres"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+	"github.com/youruser/yourproject/pkg/logger"
+)
+
+// rbacPolicyFile returns the path of the declarative RBAC policy
+func rbacPolicyFile() string {
+	if path := os.Getenv("RBAC_POLICY_FILE"); path != "" {
+		return path
+	}
+	return "rbac.yaml"
+}
+
+// syncRBACPolicyOnStartup reconciles the database with the policy file if
+// one is present. Blocked deletions are logged and left for the CLI.
+func syncRBACPolicyOnStartup(ctx context.Context, rbacRepo ports.RBACRepository, versions ports.PermissionVersionStore, lock ports.DistributedLock) {
+	if os.Getenv("RBAC_SYNC_ON_STARTUP") == "false" {
+		return
+	}
+
+	path := rbacPolicyFile()
+	rbacPolicy, err := policy.LoadRBACPolicy(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Log.Info("No RBAC policy file found, skipping sync", zap.String("path", path))
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Failed to load RBAC policy", zap.String("path", path), zap.Error(err))
+		return
+	}
+
+	diff, err := services.NewRBACSyncer(rbacRepo, versions, lock).Sync(ctx, rbacPolicy, services.RBACSyncOptions{})
+	if errors.Is(err, services.ErrRBACSyncInProgress) {
+		logger.Log.Info("RBAC policy is being synced by another replica, skipping")
+		return
+	}
+	if errors.Is(err, services.ErrRBACSyncBlocked) {
+		for _, change := range diff.Blocked() {
+			logger.Log.Warn("RBAC sync skipped change still in use, run rbac-sync -force to apply", zap.String("change", change.String()))
+		}
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Failed to sync RBAC policy", zap.Error(err))
+		return
+	}
+	if !diff.IsEmpty() {
+		logger.Log.Info("RBAC policy synced", zap.Int("changes", len(diff.Changes)))
+	}
+}
+
+// runRBACSync implements the `rbac-sync` subcommand and returns the exit code
+func runRBACSync(args []string) int {
+	fs := flag.NewFlagSet("rbac-sync", flag.ContinueOnError)
+	file := fs.String("file", rbacPolicyFile(), "path to the RBAC policy file")
+	dryRun := fs.Bool("dry-run", false, "print the diff without applying it")
+	force := fs.Bool("force", false, "delete roles and permissions that are still in use")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	rbacPolicy, err := policy.LoadRBACPolicy(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load policy:", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	dbPool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to connect to database:", err)
+		return 1
+	}
+	defer dbPool.Close()
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: os.Getenv("REDIS_ADDR"),
+	})
+	defer rdb.Close()
+
+	syncer := services.NewRBACSyncer(postgres.NewRBACRepository(dbPool), redisstore.NewPermissionVersionStore(rdb), redisstore.NewLock(rdb))
+	diff, err := syncer.Sync(ctx, rbacPolicy, services.RBACSyncOptions{
+		DryRun: *dryRun,
+		Force:  *force,
+	})
+	if diff != nil {
+		fmt.Println(diff.String())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *dryRun && len(diff.Blocked()) > 0 {
+		fmt.Fprintln(os.Stderr, "some deletions are still referenced and need -force")
+	}
+	return 0
+}
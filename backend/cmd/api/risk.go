@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// riskRules returns the payment risk rules, overriding the defaults with
+// RISK_* variables. Zero disables a limit; unset or invalid values keep the
+// default.
+func riskRules() domain.RiskRules {
+	rules := domain.DefaultRiskRules()
+
+	envDuration := func(key string, target *time.Duration) {
+		if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d >= 0 {
+			*target = d
+		}
+	}
+	envInt := func(key string, target *int64) {
+		if n, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && n >= 0 {
+			*target = n
+		}
+	}
+
+	envDuration("RISK_VELOCITY_WINDOW", &rules.VelocityWindow)
+	perUser, perIP := int64(rules.MaxPaymentsPerUser), int64(rules.MaxPaymentsPerIP)
+	envInt("RISK_MAX_PAYMENTS_PER_USER", &perUser)
+	envInt("RISK_MAX_PAYMENTS_PER_IP", &perIP)
+	rules.MaxPaymentsPerUser, rules.MaxPaymentsPerIP = int(perUser), int(perIP)
+	envInt("RISK_MAX_DAILY_AMOUNT", &rules.MaxDailyAmount)
+	envDuration("RISK_NEW_ACCOUNT_PERIOD", &rules.NewAccountPeriod)
+	envInt("RISK_NEW_ACCOUNT_MAX_AMOUNT", &rules.NewAccountMaxAmount)
+	return rules
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/gofiber/fiber/v2"
+	httphandler "github.com/youruser/yourproject/internal/adapter/handler/http"
+)
+
+// registerWebhookRoutes mounts the webhook endpoint and delivery log routes
+// on group. Under a tenant they manage the organization's endpoints; under
+// the admin group, the app-wide ones.
+func registerWebhookRoutes(group fiber.Router, canManage fiber.Handler, h *httphandler.WebhookHandler) {
+	group.Get("/webhooks/events", canManage, h.ListEvents)
+	group.Get("/webhooks/deliveries/:id", canManage, h.GetDelivery)
+	group.Post("/webhooks/deliveries/:id/replay", canManage, h.Replay)
+	group.Get("/webhooks", canManage, h.List)
+	group.Post("/webhooks", canManage, h.Create)
+	group.Get("/webhooks/:id", canManage, h.Get)
+	group.Put("/webhooks/:id", canManage, h.Update)
+	group.Delete("/webhooks/:id", canManage, h.Delete)
+	group.Post("/webhooks/:id/rotate-secret", canManage, h.RotateSecret)
+	group.Get("/webhooks/:id/deliveries", canManage, h.ListDeliveries)
+}
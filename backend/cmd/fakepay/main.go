@@ -0,0 +1,40 @@
+// Command fakepay serves the sandbox gateway's fake IPG on its own, for
+// development and end-to-end tests against a running API. Point the API at
+// it with SANDBOX_GATEWAY_URL.
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/youruser/yourproject/internal/adapter/payment/sandbox"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger.InitLogger()
+
+	addr := os.Getenv("FAKEPAY_ADDR")
+	if addr == "" {
+		addr = ":8090"
+	}
+
+	server := sandbox.NewServer()
+	if outcome := sandbox.Outcome(os.Getenv("FAKEPAY_OUTCOME")); outcome != "" {
+		if !outcome.Valid() {
+			logger.Log.Fatal("Unknown FAKEPAY_OUTCOME", zap.String("outcome", string(outcome)))
+		}
+		server.Outcome = outcome
+	}
+	delay, err := time.ParseDuration(os.Getenv("FAKEPAY_TIMEOUT_DELAY"))
+	if err == nil && delay > 0 {
+		server.Delay = delay
+	}
+
+	logger.Log.Info("Fake IPG listening", zap.String("addr", addr), zap.String("outcome", string(server.Outcome)))
+	if err := http.ListenAndServe(addr, server); err != nil {
+		logger.Log.Fatal("Fake IPG stopped", zap.Error(err))
+	}
+}
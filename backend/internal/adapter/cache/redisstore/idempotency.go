@@ -0,0 +1,98 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const idempotencyKeyPrefix = "idempotency:"
+
+// completeScript replaces a record only while it is still reserved by the
+// caller; ARGV holds the owner, the new record and its TTL in milliseconds,
+// or no record to delete it
+var completeScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return 0
+end
+local record = cjson.decode(current)
+if record.Completed or record.Owner ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] == nil then
+	return redis.call("DEL", KEYS[1])
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// IdempotencyStore keeps idempotency records as JSON values. Keys are
+// reserved with SETNX so only one concurrent request can own them, and only
+// their owner may complete or release them.
+type IdempotencyStore struct {
+	rdb *redis.Client
+}
+
+func NewIdempotencyStore(rdb *redis.Client) ports.IdempotencyStore {
+	return &IdempotencyStore{rdb: rdb}
+}
+
+func (s *IdempotencyStore) Begin(ctx context.Context, key, fingerprint, owner string, lockTTL time.Duration) (*ports.IdempotencyRecord, error) {
+	data, err := json.Marshal(&ports.IdempotencyRecord{Fingerprint: fingerprint, Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.rdb.SetNX(ctx, idempotencyKeyPrefix+key, data, lockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, nil
+	}
+
+	existing, err := s.rdb.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Expired between SETNX and GET; report it as in progress so the
+		// client retries instead of racing another request
+		return &ports.IdempotencyRecord{Fingerprint: fingerprint}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record ports.IdempotencyRecord
+	if err := json.Unmarshal(existing, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key, owner string, record *ports.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.compareAndSet(ctx, key, owner, string(data), ttl.Milliseconds())
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key, owner string) error {
+	return s.compareAndSet(ctx, key, owner)
+}
+
+// compareAndSet runs completeScript and reports a key owned by someone else
+func (s *IdempotencyStore) compareAndSet(ctx context.Context, key, owner string, args ...interface{}) error {
+	changed, err := completeScript.Run(ctx, s.rdb, []string{idempotencyKeyPrefix + key}, append([]interface{}{owner}, args...)...).Int()
+	if err != nil {
+		return err
+	}
+	if changed == 0 {
+		return ports.ErrIdempotencyKeyLost
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package redisstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const lockKeyPrefix = "lock:"
+
+// unlockScript deletes the lock only if it still holds the caller's token,
+// so a holder whose lock expired cannot release someone else's
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock is a single-instance Redis lock built on SET NX PX
+type Lock struct {
+	rdb *redis.Client
+}
+
+func NewLock(rdb *redis.Client) ports.DistributedLock {
+	return &Lock{rdb: rdb}
+}
+
+func (l *Lock) TryLock(ctx context.Context, name string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	key := lockKeyPrefix + name
+	token := uuid.New().String()
+
+	ok, err := l.rdb.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock := func(ctx context.Context) error {
+		return unlockScript.Run(ctx, l.rdb, []string{key}, token).Err()
+	}
+	return unlock, true, nil
+}
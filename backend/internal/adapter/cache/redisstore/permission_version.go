@@ -0,0 +1,52 @@
+package redisstore
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const (
+	globalVersionKey     = "authz:version"
+	userVersionKeyPrefix = "authz:version:user:"
+)
+
+// PermissionVersionStore keeps permission versions in two Redis counters: a
+// global one and a per-user one. A user's version is their sum, which only
+// ever grows, so bumping either counter invalidates issued tokens.
+type PermissionVersionStore struct {
+	rdb *redis.Client
+}
+
+func NewPermissionVersionStore(rdb *redis.Client) ports.PermissionVersionStore {
+	return &PermissionVersionStore{rdb: rdb}
+}
+
+func (s *PermissionVersionStore) UserVersion(ctx context.Context, userID string) (int64, error) {
+	values, err := s.rdb.MGet(ctx, globalVersionKey, userVersionKeyPrefix+userID).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		n, err := redis.NewStringResult(v.(string), nil).Int64()
+		if err != nil {
+			return 0, err
+		}
+		version += n
+	}
+	return version, nil
+}
+
+func (s *PermissionVersionStore) BumpUser(ctx context.Context, userID string) error {
+	return s.rdb.Incr(ctx, userVersionKeyPrefix+userID).Err()
+}
+
+func (s *PermissionVersionStore) BumpAll(ctx context.Context) error {
+	return s.rdb.Incr(ctx, globalVersionKey).Err()
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+type AdminHandler struct {
+	RBACRepo    ports.RBACRepository
+	PermVersion ports.PermissionVersionStore
+	Authz       *services.AuthzExplainer
+	// Breakers lists the outbound circuit breakers; optional
+	Breakers *resilience.Registry
+}
+
+func NewAdminHandler(rbacRepo ports.RBACRepository, permVersion ports.PermissionVersionStore) *AdminHandler {
+	return &AdminHandler{
+		RBACRepo:    rbacRepo,
+		PermVersion: permVersion,
+		Authz:       services.NewAuthzExplainer(rbacRepo),
+	}
+}
+
+type roleGrantResponse struct {
+	UserID    string     `json:"user_id"`
+	RoleID    string     `json:"role_id"`
+	Role      string     `json:"role"`
+	Scope     string     `json:"scope"`
+	GrantedBy string     `json:"granted_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func toRoleGrantResponses(grants []domain.UserRole) []roleGrantResponse {
+	resp := make([]roleGrantResponse, 0, len(grants))
+	for _, g := range grants {
+		resp = append(resp, roleGrantResponse{
+			UserID:    g.UserID,
+			RoleID:    g.RoleID,
+			Role:      g.RoleName,
+			Scope:     g.Scope,
+			GrantedBy: g.GrantedBy,
+			ExpiresAt: g.ExpiresAt,
+			CreatedAt: g.CreatedAt,
+		})
+	}
+	return resp
+}
+
+// ListRoleGrants lists role grants, optionally filtered by user, role, granter or scope
+func (h *AdminHandler) ListRoleGrants(c *fiber.Ctx) error {
+	filter := domain.RoleGrantFilter{
+		UserID:         c.Query("user_id"),
+		RoleID:         c.Query("role_id"),
+		GrantedBy:      c.Query("granted_by"),
+		IncludeExpired: c.QueryBool("include_expired", false),
+	}
+	if c.Context().QueryArgs().Has("scope") {
+		scope := c.Query("scope")
+		filter.Scope = &scope
+	}
+
+	grants, err := h.RBACRepo.ListRoleGrants(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list role grants"})
+	}
+
+	return c.JSON(fiber.Map{"grants": toRoleGrantResponses(grants)})
+}
+
+// GetUserRoleGrants lists the active role grants of a single user
+func (h *AdminHandler) GetUserRoleGrants(c *fiber.Ctx) error {
+	grants, err := h.RBACRepo.ListRoleGrants(c.UserContext(), domain.RoleGrantFilter{
+		UserID:         c.Params("id"),
+		IncludeExpired: c.QueryBool("include_expired", false),
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list role grants"})
+	}
+
+	return c.JSON(fiber.Map{"grants": toRoleGrantResponses(grants)})
+}
+
+// GrantRole assigns a role to a user, optionally scoped and time-bound
+func (h *AdminHandler) GrantRole(c *fiber.Ctx) error {
+	granterID, _ := c.Locals("user_id").(string)
+
+	type Request struct {
+		Role      string     `json:"role"`
+		Scope     string     `json:"scope"`
+		ExpiresAt *time.Time `json:"expires_at"`
+		TTL       string     `json:"ttl"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil || req.Role == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	expiresAt := req.ExpiresAt
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid ttl"})
+		}
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return c.Status(400).JSON(fiber.Map{"error": "Expiry must be in the future"})
+	}
+
+	role, err := h.RBACRepo.GetRoleByName(c.UserContext(), req.Role)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+
+	grant := &domain.UserRole{
+		UserID:    c.Params("id"),
+		RoleID:    role.ID,
+		RoleName:  role.Name,
+		Scope:     req.Scope,
+		GrantedBy: granterID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := h.RBACRepo.GrantRole(c.UserContext(), grant); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to grant role"})
+	}
+	if err := h.PermVersion.BumpUser(c.UserContext(), grant.UserID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Role granted but token invalidation failed"})
+	}
+
+	return c.Status(201).JSON(toRoleGrantResponses([]domain.UserRole{*grant})[0])
+}
+
+// RevokeRole removes a role grant from a user in the given scope
+func (h *AdminHandler) RevokeRole(c *fiber.Ctx) error {
+	role, err := h.RBACRepo.GetRoleByName(c.UserContext(), c.Params("role"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+
+	if err := h.RBACRepo.RevokeRoleGrant(c.UserContext(), c.Params("id"), role.ID, c.Query("scope")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke role"})
+	}
+	if err := h.PermVersion.BumpUser(c.UserContext(), c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Role revoked but token invalidation failed"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Role revoked"})
+}
+
+// ExplainAuthz explains whether a user holds a permission and which grants
+// allowed or denied it
+func (h *AdminHandler) ExplainAuthz(c *fiber.Ctx) error {
+	userID := c.Query("user")
+	permission := c.Query("permission")
+	if userID == "" || permission == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "user and permission are required"})
+	}
+
+	decision, err := h.Authz.Explain(c.UserContext(), userID, permission, c.Query("scope"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to explain decision"})
+	}
+
+	type evaluation struct {
+		roleGrantResponse
+		Permissions []string `json:"permissions"`
+		Grants      bool     `json:"grants"`
+		Reason      string   `json:"reason"`
+	}
+	evaluations := make([]evaluation, 0, len(decision.Evaluations))
+	for _, e := range decision.Evaluations {
+		evaluations = append(evaluations, evaluation{
+			roleGrantResponse: toRoleGrantResponses([]domain.UserRole{e.Grant})[0],
+			Permissions:       e.Permissions,
+			Grants:            e.Grants,
+			Reason:            e.Reason,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user_id":     decision.UserID,
+		"permission":  decision.Permission,
+		"scope":       decision.Scope,
+		"allowed":     decision.Allowed,
+		"reason":      decision.Reason,
+		"evaluations": evaluations,
+	})
+}
+
+type breakerResponse struct {
+	Name     string    `json:"name"`
+	State    string    `json:"state"`
+	Since    time.Time `json:"since"`
+	Requests int       `json:"requests"`
+	Failures int       `json:"failures"`
+}
+
+// ListBreakers reports the state of every outbound dependency's circuit
+// breaker, with the calls counted in its current window
+func (h *AdminHandler) ListBreakers(c *fiber.Ctx) error {
+	resp := make([]breakerResponse, 0)
+	if h.Breakers != nil {
+		for _, s := range h.Breakers.Statuses() {
+			resp = append(resp, breakerResponse{
+				Name:     s.Name,
+				State:    s.State.String(),
+				Since:    s.Since,
+				Requests: s.Requests,
+				Failures: s.Failures,
+			})
+		}
+	}
+	return c.JSON(fiber.Map{"breakers": resp})
+}
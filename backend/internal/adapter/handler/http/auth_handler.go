@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,16 +16,24 @@ import (
 )
 
 type AuthHandler struct {
-	SMSGateway ports.SMSGateway
-	Redis      *redis.Client
-	UserRepo   ports.UserRepository
+	SMSGateway  ports.SMSGateway
+	Redis       *redis.Client
+	UserRepo    ports.UserRepository
+	RBACRepo    ports.RBACRepository
+	PermVersion ports.PermissionVersionStore
+	// EmbedPermissions adds the user's roles and permissions to access tokens
+	// so read-heavy services can authorize from claims alone
+	EmbedPermissions bool
 }
 
-func NewAuthHandler(sms ports.SMSGateway, rdb *redis.Client, userRepo ports.UserRepository) *AuthHandler {
+func NewAuthHandler(sms ports.SMSGateway, rdb *redis.Client, userRepo ports.UserRepository, rbacRepo ports.RBACRepository, permVersion ports.PermissionVersionStore) *AuthHandler {
 	return &AuthHandler{
-		SMSGateway: sms,
-		Redis:      rdb,
-		UserRepo:   userRepo,
+		SMSGateway:       sms,
+		Redis:            rdb,
+		UserRepo:         userRepo,
+		RBACRepo:         rbacRepo,
+		PermVersion:      permVersion,
+		EmbedPermissions: os.Getenv("JWT_EMBED_PERMISSIONS") == "true",
 	}
 }
 
@@ -98,7 +107,7 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 
 	// Check 2FA
 	if user.IsTwoFactorEnabled {
-		tempToken, _ := generateToken(user.ID, true)
+		tempToken, _ := generateToken(user.ID, true, nil)
 		return c.JSON(fiber.Map{
 			"2fa_required": true,
 			"temp_token":   tempToken,
@@ -106,7 +115,7 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 	}
 
 	// Generate final JWT
-	token, _ := generateToken(user.ID, false)
+	token, _ := generateToken(user.ID, false, h.permissionClaims(context.Background(), user.ID))
 
 	return c.JSON(fiber.Map{"token": token})
 }
@@ -195,11 +204,59 @@ func (h *AuthHandler) Verify2FALogin(c *fiber.Ctx) error {
 	}
 
 	// Generate final JWT
-	token, _ := generateToken(user.ID, false)
+	token, _ := generateToken(user.ID, false, h.permissionClaims(context.Background(), user.ID))
 	return c.JSON(fiber.Map{"token": token})
 }
 
-func generateToken(userID string, isTemp bool) (string, error) {
+// permissionClaims returns the role, permission and permission-version
+// claims for a user, or nil when embedding is disabled or cannot be done
+// consistently. Tokens without them fall back to database checks.
+func (h *AuthHandler) permissionClaims(ctx context.Context, userID string) jwt.MapClaims {
+	if !h.EmbedPermissions {
+		return nil
+	}
+
+	// Read the version first so a concurrent change makes the token stale
+	// rather than silently missing from it
+	version, err := h.PermVersion.UserVersion(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	grants, err := h.RBACRepo.ListRoleGrants(ctx, domain.RoleGrantFilter{UserID: userID, Scope: new(string)})
+	if err != nil {
+		return nil
+	}
+	permissions, err := h.RBACRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil
+	}
+
+	roles := make([]string, 0, len(grants))
+	var validUntil *time.Time
+	for _, g := range grants {
+		roles = append(roles, g.RoleName)
+		if g.ExpiresAt != nil && (validUntil == nil || g.ExpiresAt.Before(*validUntil)) {
+			validUntil = g.ExpiresAt
+		}
+	}
+	perms := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		perms = append(perms, p.Name)
+	}
+
+	claims := jwt.MapClaims{
+		"roles": roles,
+		"perms": perms,
+		"pv":    version,
+	}
+	// Time-bound grants stop counting at their expiry, not at the token's
+	if validUntil != nil {
+		claims["pv_exp"] = validUntil.Unix()
+	}
+	return claims
+}
+
+func generateToken(userID string, isTemp bool, extra jwt.MapClaims) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"exp":     time.Now().Add(72 * time.Hour).Unix(),
@@ -208,6 +265,9 @@ func generateToken(userID string, isTemp bool) (string, error) {
 		claims["is_temp"] = true
 		claims["exp"] = time.Now().Add(5 * time.Minute).Unix()
 	}
+	for k, v := range extra {
+		claims[k] = v
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte("your-256-bit-secret"))
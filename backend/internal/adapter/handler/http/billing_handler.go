@@ -0,0 +1,359 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+type BillingHandler struct {
+	Billing     *services.BillingService
+	BillingRepo ports.BillingRepository
+	Gateways    *services.GatewayRegistry
+
+	// CallbackBaseURL is the public URL of the payments API, as in
+	// PaymentHandler
+	CallbackBaseURL string
+}
+
+func NewBillingHandler(billing *services.BillingService, billingRepo ports.BillingRepository, gateways *services.GatewayRegistry, callbackBaseURL string) *BillingHandler {
+	return &BillingHandler{Billing: billing, BillingRepo: billingRepo, Gateways: gateways, CallbackBaseURL: callbackBaseURL}
+}
+
+func (h *BillingHandler) callbackURL(gateway string) string {
+	return fmt.Sprintf("%s/%s/callback", h.CallbackBaseURL, gateway)
+}
+
+type planResponse struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+	PeriodDays int    `json:"period_days"`
+	GraceDays  int    `json:"grace_days"`
+	Active     bool   `json:"active"`
+}
+
+func toPlanResponse(p *domain.Plan) planResponse {
+	return planResponse{
+		ID:         p.ID,
+		Code:       p.Code,
+		Name:       p.Name,
+		Price:      p.Price,
+		PeriodDays: p.PeriodDays,
+		GraceDays:  p.GraceDays,
+		Active:     p.Active,
+	}
+}
+
+type subscriptionResponse struct {
+	ID                 string     `json:"id"`
+	Plan               string     `json:"plan"`
+	PendingPlanID      string     `json:"pending_plan_id,omitempty"`
+	Status             string     `json:"status"`
+	CurrentPeriodStart *time.Time `json:"current_period_start,omitempty"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end"`
+	Entitled           bool       `json:"entitled"`
+}
+
+func toSubscriptionResponse(s *domain.Subscription, plan *domain.Plan) subscriptionResponse {
+	resp := subscriptionResponse{
+		ID:                s.ID,
+		Plan:              plan.Code,
+		PendingPlanID:     s.PendingPlanID,
+		Status:            string(s.Status),
+		CancelAtPeriodEnd: s.CancelAtPeriodEnd,
+		Entitled:          s.Entitled(time.Now(), plan.GracePeriod()),
+	}
+	if !s.CurrentPeriodStart.IsZero() {
+		resp.CurrentPeriodStart = &s.CurrentPeriodStart
+		resp.CurrentPeriodEnd = &s.CurrentPeriodEnd
+	}
+	return resp
+}
+
+type invoiceResponse struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscription_id"`
+	PlanID         string     `json:"plan_id"`
+	Kind           string     `json:"kind"`
+	Amount         int64      `json:"amount"`
+	Status         string     `json:"status"`
+	PeriodStart    time.Time  `json:"period_start"`
+	PeriodEnd      time.Time  `json:"period_end"`
+	DueAt          time.Time  `json:"due_at"`
+	PaymentID      string     `json:"payment_id,omitempty"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toInvoiceResponse(i *domain.Invoice) invoiceResponse {
+	return invoiceResponse{
+		ID:             i.ID,
+		SubscriptionID: i.SubscriptionID,
+		PlanID:         i.PlanID,
+		Kind:           string(i.Kind),
+		Amount:         i.Amount,
+		Status:         string(i.Status),
+		PeriodStart:    i.PeriodStart,
+		PeriodEnd:      i.PeriodEnd,
+		DueAt:          i.DueAt,
+		PaymentID:      i.PaymentID,
+		PaidAt:         i.PaidAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+// billingError maps billing errors to HTTP responses
+func billingError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPlan):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPayLink):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrPlanNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Plan not found"})
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Subscription not found"})
+	case errors.Is(err, domain.ErrInvoiceNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Invoice not found"})
+	case errors.Is(err, domain.ErrAlreadySubscribed), errors.Is(err, domain.ErrInvalidSubscriptionState),
+		errors.Is(err, domain.ErrInvoiceNotPayable), errors.Is(err, domain.ErrPlanCodeTaken):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return paymentError(c, err)
+}
+
+// ListPlans returns the plans users can subscribe to
+func (h *BillingHandler) ListPlans(c *fiber.Ctx) error {
+	plans, err := h.Billing.Plans(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list plans"})
+	}
+
+	resp := make([]planResponse, 0, len(plans))
+	for i := range plans {
+		resp = append(resp, toPlanResponse(&plans[i]))
+	}
+	return c.JSON(fiber.Map{"plans": resp})
+}
+
+// GetSubscription returns the current user's subscription
+func (h *BillingHandler) GetSubscription(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	sub, plan, err := h.Billing.Current(c.UserContext(), userID)
+	if err != nil {
+		return billingError(c, err)
+	}
+	return c.JSON(toSubscriptionResponse(sub, plan))
+}
+
+type planChangeRequest struct {
+	Plan string `json:"plan"`
+}
+
+// subscriptionResult answers subscribe and plan change requests with the
+// invoice to pay, if any
+func (h *BillingHandler) subscriptionResult(c *fiber.Ctx, sub *domain.Subscription, inv *domain.Invoice) error {
+	plan, err := h.BillingRepo.GetPlan(c.UserContext(), sub.PlanID)
+	if err != nil {
+		return billingError(c, err)
+	}
+	resp := fiber.Map{"subscription": toSubscriptionResponse(sub, plan)}
+	if inv != nil {
+		resp["invoice"] = toInvoiceResponse(inv)
+	}
+	return c.JSON(resp)
+}
+
+// Subscribe starts a subscription to a plan; paid plans return the initial
+// invoice to pay
+func (h *BillingHandler) Subscribe(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req planChangeRequest
+	if err := c.BodyParser(&req); err != nil || req.Plan == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Plan is required"})
+	}
+
+	sub, inv, err := h.Billing.Subscribe(c.UserContext(), userID, req.Plan)
+	if err != nil {
+		return billingError(c, err)
+	}
+	return h.subscriptionResult(c, sub, inv)
+}
+
+// ChangePlan switches the current subscription to another plan; upgrades
+// return the prorated invoice to pay
+func (h *BillingHandler) ChangePlan(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req planChangeRequest
+	if err := c.BodyParser(&req); err != nil || req.Plan == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Plan is required"})
+	}
+
+	sub, inv, err := h.Billing.ChangePlan(c.UserContext(), userID, req.Plan)
+	if err != nil {
+		return billingError(c, err)
+	}
+	return h.subscriptionResult(c, sub, inv)
+}
+
+// Cancel stops the current subscription at the end of its period
+func (h *BillingHandler) Cancel(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	sub, err := h.Billing.Cancel(c.UserContext(), userID)
+	if err != nil {
+		return billingError(c, err)
+	}
+	return h.subscriptionResult(c, sub, nil)
+}
+
+// ListInvoices returns the current user's invoices
+func (h *BillingHandler) ListInvoices(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	invoices, err := h.Billing.Invoices(c.UserContext(), userID, limit, offset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list invoices"})
+	}
+
+	resp := make([]invoiceResponse, 0, len(invoices))
+	for i := range invoices {
+		resp = append(resp, toInvoiceResponse(&invoices[i]))
+	}
+	return c.JSON(fiber.Map{"invoices": resp, "limit": limit, "offset": offset})
+}
+
+// PayInvoice starts a payment of one of the current user's invoices
+func (h *BillingHandler) PayInvoice(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type PayReq struct {
+		Gateway    string `json:"gateway"`
+		CouponCode string `json:"coupon_code"`
+	}
+	var req PayReq
+	if err := c.BodyParser(&req); err != nil || req.Gateway == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Gateway is required"})
+	}
+
+	payment, result, err := h.Billing.PayInvoice(c.UserContext(), userID, c.Params("id"), req.Gateway, h.callbackURL(req.Gateway), req.CouponCode)
+	if err != nil {
+		return billingError(c, err)
+	}
+	return paymentStarted(c, 200, payment, result)
+}
+
+// PayLink serves the signed links sent in invoice reminders: it starts a
+// payment through the requested gateway, or the first available one, and
+// sends the user to it
+func (h *BillingHandler) PayLink(c *fiber.Ctx) error {
+	gateway := c.Query("gateway")
+	if gateway == "" {
+		gateways := h.Gateways.List()
+		if len(gateways) == 0 {
+			return paymentError(c, domain.ErrGatewayUnavailable)
+		}
+		gateway = gateways[0].Name
+	}
+
+	_, result, err := h.Billing.PayInvoiceWithLink(c.UserContext(), c.Params("id"), c.Query("token"), gateway, h.callbackURL(gateway))
+	if err != nil {
+		return billingError(c, err)
+	}
+	return redirectToGateway(c, result)
+}
+
+type planRequest struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+	PeriodDays int    `json:"period_days"`
+	GraceDays  int    `json:"grace_days"`
+	Active     *bool  `json:"active"`
+}
+
+// AdminListPlans returns every plan, including retired ones
+func (h *BillingHandler) AdminListPlans(c *fiber.Ctx) error {
+	plans, err := h.BillingRepo.ListPlans(c.UserContext(), false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list plans"})
+	}
+
+	resp := make([]planResponse, 0, len(plans))
+	for i := range plans {
+		resp = append(resp, toPlanResponse(&plans[i]))
+	}
+	return c.JSON(fiber.Map{"plans": resp})
+}
+
+// AdminCreatePlan adds a plan
+func (h *BillingHandler) AdminCreatePlan(c *fiber.Ctx) error {
+	var req planRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	plan, err := domain.NewPlan(req.Code, req.Name, req.Price, req.PeriodDays, req.GraceDays)
+	if err != nil {
+		return billingError(c, err)
+	}
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	if err := h.BillingRepo.CreatePlan(c.UserContext(), plan); err != nil {
+		return billingError(c, err)
+	}
+	return c.Status(201).JSON(toPlanResponse(plan))
+}
+
+// AdminUpdatePlan replaces a plan's name, price, periods and, if given,
+// availability. Price changes apply to invoices issued afterwards; the code
+// is fixed.
+func (h *BillingHandler) AdminUpdatePlan(c *fiber.Ctx) error {
+	plan, err := h.BillingRepo.GetPlan(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return billingError(c, err)
+	}
+
+	var req planRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	updated, err := domain.NewPlan(plan.Code, req.Name, req.Price, req.PeriodDays, req.GraceDays)
+	if err != nil {
+		return billingError(c, err)
+	}
+
+	plan.Name = updated.Name
+	plan.Price = updated.Price
+	plan.PeriodDays = updated.PeriodDays
+	plan.GraceDays = updated.GraceDays
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	plan.UpdatedAt = time.Now()
+	if err := h.BillingRepo.UpdatePlan(c.UserContext(), plan); err != nil {
+		return billingError(c, err)
+	}
+	return c.JSON(toPlanResponse(plan))
+}
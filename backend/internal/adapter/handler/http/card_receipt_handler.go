@@ -0,0 +1,146 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+// CardReceiptHandler serves the admin review queue of card-to-card receipts
+type CardReceiptHandler struct {
+	CardToCard  *services.CardToCardService
+	ReceiptRepo ports.CardReceiptRepository
+}
+
+func NewCardReceiptHandler(cardToCard *services.CardToCardService, receiptRepo ports.CardReceiptRepository) *CardReceiptHandler {
+	return &CardReceiptHandler{CardToCard: cardToCard, ReceiptRepo: receiptRepo}
+}
+
+type cardReceiptResponse struct {
+	ID             string     `json:"id"`
+	PaymentID      string     `json:"payment_id"`
+	UserID         string     `json:"user_id"`
+	Amount         int64      `json:"amount"`
+	TrackingNumber string     `json:"tracking_number"`
+	ImageKey       string     `json:"image_key"`
+	PaidAt         time.Time  `json:"paid_at"`
+	Description    string     `json:"description,omitempty"`
+	Status         string     `json:"status"`
+	Flags          []string   `json:"flags"`
+	ReviewerID     string     `json:"reviewer_id,omitempty"`
+	ReviewReason   string     `json:"review_reason,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toCardReceiptResponse(r *domain.CardReceipt) cardReceiptResponse {
+	flags := r.Flags
+	if flags == nil {
+		flags = []string{}
+	}
+	return cardReceiptResponse{
+		ID:             r.ID,
+		PaymentID:      r.PaymentID,
+		UserID:         r.UserID,
+		Amount:         r.Amount,
+		TrackingNumber: r.TrackingNumber,
+		ImageKey:       r.ImageKey,
+		PaidAt:         r.PaidAt,
+		Description:    r.Description,
+		Status:         string(r.Status),
+		Flags:          flags,
+		ReviewerID:     r.ReviewerID,
+		ReviewReason:   r.ReviewReason,
+		ReviewedAt:     r.ReviewedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+// List returns the review queue, filtered by status (default pending),
+// user, flagged and a from/to submission window (RFC 3339)
+func (h *CardReceiptHandler) List(c *fiber.Ctx) error {
+	filter := domain.CardReceiptFilter{
+		Status: domain.ReceiptStatus(c.Query("status", string(domain.ReceiptPending))),
+		UserID: c.Query("user_id"),
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	if flagged := c.Query("flagged"); flagged != "" {
+		value := c.QueryBool("flagged")
+		filter.Flagged = &value
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "Invalid " + param + " time, use RFC 3339"})
+			}
+			*target = &t
+		}
+	}
+
+	receipts, err := h.ReceiptRepo.List(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list receipts"})
+	}
+
+	resp := make([]cardReceiptResponse, 0, len(receipts))
+	for i := range receipts {
+		resp = append(resp, toCardReceiptResponse(&receipts[i]))
+	}
+	return c.JSON(fiber.Map{"receipts": resp})
+}
+
+// Get returns a single receipt
+func (h *CardReceiptHandler) Get(c *fiber.Ctx) error {
+	receipt, err := h.ReceiptRepo.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toCardReceiptResponse(receipt))
+}
+
+type reviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Approve accepts a receipt and verifies its payment
+func (h *CardReceiptHandler) Approve(c *fiber.Ctx) error {
+	reviewerID := c.Locals("user_id").(string)
+
+	var req reviewRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	receipt, err := h.CardToCard.Approve(c.UserContext(), c.Params("id"), reviewerID, req.Reason)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toCardReceiptResponse(receipt))
+}
+
+// Reject declines a receipt and fails its payment; a reason is required
+func (h *CardReceiptHandler) Reject(c *fiber.Ctx) error {
+	reviewerID := c.Locals("user_id").(string)
+
+	var req reviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	receipt, err := h.CardToCard.Reject(c.UserContext(), c.Params("id"), reviewerID, req.Reason)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toCardReceiptResponse(receipt))
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// CouponHandler serves the admin endpoints for promo codes
+type CouponHandler struct {
+	CouponRepo  ports.CouponRepository
+	BillingRepo ports.BillingRepository
+}
+
+func NewCouponHandler(couponRepo ports.CouponRepository, billingRepo ports.BillingRepository) *CouponHandler {
+	return &CouponHandler{CouponRepo: couponRepo, BillingRepo: billingRepo}
+}
+
+type couponResponse struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int64      `json:"value"`
+	MaxDiscount    int64      `json:"max_discount"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	PerUserLimit   int        `json:"per_user_limit"`
+	Redemptions    int        `json:"redemptions"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	PlanIDs        []string   `json:"plan_ids"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func toCouponResponse(c *domain.Coupon) couponResponse {
+	resp := couponResponse{
+		ID:             c.ID,
+		Code:           c.Code,
+		Type:           string(c.Type),
+		Value:          c.Value,
+		MaxDiscount:    c.MaxDiscount,
+		MaxRedemptions: c.MaxRedemptions,
+		PerUserLimit:   c.PerUserLimit,
+		Redemptions:    c.Redemptions,
+		PlanIDs:        c.PlanIDs,
+		Active:         c.Active,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
+	}
+	if !c.ValidFrom.IsZero() {
+		resp.ValidFrom = &c.ValidFrom
+	}
+	if !c.ValidUntil.IsZero() {
+		resp.ValidUntil = &c.ValidUntil
+	}
+	if resp.PlanIDs == nil {
+		resp.PlanIDs = []string{}
+	}
+	return resp
+}
+
+type redemptionResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	PaymentID string    `json:"payment_id"`
+	Discount  int64     `json:"discount"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type couponRequest struct {
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int64      `json:"value"`
+	MaxDiscount    int64      `json:"max_discount"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	PerUserLimit   int        `json:"per_user_limit"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	PlanIDs        []string   `json:"plan_ids"`
+	Active         *bool      `json:"active"`
+}
+
+// apply copies the request's limits, validity and plans onto coupon and
+// validates the result
+func (h *CouponHandler) apply(c *fiber.Ctx, coupon *domain.Coupon, req *couponRequest) error {
+	coupon.MaxDiscount = req.MaxDiscount
+	coupon.MaxRedemptions = req.MaxRedemptions
+	coupon.PerUserLimit = req.PerUserLimit
+	coupon.ValidFrom, coupon.ValidUntil = time.Time{}, time.Time{}
+	if req.ValidFrom != nil {
+		coupon.ValidFrom = *req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		coupon.ValidUntil = *req.ValidUntil
+	}
+	if req.Active != nil {
+		coupon.Active = *req.Active
+	}
+
+	coupon.PlanIDs = nil
+	for _, id := range req.PlanIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid plan id %q", domain.ErrInvalidCoupon, id)
+		}
+		if _, err := h.BillingRepo.GetPlan(c.UserContext(), id); err != nil {
+			return err
+		}
+		coupon.PlanIDs = append(coupon.PlanIDs, id)
+	}
+	return coupon.Validate()
+}
+
+// List returns coupons, newest first
+func (h *CouponHandler) List(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	coupons, err := h.CouponRepo.List(c.UserContext(), limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list coupons"})
+	}
+	resp := make([]couponResponse, 0, len(coupons))
+	for i := range coupons {
+		resp = append(resp, toCouponResponse(&coupons[i]))
+	}
+	return c.JSON(fiber.Map{"coupons": resp})
+}
+
+// Get returns a coupon
+func (h *CouponHandler) Get(c *fiber.Ctx) error {
+	coupon, err := h.CouponRepo.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return billingError(c, err)
+	}
+	return c.JSON(toCouponResponse(coupon))
+}
+
+// Create adds a coupon
+func (h *CouponHandler) Create(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	var req couponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	coupon, err := domain.NewCoupon(req.Code, domain.DiscountType(req.Type), req.Value, adminID)
+	if err != nil {
+		return billingError(c, err)
+	}
+	if err := h.apply(c, coupon, &req); err != nil {
+		return billingError(c, err)
+	}
+	if err := h.CouponRepo.Create(c.UserContext(), coupon); err != nil {
+		return billingError(c, err)
+	}
+	return c.Status(201).JSON(toCouponResponse(coupon))
+}
+
+// Update replaces a coupon's discount, limits, validity, plans and, if
+// given, availability. The code is fixed; changes apply to payments
+// started afterwards.
+func (h *CouponHandler) Update(c *fiber.Ctx) error {
+	coupon, err := h.CouponRepo.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return billingError(c, err)
+	}
+
+	var req couponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	coupon.Type = domain.DiscountType(req.Type)
+	coupon.Value = req.Value
+	if err := h.apply(c, coupon, &req); err != nil {
+		return billingError(c, err)
+	}
+	coupon.UpdatedAt = time.Now()
+	if err := h.CouponRepo.Update(c.UserContext(), coupon); err != nil {
+		return billingError(c, err)
+	}
+	return c.JSON(toCouponResponse(coupon))
+}
+
+// Delete removes a coupon that was never redeemed; used coupons are
+// deactivated instead
+func (h *CouponHandler) Delete(c *fiber.Ctx) error {
+	if err := h.CouponRepo.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return billingError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Coupon deleted"})
+}
+
+// ListRedemptions returns the payments that used a coupon, newest first
+func (h *CouponHandler) ListRedemptions(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	redemptions, err := h.CouponRepo.ListRedemptions(c.UserContext(), c.Params("id"), limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list redemptions"})
+	}
+	resp := make([]redemptionResponse, 0, len(redemptions))
+	for _, r := range redemptions {
+		resp = append(resp, redemptionResponse{
+			ID:        r.ID,
+			UserID:    r.UserID,
+			PaymentID: r.PaymentID,
+			Discount:  r.Discount,
+			Status:    string(r.Status),
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		})
+	}
+	return c.JSON(fiber.Map{"redemptions": resp})
+}
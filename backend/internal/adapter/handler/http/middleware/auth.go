@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,19 +16,30 @@ func Protected() fiber.Handler {
 		}
 
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// In production, fetch this from env or secret manager
-			return []byte("your-256-bit-secret"), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
 		}
 
 		// Store claims in context for next handlers
-		claims := token.Claims.(jwt.MapClaims)
 		c.Locals("user_id", claims["user_id"])
+		c.Locals("claims", claims)
 
 		return c.Next()
 	}
 }
+
+// ParseToken validates a signed access token and returns its claims
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// In production, fetch this from env or secret manager
+		return []byte("your-256-bit-secret"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return token.Claims.(jwt.MapClaims), nil
+}
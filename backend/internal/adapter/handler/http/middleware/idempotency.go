@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyMiddleware replays the stored response when a request is
+// repeated with the same Idempotency-Key
+type IdempotencyMiddleware struct {
+	store ports.IdempotencyStore
+	// ttl is how long completed responses are kept
+	ttl time.Duration
+	// lockTTL bounds how long an in-flight request holds its key
+	lockTTL time.Duration
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware instance
+func NewIdempotencyMiddleware(store ports.IdempotencyStore, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{store: store, ttl: ttl, lockTTL: time.Minute}
+}
+
+// fingerprint identifies a request by method, path, query string and body
+func fingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Request().URI().QueryString())
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Handle enforces idempotency for requests carrying an Idempotency-Key
+// header; requests without one pass through. Keys are scoped to the
+// authenticated user, so it must run after Protected.
+func (m *IdempotencyMiddleware) Handle() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(400).JSON(fiber.Map{"error": "Idempotency-Key is too long"})
+		}
+
+		userID, _ := c.Locals("user_id").(string)
+		storeKey := userID + ":" + key
+		fp := fingerprint(c)
+		owner := uuid.NewString()
+
+		existing, err := m.store.Begin(c.UserContext(), storeKey, fp, owner, m.lockTTL)
+		if err != nil {
+			logger.Log.Error("Idempotency store unavailable", zap.Error(err))
+			return c.Status(503).JSON(fiber.Map{"error": "Idempotency store unavailable"})
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fp:
+				return c.Status(422).JSON(fiber.Map{"error": "Idempotency-Key was already used with a different request"})
+			case !existing.Completed:
+				return c.Status(409).JSON(fiber.Map{"error": "A request with this Idempotency-Key is already in progress"})
+			}
+			c.Set(IdempotencyReplayedHeader, "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.StatusCode).Send(existing.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			m.release(c, storeKey, owner)
+			return err
+		}
+
+		// Server errors are not stored so the client can retry
+		status := c.Response().StatusCode()
+		if status >= 500 {
+			m.release(c, storeKey, owner)
+			return nil
+		}
+
+		record := &ports.IdempotencyRecord{
+			Fingerprint: fp,
+			Completed:   true,
+			StatusCode:  status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		if err := m.store.Complete(c.UserContext(), storeKey, owner, record, m.ttl); err != nil {
+			logger.Log.Error("Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+		}
+		return nil
+	}
+}
+
+func (m *IdempotencyMiddleware) release(c *fiber.Ctx, storeKey, owner string) {
+	if err := m.store.Release(c.UserContext(), storeKey, owner); err != nil {
+		logger.Log.Error("Failed to release idempotency key", zap.Error(err))
+	}
+}
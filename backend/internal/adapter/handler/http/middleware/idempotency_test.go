@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// memoryIdempotencyStore is an in-memory ports.IdempotencyStore
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*ports.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*ports.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) Begin(_ context.Context, key, fingerprint, owner string, _ time.Duration) (*ports.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok {
+		return record, nil
+	}
+	s.records[key] = &ports.IdempotencyRecord{Fingerprint: fingerprint, Owner: owner}
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key, owner string, record *ports.IdempotencyRecord, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.records[key]; !ok || current.Completed || current.Owner != owner {
+		return ports.ErrIdempotencyKeyLost
+	}
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.records[key]; !ok || current.Completed || current.Owner != owner {
+		return ports.ErrIdempotencyKeyLost
+	}
+	delete(s.records, key)
+	return nil
+}
+
+// newIdempotencyApp serves POST /payments behind the middleware; the handler
+// answers with status and counts its calls
+func newIdempotencyApp(store ports.IdempotencyStore, status *int, calls *int) *fiber.App {
+	app := fiber.New()
+	app.Post("/payments",
+		func(c *fiber.Ctx) error {
+			c.Locals("user_id", "u1")
+			return c.Next()
+		},
+		NewIdempotencyMiddleware(store, time.Hour).Handle(),
+		func(c *fiber.Ctx) error {
+			*calls++
+			return c.Status(*status).JSON(fiber.Map{"call": *calls})
+		},
+	)
+	return app
+}
+
+func doIdempotent(t *testing.T, app *fiber.App, target, key, body string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(respBody), resp.Header.Get(IdempotencyReplayedHeader)
+}
+
+func TestIdempotencyReplaysCompletedResponse(t *testing.T) {
+	status, calls := 201, 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), &status, &calls)
+
+	code, body, _ := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`)
+	if code != 201 {
+		t.Fatalf("first request status = %d, want 201", code)
+	}
+
+	replayCode, replayBody, replayed := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`)
+	if replayCode != 201 || replayBody != body || replayed != "true" {
+		t.Errorf("replay = %d %q (replayed %q), want 201 %q (replayed true)", replayCode, replayBody, replayed, body)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	status, calls := 201, 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), &status, &calls)
+
+	doIdempotent(t, app, "/payments", "", `{"amount":1000}`)
+	doIdempotent(t, app, "/payments", "", `{"amount":1000}`)
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyRejectsInFlightKey(t *testing.T) {
+	status, calls := 201, 0
+	store := newMemoryIdempotencyStore()
+	app := newIdempotencyApp(store, &status, &calls)
+
+	// Reserve the key the way a concurrent first request would
+	req := httptest.NewRequest("POST", "/payments", strings.NewReader(`{"amount":1000}`))
+	fp := ""
+	probe := fiber.New()
+	probe.Post("/payments", func(c *fiber.Ctx) error {
+		fp = fingerprint(c)
+		return nil
+	})
+	if _, err := probe.Test(req); err != nil {
+		t.Fatalf("probe.Test() error = %v", err)
+	}
+	if _, err := store.Begin(context.Background(), "u1:k1", fp, "other", time.Minute); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	if code, _, _ := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`); code != 409 {
+		t.Errorf("status = %d, want 409", code)
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times, want 0", calls)
+	}
+}
+
+func TestIdempotencyRejectsDifferentRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{name: "Different Body", target: "/payments", body: `{"amount":2000}`},
+		{name: "Different Query", target: "/payments?gateway=zarinpal", body: `{"amount":1000}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, calls := 201, 0
+			app := newIdempotencyApp(newMemoryIdempotencyStore(), &status, &calls)
+
+			doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`)
+			if code, _, _ := doIdempotent(t, app, tt.target, "k1", tt.body); code != 422 {
+				t.Errorf("status = %d, want 422", code)
+			}
+			if calls != 1 {
+				t.Errorf("handler called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	status, calls := 500, 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), &status, &calls)
+
+	if code, _, _ := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`); code != 500 {
+		t.Fatalf("first request status = %d, want 500", code)
+	}
+
+	status = 201
+	code, _, replayed := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`)
+	if code != 201 || replayed != "" {
+		t.Errorf("retry = %d (replayed %q), want a fresh 201", code, replayed)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyKeepsRecordOfRequestThatTookOver(t *testing.T) {
+	// The lost key is logged
+	logger.Log = zap.NewNop()
+	store := newMemoryIdempotencyStore()
+	taken := &ports.IdempotencyRecord{Fingerprint: "retry", Owner: "retry"}
+	app := fiber.New()
+	app.Post("/payments",
+		func(c *fiber.Ctx) error {
+			c.Locals("user_id", "u1")
+			return c.Next()
+		},
+		NewIdempotencyMiddleware(store, time.Hour).Handle(),
+		func(c *fiber.Ctx) error {
+			// The reservation expires and a retry takes the key over
+			store.mu.Lock()
+			store.records["u1:k1"] = taken
+			store.mu.Unlock()
+			return c.Status(201).JSON(fiber.Map{"call": 1})
+		},
+	)
+
+	if code, _, _ := doIdempotent(t, app, "/payments", "k1", `{"amount":1000}`); code != 201 {
+		t.Fatalf("status = %d, want 201", code)
+	}
+	if got := store.records["u1:k1"]; got != taken {
+		t.Errorf("record = %+v, want the retry's reservation kept", got)
+	}
+}
@@ -1,19 +1,45 @@
 package middleware
 
 import (
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/youruser/yourproject/internal/core/domain"
 	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 // RBACMiddleware holds the RBAC repository for authorization checks
 type RBACMiddleware struct {
 	rbacRepo ports.RBACRepository
+	versions ports.PermissionVersionStore
 }
 
 // NewRBACMiddleware creates a new RBAC middleware instance
-func NewRBACMiddleware(rbacRepo ports.RBACRepository) *RBACMiddleware {
-	return &RBACMiddleware{rbacRepo: rbacRepo}
+func NewRBACMiddleware(rbacRepo ports.RBACRepository, versions ports.PermissionVersionStore) *RBACMiddleware {
+	return &RBACMiddleware{rbacRepo: rbacRepo, versions: versions}
+}
+
+// hasRole checks a role globally or, inside a tenant, in the tenant's scope
+func (m *RBACMiddleware) hasRole(c *fiber.Ctx, userID, role string) (bool, error) {
+	if tenantID := TenantID(c); tenantID != "" {
+		return m.rbacRepo.UserHasRoleInScope(c.Context(), userID, role, tenantID)
+	}
+	return m.rbacRepo.UserHasRole(c.Context(), userID, role)
+}
+
+// hasPermission checks a permission globally or, inside a tenant, in the
+// tenant's scope
+func (m *RBACMiddleware) hasPermission(c *fiber.Ctx, userID, permission string) (bool, error) {
+	if tenantID := TenantID(c); tenantID != "" {
+		return m.rbacRepo.UserHasPermissionInScope(c.Context(), userID, permission, tenantID)
+	}
+	return m.rbacRepo.UserHasPermission(c.Context(), userID, permission)
 }
 
 // RequireRole checks if the user has a specific role
@@ -26,8 +52,7 @@ func (m *RBACMiddleware) RequireRole(role string) fiber.Handler {
 			})
 		}
 
-		ctx := c.Context()
-		hasRole, err := m.rbacRepo.UserHasRole(ctx, userID, role)
+		hasRole, err := m.hasRole(c, userID, role)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to check user role",
@@ -36,11 +61,9 @@ func (m *RBACMiddleware) RequireRole(role string) fiber.Handler {
 
 		if !hasRole {
 			// Check if user is admin (admin has access to everything)
-			isAdmin, _ := m.rbacRepo.UserHasRole(ctx, userID, domain.RoleAdmin)
+			isAdmin, _ := m.hasRole(c, userID, domain.RoleAdmin)
 			if !isAdmin {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Insufficient permissions",
-				})
+				return deny(c, userID, "require_role", "authz.required_roles", role)
 			}
 		}
 
@@ -58,8 +81,7 @@ func (m *RBACMiddleware) RequirePermission(permission string) fiber.Handler {
 			})
 		}
 
-		ctx := c.Context()
-		hasPermission, err := m.rbacRepo.UserHasPermission(ctx, userID, permission)
+		hasPermission, err := m.hasPermission(c, userID, permission)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to check user permission",
@@ -67,15 +89,65 @@ func (m *RBACMiddleware) RequirePermission(permission string) fiber.Handler {
 		}
 
 		if !hasPermission {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Insufficient permissions",
-			})
+			return deny(c, userID, "require_permission", "authz.missing_permissions", permission)
 		}
 
 		return c.Next()
 	}
 }
 
+// RequirePermissionFromClaims authorizes from the permissions embedded in the
+// access token, without touching Postgres. The token's permission version is
+// checked against Redis so tokens issued before a role change are rejected
+// with a refresh hint. Tokens without embedded permissions, or whose
+// time-bound grants have lapsed, fall back to RequirePermission.
+func (m *RBACMiddleware) RequirePermissionFromClaims(permission string) fiber.Handler {
+	fallback := m.RequirePermission(permission)
+
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+
+		// Embedded permissions are global; tenant roles need the database
+		if TenantID(c) != "" {
+			return fallback(c)
+		}
+
+		claims, _ := c.Locals("claims").(jwt.MapClaims)
+		perms, hasPerms := claims["perms"].([]interface{})
+		tokenVersion, hasVersion := claims["pv"].(float64)
+		if !hasPerms || !hasVersion {
+			return fallback(c)
+		}
+		if validUntil, ok := claims["pv_exp"].(float64); ok && time.Now().Unix() >= int64(validUntil) {
+			return fallback(c)
+		}
+
+		currentVersion, err := m.versions.UserVersion(c.Context(), userID)
+		if err != nil {
+			return fallback(c)
+		}
+		if int64(tokenVersion) != currentVersion {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Permissions changed, please refresh your token",
+				"code":  "token_stale",
+			})
+		}
+
+		for _, p := range perms {
+			if name, _ := p.(string); name == permission {
+				return c.Next()
+			}
+		}
+
+		return deny(c, userID, "require_permission_from_claims", "authz.missing_permissions", permission)
+	}
+}
+
 // RequireAnyRole checks if the user has any of the specified roles
 func (m *RBACMiddleware) RequireAnyRole(roles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -86,9 +158,8 @@ func (m *RBACMiddleware) RequireAnyRole(roles ...string) fiber.Handler {
 			})
 		}
 
-		ctx := c.Context()
 		for _, role := range roles {
-			hasRole, err := m.rbacRepo.UserHasRole(ctx, userID, role)
+			hasRole, err := m.hasRole(c, userID, role)
 			if err != nil {
 				continue
 			}
@@ -97,9 +168,7 @@ func (m *RBACMiddleware) RequireAnyRole(roles ...string) fiber.Handler {
 			}
 		}
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-		})
+		return deny(c, userID, "require_any_role", "authz.required_roles", roles...)
 	}
 }
 
@@ -113,9 +182,8 @@ func (m *RBACMiddleware) RequireAnyPermission(permissions ...string) fiber.Handl
 			})
 		}
 
-		ctx := c.Context()
 		for _, permission := range permissions {
-			hasPerm, err := m.rbacRepo.UserHasPermission(ctx, userID, permission)
+			hasPerm, err := m.hasPermission(c, userID, permission)
 			if err != nil {
 				continue
 			}
@@ -124,9 +192,7 @@ func (m *RBACMiddleware) RequireAnyPermission(permissions ...string) fiber.Handl
 			}
 		}
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-		})
+		return deny(c, userID, "require_any_permission", "authz.missing_permissions", permissions...)
 	}
 }
 
@@ -140,16 +206,40 @@ func (m *RBACMiddleware) RequireAllPermissions(permissions ...string) fiber.Hand
 			})
 		}
 
-		ctx := c.Context()
 		for _, permission := range permissions {
-			hasPerm, err := m.rbacRepo.UserHasPermission(ctx, userID, permission)
+			hasPerm, err := m.hasPermission(c, userID, permission)
 			if err != nil || !hasPerm {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Insufficient permissions",
-				})
+				return deny(c, userID, "require_all_permissions", "authz.missing_permissions", permission)
 			}
 		}
 
 		return c.Next()
 	}
 }
+
+// deny rejects the request with 403 and records the denial, naming what was
+// missing, in the log and on the active trace span
+func deny(c *fiber.Ctx, userID, check, missingKey string, missing ...string) error {
+	logger.Log.Warn("Authorization denied",
+		zap.String("user_id", userID),
+		zap.String("check", check),
+		zap.String("tenant_id", TenantID(c)),
+		zap.Strings(strings.TrimPrefix(missingKey, "authz."), missing),
+		zap.String("method", c.Method()),
+		zap.String("path", c.Path()),
+	)
+
+	span := trace.SpanFromContext(c.UserContext())
+	span.SetAttributes(
+		attribute.String("authz.decision", "deny"),
+		attribute.String("authz.user_id", userID),
+		attribute.String("authz.check", check),
+		attribute.String("authz.tenant_id", TenantID(c)),
+		attribute.StringSlice(missingKey, missing),
+	)
+	span.AddEvent("authz.denied")
+
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": "Insufficient permissions",
+	})
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// SubscriptionMiddleware gates features behind subscription plans
+type SubscriptionMiddleware struct {
+	billingRepo ports.BillingRepository
+}
+
+// NewSubscriptionMiddleware creates a new subscription middleware instance
+func NewSubscriptionMiddleware(billingRepo ports.BillingRepository) *SubscriptionMiddleware {
+	return &SubscriptionMiddleware{billingRepo: billingRepo}
+}
+
+// RequireActiveSubscription allows users whose subscription is active or in
+// its grace period, on one of the given plan codes or on any plan when none
+// are given. It must run after Protected.
+func (m *SubscriptionMiddleware) RequireActiveSubscription(plans ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+
+		sub, err := m.billingRepo.GetCurrentSubscription(c.UserContext(), userID)
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			return c.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{
+				"error": "An active subscription is required",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check subscription",
+			})
+		}
+		plan, err := m.billingRepo.GetPlan(c.UserContext(), sub.PlanID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check subscription",
+			})
+		}
+
+		if !sub.Entitled(time.Now(), plan.GracePeriod()) {
+			return c.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{
+				"error": "An active subscription is required",
+			})
+		}
+		if len(plans) > 0 && !slices.Contains(plans, plan.Code) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":          "Your plan does not include this feature",
+				"required_plans": plans,
+			})
+		}
+
+		c.Locals("plan", plan.Code)
+		return c.Next()
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// Tenant resolution strategies
+const (
+	TenantFromHeader    = "header"
+	TenantFromSubdomain = "subdomain"
+	TenantFromPath      = "path"
+)
+
+// TenantConfig controls how the current organization is resolved
+type TenantConfig struct {
+	// Strategies are tried in order until one yields an identifier
+	Strategies []string
+	// Header carries an organization ID or slug
+	Header string
+	// BaseDomain is stripped from the host to find the subdomain slug
+	BaseDomain string
+	// PathParam is the route parameter holding an organization ID or slug
+	PathParam string
+}
+
+// DefaultTenantConfig returns the default configuration
+func DefaultTenantConfig() TenantConfig {
+	return TenantConfig{
+		Strategies: []string{TenantFromPath, TenantFromHeader, TenantFromSubdomain},
+		Header:     "X-Tenant-ID",
+		PathParam:  "tenant",
+	}
+}
+
+// TenantMiddleware resolves the current organization for a request
+type TenantMiddleware struct {
+	orgRepo ports.OrganizationRepository
+	cfg     TenantConfig
+}
+
+// NewTenantMiddleware creates a new tenant middleware instance
+func NewTenantMiddleware(orgRepo ports.OrganizationRepository, cfg TenantConfig) *TenantMiddleware {
+	return &TenantMiddleware{orgRepo: orgRepo, cfg: cfg}
+}
+
+// Resolve identifies the organization, checks that the authenticated user is
+// a member and stores it as "tenant_id" in locals and in the user context.
+// It must run after Protected.
+func (m *TenantMiddleware) Resolve() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+
+		identifier := m.identifier(c)
+		if identifier == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Tenant not specified",
+			})
+		}
+
+		ctx := c.Context()
+		org, err := m.lookup(ctx, identifier)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Organization not found",
+			})
+		}
+
+		isMember, err := m.orgRepo.IsMember(ctx, org.ID, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check membership",
+			})
+		}
+		if !isMember {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Not a member of this organization",
+			})
+		}
+
+		c.Locals("tenant_id", org.ID)
+		c.Locals("tenant", org)
+		c.SetUserContext(domain.WithTenant(c.UserContext(), org.ID))
+
+		return c.Next()
+	}
+}
+
+func (m *TenantMiddleware) identifier(c *fiber.Ctx) string {
+	for _, strategy := range m.cfg.Strategies {
+		var id string
+		switch strategy {
+		case TenantFromHeader:
+			id = c.Get(m.cfg.Header)
+		case TenantFromPath:
+			id = c.Params(m.cfg.PathParam)
+		case TenantFromSubdomain:
+			host := strings.Split(c.Hostname(), ":")[0]
+			if m.cfg.BaseDomain != "" && strings.HasSuffix(host, "."+m.cfg.BaseDomain) {
+				id = strings.TrimSuffix(host, "."+m.cfg.BaseDomain)
+			}
+		}
+		if id = strings.TrimSpace(id); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (m *TenantMiddleware) lookup(ctx context.Context, identifier string) (*domain.Organization, error) {
+	if _, err := uuid.Parse(identifier); err == nil {
+		return m.orgRepo.GetByID(ctx, identifier)
+	}
+	return m.orgRepo.GetBySlug(ctx, strings.ToLower(identifier))
+}
+
+// TenantID returns the organization resolved for the request, if any
+func TenantID(c *fiber.Ctx) string {
+	id, _ := c.Locals("tenant_id").(string)
+	return id
+}
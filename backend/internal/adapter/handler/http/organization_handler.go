@@ -0,0 +1,170 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+type OrganizationHandler struct {
+	Orgs    *services.OrganizationService
+	OrgRepo ports.OrganizationRepository
+}
+
+func NewOrganizationHandler(orgs *services.OrganizationService, orgRepo ports.OrganizationRepository) *OrganizationHandler {
+	return &OrganizationHandler{
+		Orgs:    orgs,
+		OrgRepo: orgRepo,
+	}
+}
+
+type organizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toOrganizationResponse(org *domain.Organization) organizationResponse {
+	return organizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+// Create creates an organization owned by the current user
+func (h *OrganizationHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type Request struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	org, err := h.Orgs.Create(c.UserContext(), req.Name, req.Slug, userID)
+	if errors.Is(err, domain.ErrInvalidOrganization) || errors.Is(err, domain.ErrInvalidSlug) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create organization"})
+	}
+
+	return c.Status(201).JSON(toOrganizationResponse(org))
+}
+
+// List lists the organizations the current user belongs to
+func (h *OrganizationHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	orgs, err := h.OrgRepo.ListForUser(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list organizations"})
+	}
+
+	resp := make([]organizationResponse, 0, len(orgs))
+	for i := range orgs {
+		resp = append(resp, toOrganizationResponse(&orgs[i]))
+	}
+	return c.JSON(fiber.Map{"organizations": resp})
+}
+
+// Get returns the current tenant
+func (h *OrganizationHandler) Get(c *fiber.Ctx) error {
+	org := c.Locals("tenant").(*domain.Organization)
+	return c.JSON(toOrganizationResponse(org))
+}
+
+// ListMembers lists the members of the current tenant
+func (h *OrganizationHandler) ListMembers(c *fiber.Ctx) error {
+	members, err := h.OrgRepo.ListMembers(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list members"})
+	}
+
+	type member struct {
+		UserID   string    `json:"user_id"`
+		Email    string    `json:"email"`
+		Phone    string    `json:"phone,omitempty"`
+		JoinedAt time.Time `json:"joined_at"`
+	}
+	resp := make([]member, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, member{UserID: m.UserID, Email: m.Email, Phone: m.Phone, JoinedAt: m.CreatedAt})
+	}
+	return c.JSON(fiber.Map{"members": resp})
+}
+
+// RemoveMember removes a member and their roles from the current tenant
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	err := h.Orgs.RemoveMember(c.UserContext(), c.Params("userId"))
+	if errors.Is(err, domain.ErrLastOrgOwner) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to remove member"})
+	}
+	return c.JSON(fiber.Map{"message": "Member removed"})
+}
+
+// Invite invites someone to the current tenant by email or SMS
+func (h *OrganizationHandler) Invite(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type Request struct {
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+		Role  string `json:"role"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	invitation, err := h.Orgs.Invite(c.UserContext(), userID, req.Email, req.Phone, req.Role)
+	if errors.Is(err, domain.ErrInvalidInvitation) || errors.Is(err, domain.ErrInvalidPhone) || errors.Is(err, domain.ErrInvalidOrgRole) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to send invitation"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"id":         invitation.ID,
+		"expires_at": invitation.ExpiresAt,
+	})
+}
+
+// AcceptInvitation joins the current user to the inviting organization
+func (h *OrganizationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type Request struct {
+		Token string `json:"token"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	org, err := h.Orgs.AcceptInvitation(c.UserContext(), req.Token, userID)
+	switch {
+	case errors.Is(err, domain.ErrInvitationExpired), errors.Is(err, domain.ErrInvitationAccepted):
+		return c.Status(410).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvitationRecipient):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(404).JSON(fiber.Map{"error": "Invitation not found"})
+	}
+
+	return c.JSON(toOrganizationResponse(org))
+}
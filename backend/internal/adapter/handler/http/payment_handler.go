@@ -0,0 +1,361 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+	"github.com/youruser/yourproject/pkg/logger"
+	"github.com/youruser/yourproject/pkg/resilience"
+	"go.uber.org/zap"
+)
+
+type PaymentHandler struct {
+	Payments    *services.PaymentService
+	PaymentRepo ports.PaymentRepository
+	UserRepo    ports.UserRepository
+	Gateways    *services.GatewayRegistry
+	CardToCard  *services.CardToCardService
+	// Receipts serves PDF receipts; nil when receipts are not configured
+	Receipts *services.PaymentReceiptService
+
+	// CallbackBaseURL is the public URL of the payments API; gateways send
+	// users back to CallbackBaseURL/{gateway}/callback
+	CallbackBaseURL string
+	// ResultURL is the frontend page users land on after a callback
+	ResultURL string
+}
+
+func NewPaymentHandler(payments *services.PaymentService, paymentRepo ports.PaymentRepository, userRepo ports.UserRepository, gateways *services.GatewayRegistry, cardToCard *services.CardToCardService, callbackBaseURL, resultURL string) *PaymentHandler {
+	return &PaymentHandler{
+		Payments:        payments,
+		PaymentRepo:     paymentRepo,
+		UserRepo:        userRepo,
+		Gateways:        gateways,
+		CardToCard:      cardToCard,
+		CallbackBaseURL: callbackBaseURL,
+		ResultURL:       resultURL,
+	}
+}
+
+func (h *PaymentHandler) callbackURL(gateway string) string {
+	return fmt.Sprintf("%s/%s/callback", h.CallbackBaseURL, gateway)
+}
+
+type paymentResponse struct {
+	ID          string    `json:"id"`
+	Gateway     string    `json:"gateway"`
+	Amount      int64     `json:"amount"`
+	CouponCode  string    `json:"coupon_code,omitempty"`
+	Discount    int64     `json:"discount"`
+	Description string    `json:"description,omitempty"`
+	OrderID     string    `json:"order_id,omitempty"`
+	Authority   string    `json:"authority,omitempty"`
+	RefID       string    `json:"ref_id,omitempty"`
+	Refunded    int64     `json:"refunded_amount"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func toPaymentResponse(p *domain.Payment) paymentResponse {
+	return paymentResponse{
+		ID:          p.ID,
+		Gateway:     p.Gateway,
+		Amount:      p.Amount,
+		CouponCode:  p.CouponCode,
+		Discount:    p.Discount,
+		Description: p.Description,
+		OrderID:     p.OrderID,
+		Authority:   p.Authority,
+		RefID:       p.RefID,
+		Refunded:    p.RefundedAmount,
+		Status:      string(p.Status),
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// paymentError maps payment errors to HTTP responses
+func paymentError(c *fiber.Ctx, err error) error {
+	var gwErr *ports.GatewayError
+	switch {
+	case errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrInexactAmount), errors.Is(err, domain.ErrInvalidRefundAmount),
+		errors.Is(err, domain.ErrInvalidReceipt), errors.Is(err, domain.ErrInvalidBlocklistEntry), errors.Is(err, domain.ErrInvalidCoupon):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrGatewayUnavailable):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrPaymentBlocked):
+		// The rules that fired are logged, not disclosed to the payer
+		return c.Status(403).JSON(fiber.Map{"error": "Payment was declined"})
+	case errors.Is(err, domain.ErrPaymentNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Payment not found"})
+	case errors.Is(err, domain.ErrRefundNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Refund not found"})
+	case errors.Is(err, domain.ErrReceiptNotFound), errors.Is(err, domain.ErrPaymentReceiptNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Receipt not found"})
+	case errors.Is(err, domain.ErrBlocklistEntryNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Blocklist entry not found"})
+	case errors.Is(err, domain.ErrCouponNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Coupon not found"})
+	case errors.Is(err, domain.ErrCouponNotApplicable), errors.Is(err, domain.ErrCouponExhausted), errors.Is(err, domain.ErrCouponUserLimit):
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPaymentState), errors.Is(err, domain.ErrPaymentConcurrentUpdate), errors.Is(err, domain.ErrInvalidRefundState),
+		errors.Is(err, domain.ErrRefundConflict), errors.Is(err, domain.ErrReceiptAlreadyReviewed), errors.Is(err, domain.ErrPaymentReferenceUsed), errors.Is(err, domain.ErrBlocklistEntryExists),
+		errors.Is(err, domain.ErrPaymentReceiptUnavailable), errors.Is(err, domain.ErrCouponCodeTaken), errors.Is(err, domain.ErrCouponInUse):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		return c.Status(503).JSON(fiber.Map{"error": "Payment gateway is temporarily unavailable"})
+	case errors.As(err, &gwErr):
+		return c.Status(502).JSON(fiber.Map{"error": gwErr.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+type gatewayResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// ListGateways returns the gateways payments can currently be made with
+func (h *PaymentHandler) ListGateways(c *fiber.Ctx) error {
+	gateways := h.Gateways.List()
+	resp := make([]gatewayResponse, 0, len(gateways))
+	for _, g := range gateways {
+		resp = append(resp, gatewayResponse{Name: g.Name, DisplayName: g.DisplayName})
+	}
+	return c.JSON(fiber.Map{"gateways": resp})
+}
+
+// Create starts a payment for the current user through the gateway the
+// client picked
+func (h *PaymentHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type PaymentReq struct {
+		Gateway     string            `json:"gateway"`
+		Amount      int64             `json:"amount"`
+		Unit        string            `json:"unit"`
+		Description string            `json:"description"`
+		Mobile      string            `json:"mobile"`
+		Email       string            `json:"email"`
+		OrderID     string            `json:"order_id"`
+		Metadata    map[string]string `json:"metadata"`
+		CouponCode  string            `json:"coupon_code"`
+	}
+	var req PaymentReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.Gateway == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Gateway is required"})
+	}
+	if _, ok := req.Metadata[domain.PaymentPurposeKey]; ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Metadata key " + domain.PaymentPurposeKey + " is reserved"})
+	}
+
+	user, err := h.UserRepo.GetByID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if req.Mobile == "" {
+		req.Mobile = user.Phone
+	}
+	if req.Email == "" {
+		req.Email = user.Email
+	}
+	amount, err := domain.NewMoney(req.Amount, domain.CurrencyUnit(req.Unit))
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	intent := ports.PaymentIntent{
+		Amount:      amount,
+		CallbackURL: h.callbackURL(req.Gateway),
+		Description: req.Description,
+		PayerMobile: req.Mobile,
+		PayerEmail:  req.Email,
+		OrderID:     req.OrderID,
+		Metadata:    req.Metadata,
+		ClientIP:    c.IP(),
+		CouponCode:  req.CouponCode,
+	}
+	payment, result, err := h.Payments.Start(c.UserContext(), userID, req.Gateway, intent)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return paymentStarted(c, 200, payment, result)
+}
+
+// paymentStarted answers a started payment. Clients send the user to
+// payment_url, or render form_html for gateways that must be POSTed to.
+func paymentStarted(c *fiber.Ctx, status int, payment *domain.Payment, result *ports.PaymentRequestResult) error {
+	var formHTML string
+	if result.Form != nil {
+		var buf strings.Builder
+		if err := gatewayForm.Execute(&buf, fiber.Map{"URL": result.PaymentURL, "Fields": result.Form}); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to render payment form"})
+		}
+		formHTML = buf.String()
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"payment_id":  payment.ID,
+		"gateway":     payment.Gateway,
+		"amount":      payment.Amount,
+		"discount":    payment.Discount,
+		"payment_url": result.PaymentURL,
+		"form":        result.Form,
+		"form_html":   formHTML,
+		"authority":   result.Authority,
+	})
+}
+
+// gatewayForm posts the user to gateways that take a form POST rather than
+// a redirect
+var gatewayForm = template.Must(template.New("gateway").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Redirecting to payment</title></head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.URL}}">
+{{range $name, $value := .Fields}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<noscript><button type="submit">Continue to payment</button></noscript>
+</form>
+</body>
+</html>
+`))
+
+// redirectToGateway sends the user's browser to the gateway, through an
+// auto-submitting form when the gateway must be POSTed to
+func redirectToGateway(c *fiber.Ctx, result *ports.PaymentRequestResult) error {
+	if result.Form == nil {
+		return c.Redirect(result.PaymentURL, fiber.StatusFound)
+	}
+	c.Type("html", "utf-8")
+	return gatewayForm.Execute(c, fiber.Map{"URL": result.PaymentURL, "Fields": result.Form})
+}
+
+// CardToCardUpload returns a URL to upload a card-to-card receipt image to
+// and the key to submit the receipt with
+func (h *PaymentHandler) CardToCardUpload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	url, key, err := h.CardToCard.ReceiptUploadURL(c.UserContext(), userID)
+	if err != nil {
+		logger.Log.Error("Failed to generate receipt upload URL", zap.String("user_id", userID), zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate URL"})
+	}
+	return c.JSON(fiber.Map{"url": url, "receipt_key": key})
+}
+
+// SubmitCardToCard records a card-to-card receipt for manual approval. The
+// receipt image must already be uploaded through CardToCardUpload.
+func (h *PaymentHandler) SubmitCardToCard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type C2CReq struct {
+		Amount         int64     `json:"amount"`
+		TrackingNumber string    `json:"tracking_number"`
+		ReceiptKey     string    `json:"receipt_key"`
+		PaidAt         time.Time `json:"paid_at"`
+		Description    string    `json:"description"`
+	}
+	var req C2CReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	receipt, payment, err := h.CardToCard.Submit(c.UserContext(), userID, req.Amount, req.TrackingNumber, req.ReceiptKey, req.PaidAt, req.Description)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"payment_id": payment.ID, "receipt_id": receipt.ID, "status": string(payment.Status)})
+}
+
+// Get returns one of the current user's payments
+func (h *PaymentHandler) Get(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	payment, err := h.PaymentRepo.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil || payment.UserID != userID {
+		return c.Status(404).JSON(fiber.Map{"error": "Payment not found"})
+	}
+
+	return c.JSON(toPaymentResponse(payment))
+}
+
+// Receipt returns a short-lived link to the PDF receipt of one of the
+// current user's verified payments
+func (h *PaymentHandler) Receipt(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if h.Receipts == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "Receipts are not available"})
+	}
+
+	url, receipt, err := h.Receipts.DownloadURL(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(fiber.Map{"url": url, "number": receipt.Number(), "issued_at": receipt.IssuedAt})
+}
+
+// Callback handles the gateway's return redirect, which some gateways send
+// as a form POST. The payment is verified against the stored amount and the
+// user is redirected to the frontend result page.
+func (h *PaymentHandler) Callback(c *fiber.Ctx) error {
+	gatewayName := c.Params("gateway")
+
+	gateway, err := h.Gateways.Get(gatewayName)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Unknown gateway"})
+	}
+	parser, ok := gateway.(ports.CallbackParser)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Unknown gateway"})
+	}
+
+	params := url.Values{}
+	for k, v := range c.Queries() {
+		params.Set(k, v)
+	}
+	c.Request().PostArgs().VisitAll(func(k, v []byte) {
+		params.Set(string(k), string(v))
+	})
+	callback := parser.ParseCallback(params)
+	if callback.Authority == "" {
+		return h.redirectResult(c, "", "error")
+	}
+
+	payment, err := h.Payments.Verify(c.UserContext(), gatewayName, callback)
+	if err != nil {
+		logger.Log.Error("Payment callback failed",
+			zap.String("gateway", gatewayName),
+			zap.String("authority", callback.Authority),
+			zap.Error(err),
+		)
+		if payment == nil {
+			return h.redirectResult(c, "", "error")
+		}
+		return h.redirectResult(c, payment.ID, "error")
+	}
+
+	return h.redirectResult(c, payment.ID, string(payment.Status))
+}
+
+func (h *PaymentHandler) redirectResult(c *fiber.Ctx, paymentID, status string) error {
+	query := url.Values{}
+	if paymentID != "" {
+		query.Set("payment_id", paymentID)
+	}
+	query.Set("status", status)
+	return c.Redirect(h.ResultURL+"?"+query.Encode(), fiber.StatusFound)
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+func TestPaymentStartedForm(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *ports.PaymentRequestResult
+		wantHTML []string
+	}{
+		{
+			name:   "Redirect Gateway",
+			result: &ports.PaymentRequestResult{Authority: "A1", PaymentURL: "https://gateway.test/pay/A1"},
+		},
+		{
+			name: "Form Gateway",
+			result: &ports.PaymentRequestResult{
+				Authority:  "A1",
+				PaymentURL: "https://gateway.test/pay",
+				Form:       map[string]string{"RefId": "A1"},
+			},
+			wantHTML: []string{`action="https://gateway.test/pay"`, `name="RefId" value="A1"`, "document.forms[0].submit()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/payments", func(c *fiber.Ctx) error {
+				return paymentStarted(c, 200, &domain.Payment{ID: "p1", Gateway: "test", Amount: 50000}, tt.result)
+			})
+			resp, err := app.Test(httptest.NewRequest("POST", "/payments", nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				PaymentURL string `json:"payment_url"`
+				FormHTML   string `json:"form_html"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body.PaymentURL != tt.result.PaymentURL {
+				t.Errorf("payment_url = %q, want %q", body.PaymentURL, tt.result.PaymentURL)
+			}
+			if len(tt.wantHTML) == 0 && body.FormHTML != "" {
+				t.Errorf("form_html = %q, want none", body.FormHTML)
+			}
+			for _, want := range tt.wantHTML {
+				if !strings.Contains(body.FormHTML, want) {
+					t.Errorf("form_html = %q, want it to contain %q", body.FormHTML, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+type PayoutHandler struct {
+	Payouts    *services.PayoutService
+	PayoutRepo ports.PayoutRepository
+}
+
+func NewPayoutHandler(payouts *services.PayoutService, payoutRepo ports.PayoutRepository) *PayoutHandler {
+	return &PayoutHandler{Payouts: payouts, PayoutRepo: payoutRepo}
+}
+
+type bankResponse struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	PersianName string `json:"persian_name"`
+}
+
+type payoutResponse struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	Amount      int64        `json:"amount"`
+	Sheba       string       `json:"sheba"`
+	Bank        bankResponse `json:"bank"`
+	OwnerName   string       `json:"owner_name"`
+	Description string       `json:"description,omitempty"`
+	Status      string       `json:"status"`
+	Gateway     string       `json:"gateway,omitempty"`
+	BatchID     string       `json:"batch_id,omitempty"`
+	Reference   string       `json:"reference,omitempty"`
+	ApprovedBy  string       `json:"approved_by,omitempty"`
+	ProcessedBy string       `json:"processed_by,omitempty"`
+	Note        string       `json:"note,omitempty"`
+	PaidAt      *time.Time   `json:"paid_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func toBankResponse(b domain.Bank) bankResponse {
+	return bankResponse{Code: b.Code, Name: b.Name, PersianName: b.PersianName}
+}
+
+func toPayoutResponse(p *domain.Payout) payoutResponse {
+	return payoutResponse{
+		ID:          p.ID,
+		UserID:      p.UserID,
+		Amount:      p.Amount,
+		Sheba:       p.Sheba,
+		Bank:        toBankResponse(p.Bank()),
+		OwnerName:   p.OwnerName,
+		Description: p.Description,
+		Status:      string(p.Status),
+		Gateway:     p.Gateway,
+		BatchID:     p.BatchID,
+		Reference:   p.Reference,
+		ApprovedBy:  p.ApprovedBy,
+		ProcessedBy: p.ProcessedBy,
+		Note:        p.Note,
+		PaidAt:      p.PaidAt,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func toPayoutResponses(payouts []domain.Payout) []payoutResponse {
+	resp := make([]payoutResponse, 0, len(payouts))
+	for i := range payouts {
+		resp = append(resp, toPayoutResponse(&payouts[i]))
+	}
+	return resp
+}
+
+// payoutError maps payout errors to HTTP responses
+func payoutError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPayout), errors.Is(err, domain.ErrInvalidSheba):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrPayoutSelfApproval):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrPayoutNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Payout not found"})
+	case errors.Is(err, domain.ErrInvalidPayoutState), errors.Is(err, domain.ErrPayoutConflict):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return ledgerError(c, err)
+}
+
+// CheckSheba validates a Sheba number and returns its bank
+func (h *PayoutHandler) CheckSheba(c *fiber.Ctx) error {
+	sheba := domain.NormalizeSheba(c.Params("sheba"))
+	if err := domain.ValidateSheba(sheba); err != nil {
+		return payoutError(c, err)
+	}
+	bank, _ := domain.BankFromSheba(sheba)
+	return c.JSON(fiber.Map{"sheba": sheba, "bank": toBankResponse(bank)})
+}
+
+// Create requests a payout from the current user's wallet
+func (h *PayoutHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type PayoutReq struct {
+		Amount      int64  `json:"amount"`
+		Sheba       string `json:"sheba"`
+		OwnerName   string `json:"owner_name"`
+		Description string `json:"description"`
+	}
+	var req PayoutReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	payout, err := h.Payouts.Request(c.UserContext(), userID, req.Amount, req.Sheba, req.OwnerName, req.Description)
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.Status(201).JSON(toPayoutResponse(payout))
+}
+
+// List returns the current user's payouts, newest first
+func (h *PayoutHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	payouts, err := h.PayoutRepo.ListByUser(c.UserContext(), userID, limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list payouts"})
+	}
+	return c.JSON(fiber.Map{"payouts": toPayoutResponses(payouts)})
+}
+
+// Get returns one of the current user's payouts
+func (h *PayoutHandler) Get(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	payout, err := h.PayoutRepo.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return payoutError(c, err)
+	}
+	if payout.UserID != userID {
+		return payoutError(c, domain.ErrPayoutNotFound)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
+
+// Cancel withdraws one of the current user's payouts before it is approved
+func (h *PayoutHandler) Cancel(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	payout, err := h.Payouts.Cancel(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
+
+// AdminList lists payouts by status, defaulting to those awaiting approval
+func (h *PayoutHandler) AdminList(c *fiber.Ctx) error {
+	status := domain.PayoutStatus(c.Query("status", string(domain.PayoutRequested)))
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	payouts, err := h.PayoutRepo.ListByStatus(c.UserContext(), status, limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list payouts"})
+	}
+	return c.JSON(fiber.Map{"payouts": toPayoutResponses(payouts)})
+}
+
+// Approve lets a payout be dispatched
+func (h *PayoutHandler) Approve(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	payout, err := h.Payouts.Approve(c.UserContext(), c.Params("id"), adminID)
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
+
+// Reject declines a payout and returns its amount to the wallet
+func (h *PayoutHandler) Reject(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type RejectReq struct {
+		Reason string `json:"reason"`
+	}
+	var req RejectReq
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	payout, err := h.Payouts.Reject(c.UserContext(), c.Params("id"), adminID, req.Reason)
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
+
+// ListGateways returns the gateways payouts can be dispatched through
+func (h *PayoutHandler) ListGateways(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"gateways": h.Payouts.Gateways()})
+}
+
+// Dispatch sends the approved payouts through a gateway. Gateways that
+// export a bank batch file get a link to download it.
+func (h *PayoutHandler) Dispatch(c *fiber.Ctx) error {
+	type DispatchReq struct {
+		Gateway string `json:"gateway"`
+	}
+	var req DispatchReq
+	if err := c.BodyParser(&req); err != nil || req.Gateway == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Gateway is required"})
+	}
+
+	result, err := h.Payouts.Dispatch(c.UserContext(), req.Gateway)
+	if err != nil && result == nil {
+		return payoutError(c, err)
+	}
+
+	resp := fiber.Map{
+		"batch_id": result.BatchID,
+		"payouts":  toPayoutResponses(result.Payouts),
+	}
+	if err != nil {
+		// The payouts were marked as processing but their outcome is unknown
+		resp["error"] = err.Error()
+		return c.Status(502).JSON(resp)
+	}
+	if result.File != nil {
+		resp["file_url"] = "/api/admin/payouts/batches/" + result.BatchID + "/file"
+	}
+	return c.JSON(resp)
+}
+
+// BatchFile downloads the bank batch file of a dispatch
+func (h *PayoutHandler) BatchFile(c *fiber.Ctx) error {
+	file, name, err := h.Payouts.BatchFile(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return payoutError(c, err)
+	}
+	c.Attachment(name)
+	return c.Send(file)
+}
+
+// Complete records that a processing payout reached the bank account
+func (h *PayoutHandler) Complete(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type CompleteReq struct {
+		Reference string `json:"reference"`
+	}
+	var req CompleteReq
+	if err := c.BodyParser(&req); err != nil || req.Reference == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Bank reference is required"})
+	}
+
+	payout, err := h.Payouts.Complete(c.UserContext(), c.Params("id"), req.Reference, adminID)
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
+
+// Fail records that the bank returned a processing payout; its amount goes
+// back to the wallet
+func (h *PayoutHandler) Fail(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type FailReq struct {
+		Reason string `json:"reason"`
+	}
+	var req FailReq
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	payout, err := h.Payouts.Fail(c.UserContext(), c.Params("id"), req.Reason, adminID)
+	if err != nil {
+		return payoutError(c, err)
+	}
+	return c.JSON(toPayoutResponse(payout))
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+type RefundHandler struct {
+	Refunds    *services.RefundService
+	RefundRepo ports.RefundRepository
+}
+
+func NewRefundHandler(refunds *services.RefundService, refundRepo ports.RefundRepository) *RefundHandler {
+	return &RefundHandler{Refunds: refunds, RefundRepo: refundRepo}
+}
+
+type refundResponse struct {
+	ID          string    `json:"id"`
+	PaymentID   string    `json:"payment_id"`
+	Amount      int64     `json:"amount"`
+	Reason      string    `json:"reason,omitempty"`
+	Method      string    `json:"method"`
+	Status      string    `json:"status"`
+	Reference   string    `json:"reference,omitempty"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	ProcessedBy string    `json:"processed_by,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func toRefundResponse(r *domain.Refund) refundResponse {
+	return refundResponse{
+		ID:          r.ID,
+		PaymentID:   r.PaymentID,
+		Amount:      r.Amount,
+		Reason:      r.Reason,
+		Method:      r.Method,
+		Status:      string(r.Status),
+		Reference:   r.Reference,
+		RequestedBy: r.RequestedBy,
+		ProcessedBy: r.ProcessedBy,
+		Note:        r.Note,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func toRefundResponses(refunds []domain.Refund) []refundResponse {
+	resp := make([]refundResponse, 0, len(refunds))
+	for i := range refunds {
+		resp = append(resp, toRefundResponse(&refunds[i]))
+	}
+	return resp
+}
+
+// Create refunds part or all of a payment
+func (h *RefundHandler) Create(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type RefundReq struct {
+		Amount int64  `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	var req RefundReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	refund, err := h.Refunds.Request(c.UserContext(), c.Params("id"), req.Amount, req.Reason, adminID)
+	if errors.Is(err, domain.ErrInsufficientFunds) {
+		return c.Status(409).JSON(fiber.Map{"error": "The wallet credit this top-up added was already spent"})
+	}
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	status := 201
+	if refund.Status == domain.RefundPendingManual {
+		status = 202
+	}
+	return c.Status(status).JSON(toRefundResponse(refund))
+}
+
+// ListForPayment lists the refunds of a payment
+func (h *RefundHandler) ListForPayment(c *fiber.Ctx) error {
+	refunds, err := h.RefundRepo.ListByPayment(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list refunds"})
+	}
+	return c.JSON(fiber.Map{"refunds": toRefundResponses(refunds)})
+}
+
+// List lists refunds by status, defaulting to the manual refund queue
+func (h *RefundHandler) List(c *fiber.Ctx) error {
+	status := domain.RefundStatus(c.Query("status", string(domain.RefundPendingManual)))
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	refunds, err := h.RefundRepo.ListByStatus(c.UserContext(), status, limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list refunds"})
+	}
+	return c.JSON(fiber.Map{"refunds": toRefundResponses(refunds)})
+}
+
+// Complete records that a manual refund was paid out
+func (h *RefundHandler) Complete(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type CompleteReq struct {
+		Reference string `json:"reference"`
+	}
+	var req CompleteReq
+	if err := c.BodyParser(&req); err != nil || req.Reference == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Bank reference is required"})
+	}
+
+	refund, err := h.Refunds.CompleteManual(c.UserContext(), c.Params("id"), req.Reference, adminID)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toRefundResponse(refund))
+}
+
+// Reject declines a manual refund
+func (h *RefundHandler) Reject(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type RejectReq struct {
+		Reason string `json:"reason"`
+	}
+	var req RejectReq
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	refund, err := h.Refunds.RejectManual(c.UserContext(), c.Params("id"), req.Reason, adminID)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toRefundResponse(refund))
+}
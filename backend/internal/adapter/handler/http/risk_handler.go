@@ -0,0 +1,171 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+// RiskHandler serves the review queue of payments held by the risk checks
+// and the blocklist
+type RiskHandler struct {
+	Risk        *services.RiskService
+	Payments    *services.PaymentService
+	Refunds     *services.RefundService
+	PaymentRepo ports.PaymentRepository
+}
+
+func NewRiskHandler(risk *services.RiskService, payments *services.PaymentService, refunds *services.RefundService, paymentRepo ports.PaymentRepository) *RiskHandler {
+	return &RiskHandler{Risk: risk, Payments: payments, Refunds: refunds, PaymentRepo: paymentRepo}
+}
+
+type heldPaymentResponse struct {
+	paymentResponse
+	UserID      string   `json:"user_id"`
+	CardPan     string   `json:"card_pan,omitempty"`
+	CardHash    string   `json:"card_hash,omitempty"`
+	ClientIP    string   `json:"client_ip,omitempty"`
+	RiskAction  string   `json:"risk_action"`
+	RiskReasons []string `json:"risk_reasons"`
+}
+
+func toHeldPaymentResponse(p *domain.Payment) heldPaymentResponse {
+	return heldPaymentResponse{
+		paymentResponse: toPaymentResponse(p),
+		UserID:          p.UserID,
+		CardPan:         p.CardPan,
+		CardHash:        p.CardHash,
+		ClientIP:        p.ClientIP,
+		RiskAction:      string(p.Risk.Action),
+		RiskReasons:     p.Risk.Reasons,
+	}
+}
+
+type blocklistEntryResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toBlocklistEntryResponse(e *domain.BlocklistEntry) blocklistEntryResponse {
+	return blocklistEntryResponse{
+		ID:        e.ID,
+		Kind:      string(e.Kind),
+		Value:     e.Value,
+		Reason:    e.Reason,
+		CreatedBy: e.CreatedBy,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// ListHeld lists payments held for review, oldest first
+func (h *RiskHandler) ListHeld(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	payments, err := h.PaymentRepo.ListStale(c.UserContext(), []domain.PaymentStatus{domain.PaymentOnHold}, time.Now(), limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list held payments"})
+	}
+	resp := make([]heldPaymentResponse, 0, len(payments))
+	for _, p := range payments {
+		resp = append(resp, toHeldPaymentResponse(p))
+	}
+	return c.JSON(fiber.Map{"payments": resp})
+}
+
+type reviewReq struct {
+	Reason string `json:"reason"`
+}
+
+// Release verifies a held payment, letting what was paid for be fulfilled
+func (h *RiskHandler) Release(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	var req reviewReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	payment, err := h.Payments.ReleaseHeld(c.UserContext(), c.Params("id"), adminID, req.Reason)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(toHeldPaymentResponse(payment))
+}
+
+// Reject refunds a held payment in full
+func (h *RiskHandler) Reject(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	var req reviewReq
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	refund, err := h.Refunds.RejectHeld(c.UserContext(), c.Params("id"), req.Reason, adminID)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	status := 201
+	if refund.Status == domain.RefundPendingManual {
+		status = 202
+	}
+	return c.Status(status).JSON(toRefundResponse(refund))
+}
+
+// ListBlocklist lists blocked mobiles and card hashes, optionally of one kind
+func (h *RiskHandler) ListBlocklist(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	entries, err := h.Risk.ListBlocked(c.UserContext(), domain.BlocklistKind(c.Query("kind")), limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list blocklist"})
+	}
+	resp := make([]blocklistEntryResponse, 0, len(entries))
+	for i := range entries {
+		resp = append(resp, toBlocklistEntryResponse(&entries[i]))
+	}
+	return c.JSON(fiber.Map{"entries": resp})
+}
+
+// Block adds a mobile or card hash to the blocklist
+func (h *RiskHandler) Block(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+
+	type BlockReq struct {
+		Kind   string `json:"kind"`
+		Value  string `json:"value"`
+		Reason string `json:"reason"`
+	}
+	var req BlockReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	entry, err := h.Risk.Block(c.UserContext(), domain.BlocklistKind(req.Kind), req.Value, req.Reason, adminID)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return c.Status(201).JSON(toBlocklistEntryResponse(entry))
+}
+
+// Unblock removes a blocklist entry
+func (h *RiskHandler) Unblock(c *fiber.Ctx) error {
+	if err := h.Risk.Unblock(c.UserContext(), c.Params("id")); err != nil {
+		return paymentError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Entry removed"})
+}
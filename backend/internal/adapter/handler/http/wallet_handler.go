@@ -0,0 +1,166 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+)
+
+type WalletHandler struct {
+	Ledger   *services.LedgerService
+	UserRepo ports.UserRepository
+
+	// CallbackBaseURL is the public URL of the payments API; top-up
+	// payments return to CallbackBaseURL/{gateway}/callback
+	CallbackBaseURL string
+}
+
+func NewWalletHandler(ledger *services.LedgerService, userRepo ports.UserRepository, callbackBaseURL string) *WalletHandler {
+	return &WalletHandler{Ledger: ledger, UserRepo: userRepo, CallbackBaseURL: callbackBaseURL}
+}
+
+type statementResponse struct {
+	EntryID     string    `json:"entry_id"`
+	Kind        string    `json:"kind"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description,omitempty"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ledgerError maps ledger errors to HTTP responses
+func ledgerError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return c.Status(402).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrDuplicateEntry), errors.Is(err, domain.ErrLedgerConflict):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrEntryNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "No such purchase to refund"})
+	case errors.Is(err, domain.ErrPaymentNotToppable), errors.Is(err, domain.ErrRefundExceedsSpend):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return paymentError(c, err)
+}
+
+// Balance returns the current user's wallet balance
+func (h *WalletHandler) Balance(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	wallet, err := h.Ledger.Balance(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load wallet"})
+	}
+	return c.JSON(fiber.Map{"balance": wallet.Balance, "unit": string(domain.CurrencyRial)})
+}
+
+// History returns the current user's wallet statements, newest first.
+// Credits are positive amounts and debits negative.
+func (h *WalletHandler) History(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	statements, err := h.Ledger.History(c.UserContext(), userID, limit, offset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load wallet history"})
+	}
+
+	resp := make([]statementResponse, 0, len(statements))
+	for _, s := range statements {
+		resp = append(resp, statementResponse{
+			EntryID:     s.EntryID,
+			Kind:        string(s.Kind),
+			Reference:   s.Reference,
+			Description: s.Description,
+			Amount:      s.Credit - s.Debit,
+			CreatedAt:   s.CreatedAt,
+		})
+	}
+	return c.JSON(fiber.Map{"entries": resp, "limit": limit, "offset": offset})
+}
+
+// TopUp starts a payment that credits the current user's wallet once it is
+// verified
+func (h *WalletHandler) TopUp(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	type TopUpReq struct {
+		Gateway string `json:"gateway"`
+		Amount  int64  `json:"amount"`
+		Unit    string `json:"unit"`
+	}
+	var req TopUpReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.Gateway == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Gateway is required"})
+	}
+	amount, err := domain.NewMoney(req.Amount, domain.CurrencyUnit(req.Unit))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user, err := h.UserRepo.GetByID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	intent := ports.PaymentIntent{
+		Amount:      amount,
+		CallbackURL: fmt.Sprintf("%s/%s/callback", h.CallbackBaseURL, req.Gateway),
+		Description: "Wallet top-up",
+		PayerMobile: user.Phone,
+		PayerEmail:  user.Email,
+		ClientIP:    c.IP(),
+	}
+	payment, result, err := h.Ledger.StartTopUp(c.UserContext(), userID, req.Gateway, intent)
+	if err != nil {
+		return paymentError(c, err)
+	}
+	return paymentStarted(c, 201, payment, result)
+}
+
+// ClaimTopUp credits the wallet with a verified top-up payment whose credit
+// did not go through on verification
+func (h *WalletHandler) ClaimTopUp(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	entry, err := h.Ledger.TopUpFromPayment(c.UserContext(), userID, c.Params("id"))
+	if err != nil {
+		return ledgerError(c, err)
+	}
+	return c.Status(201).JSON(fiber.Map{"entry_id": entry.ID})
+}
+
+// AdminRefund gives spent credit back to a user's wallet
+func (h *WalletHandler) AdminRefund(c *fiber.Ctx) error {
+	type RefundReq struct {
+		Amount    int64  `json:"amount"`
+		Reference string `json:"reference"`
+		Reason    string `json:"reason"`
+	}
+	var req RefundReq
+	if err := c.BodyParser(&req); err != nil || req.Reference == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Amount and reference are required"})
+	}
+
+	entry, err := h.Ledger.Refund(c.UserContext(), c.Params("id"), req.Amount, req.Reference, req.Reason)
+	if err != nil {
+		return ledgerError(c, err)
+	}
+	return c.Status(201).JSON(fiber.Map{"entry_id": entry.ID})
+}
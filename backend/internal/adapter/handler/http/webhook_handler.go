@@ -0,0 +1,338 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/adapter/handler/http/middleware"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/internal/core/services"
+	"github.com/youruser/yourproject/pkg/webhook"
+)
+
+// WebhookHandler manages webhook endpoints and their delivery log. Under a
+// tenant it serves the organization's endpoints; elsewhere it serves the
+// app-wide endpoints.
+type WebhookHandler struct {
+	Webhooks    *services.WebhookService
+	WebhookRepo ports.WebhookRepository
+}
+
+func NewWebhookHandler(webhooks *services.WebhookService, webhookRepo ports.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{Webhooks: webhooks, WebhookRepo: webhookRepo}
+}
+
+type webhookEndpointResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Secret is only returned when it is created or rotated
+	Secret string `json:"secret,omitempty"`
+}
+
+type webhookDeliveryResponse struct {
+	ID             string                   `json:"id"`
+	EndpointID     string                   `json:"endpoint_id"`
+	EventID        string                   `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty"`
+	LastStatusCode int                      `json:"last_status_code,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	Payload        json.RawMessage          `json:"payload,omitempty"`
+	Log            []webhookAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+type webhookAttemptResponse struct {
+	Attempt      int       `json:"attempt"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	DurationMS   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func toWebhookEndpointResponse(e *domain.WebhookEndpoint) webhookEndpointResponse {
+	resp := webhookEndpointResponse{
+		ID:          e.ID,
+		URL:         e.URL,
+		Description: e.Description,
+		Events:      e.Events,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+	if resp.Events == nil {
+		resp.Events = []string{}
+	}
+	return resp
+}
+
+func toWebhookDeliveryResponse(d *domain.WebhookDelivery) webhookDeliveryResponse {
+	resp := webhookDeliveryResponse{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if d.Status == domain.WebhookPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
+
+// webhookError maps webhook errors to HTTP responses
+func webhookError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidWebhookEndpoint):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrWebhookEndpointNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Webhook endpoint not found"})
+	case errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Webhook delivery not found"})
+	case errors.Is(err, domain.ErrInvalidWebhookState):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Internal server error"})
+}
+
+// endpoint loads an endpoint of the request's scope; endpoints of other
+// scopes are reported as not found
+func (h *WebhookHandler) endpoint(c *fiber.Ctx, id string) (*domain.WebhookEndpoint, error) {
+	endpoint, err := h.WebhookRepo.GetEndpoint(c.UserContext(), id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.OrganizationID != middleware.TenantID(c) {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+// delivery loads a delivery to an endpoint of the request's scope
+func (h *WebhookHandler) delivery(c *fiber.Ctx) (*domain.WebhookDelivery, error) {
+	delivery, err := h.WebhookRepo.GetDelivery(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.endpoint(c, delivery.EndpointID); err != nil {
+		if errors.Is(err, domain.ErrWebhookEndpointNotFound) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+type webhookEndpointRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+// ListEvents returns the event types endpoints can subscribe to
+func (h *WebhookHandler) ListEvents(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"events": domain.WebhookEventTypes})
+}
+
+// List returns the endpoints of the scope
+func (h *WebhookHandler) List(c *fiber.Ctx) error {
+	endpoints, err := h.WebhookRepo.ListEndpoints(c.UserContext(), middleware.TenantID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list webhook endpoints"})
+	}
+	resp := make([]webhookEndpointResponse, 0, len(endpoints))
+	for i := range endpoints {
+		resp = append(resp, toWebhookEndpointResponse(&endpoints[i]))
+	}
+	return c.JSON(fiber.Map{"endpoints": resp})
+}
+
+// Get returns an endpoint
+func (h *WebhookHandler) Get(c *fiber.Ctx) error {
+	endpoint, err := h.endpoint(c, c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(toWebhookEndpointResponse(endpoint))
+}
+
+// Create adds an endpoint and returns its signing secret, which is not
+// shown again
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req webhookEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate secret"})
+	}
+	endpoint, err := domain.NewWebhookEndpoint(middleware.TenantID(c), req.URL, req.Description, req.Events, secret, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if err := h.WebhookRepo.CreateEndpoint(c.UserContext(), endpoint); err != nil {
+		return webhookError(c, err)
+	}
+
+	resp := toWebhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	return c.Status(201).JSON(resp)
+}
+
+// Update replaces an endpoint's URL, description, events and, if given,
+// availability. Deliveries to inactive endpoints wait until they are
+// activated again.
+func (h *WebhookHandler) Update(c *fiber.Ctx) error {
+	endpoint, err := h.endpoint(c, c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	var req webhookEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	endpoint.URL = req.URL
+	endpoint.Description = req.Description
+	endpoint.Events = req.Events
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if err := endpoint.Validate(); err != nil {
+		return webhookError(c, err)
+	}
+	endpoint.UpdatedAt = time.Now()
+	if err := h.WebhookRepo.UpdateEndpoint(c.UserContext(), endpoint); err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(toWebhookEndpointResponse(endpoint))
+}
+
+// RotateSecret replaces an endpoint's signing secret and returns the new
+// one; deliveries are signed with it from now on
+func (h *WebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	endpoint, err := h.endpoint(c, c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate secret"})
+	}
+	endpoint.Secret = secret
+	endpoint.UpdatedAt = time.Now()
+	if err := h.WebhookRepo.UpdateEndpoint(c.UserContext(), endpoint); err != nil {
+		return webhookError(c, err)
+	}
+
+	resp := toWebhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	return c.JSON(resp)
+}
+
+// Delete removes an endpoint with its deliveries and their log
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	endpoint, err := h.endpoint(c, c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	if err := h.WebhookRepo.DeleteEndpoint(c.UserContext(), endpoint.ID); err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Webhook endpoint deleted"})
+}
+
+// ListDeliveries returns an endpoint's deliveries, newest first, optionally
+// filtered by status
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	endpoint, err := h.endpoint(c, c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	status := domain.WebhookDeliveryStatus(c.Query("status"))
+
+	deliveries, err := h.WebhookRepo.ListDeliveries(c.UserContext(), endpoint.ID, status, limit, c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to list webhook deliveries"})
+	}
+	resp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		resp = append(resp, toWebhookDeliveryResponse(&deliveries[i]))
+	}
+	return c.JSON(fiber.Map{"deliveries": resp})
+}
+
+// GetDelivery returns a delivery with its payload and attempt log
+func (h *WebhookHandler) GetDelivery(c *fiber.Ctx) error {
+	delivery, err := h.delivery(c)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	attempts, err := h.WebhookRepo.ListAttempts(c.UserContext(), delivery.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load the delivery log"})
+	}
+
+	resp := toWebhookDeliveryResponse(delivery)
+	resp.Payload = delivery.Payload
+	resp.Log = make([]webhookAttemptResponse, 0, len(attempts))
+	for _, a := range attempts {
+		resp.Log = append(resp.Log, webhookAttemptResponse{
+			Attempt:      a.Attempt,
+			StatusCode:   a.StatusCode,
+			Error:        a.Error,
+			ResponseBody: a.ResponseBody,
+			DurationMS:   a.Duration.Milliseconds(),
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// Replay sends a failed or succeeded delivery again. The event keeps its ID
+// so receivers can tell it is a repeat.
+func (h *WebhookHandler) Replay(c *fiber.Ctx) error {
+	delivery, err := h.delivery(c)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	if err := h.Webhooks.Replay(c.UserContext(), delivery); err != nil {
+		return webhookError(c, err)
+	}
+	return c.Status(202).JSON(toWebhookDeliveryResponse(delivery))
+}
@@ -1,29 +1,41 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"sync"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/youruser/yourproject/internal/adapter/handler/http/middleware"
 )
 
+// userMessage is a message addressed to the connections of one user
+type userMessage struct {
+	userID  string
+	payload []byte
+}
+
 // WebSocketHandler handles websocket connections
 type WebSocketHandler struct {
-	clients    map[*websocket.Conn]bool
+	// clients maps each connection to its authenticated user ID ("" if anonymous)
+	clients    map[*websocket.Conn]string
 	register   chan *websocket.Conn
 	unregister chan *websocket.Conn
 	broadcast  chan []byte
+	direct     chan userMessage
 	mu         sync.Mutex
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler
 func NewWebSocketHandler() *WebSocketHandler {
 	return &WebSocketHandler{
-		clients:    make(map[*websocket.Conn]bool),
+		clients:    make(map[*websocket.Conn]string),
 		register:   make(chan *websocket.Conn),
 		unregister: make(chan *websocket.Conn),
 		broadcast:  make(chan []byte),
+		direct:     make(chan userMessage),
 	}
 }
 
@@ -32,8 +44,9 @@ func (h *WebSocketHandler) Run() {
 	for {
 		select {
 		case client := <-h.register:
+			userID, _ := client.Locals("user_id").(string)
 			h.mu.Lock()
-			h.clients[client] = true
+			h.clients[client] = userID
 			h.mu.Unlock()
 			log.Println("Client connected")
 
@@ -49,10 +62,15 @@ func (h *WebSocketHandler) Run() {
 		case message := <-h.broadcast:
 			h.mu.Lock()
 			for client := range h.clients {
-				if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
-					log.Println("write error:", err)
-					client.Close()
-					delete(h.clients, client)
+				h.write(client, message)
+			}
+			h.mu.Unlock()
+
+		case message := <-h.direct:
+			h.mu.Lock()
+			for client, userID := range h.clients {
+				if userID == message.userID {
+					h.write(client, message.payload)
 				}
 			}
 			h.mu.Unlock()
@@ -60,6 +78,15 @@ func (h *WebSocketHandler) Run() {
 	}
 }
 
+// write sends a message and drops the client on failure; h.mu must be held
+func (h *WebSocketHandler) write(client *websocket.Conn, message []byte) {
+	if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
+		log.Println("write error:", err)
+		client.Close()
+		delete(h.clients, client)
+	}
+}
+
 // HandleWebSocket handles the websocket connection
 func (h *WebSocketHandler) HandleWebSocket(c *websocket.Conn) {
 	defer func() {
@@ -87,11 +114,36 @@ func (h *WebSocketHandler) BroadcastMessage(message string) {
 	h.broadcast <- []byte(message)
 }
 
-// WebSocketMiddleware to upgrade connection
+// NotifyUser sends a {"type": ..., "data": ...} event to every connection
+// the user authenticated with
+func (h *WebSocketHandler) NotifyUser(ctx context.Context, userID string, eventType string, payload interface{}) error {
+	message, err := json.Marshal(fiber.Map{"type": eventType, "data": payload})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.direct <- userMessage{userID: userID, payload: message}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WebSocketMiddleware to upgrade connection. Browsers cannot set headers on
+// websocket requests, so an access token may be passed as ?token= to receive
+// events addressed to the user.
 func WebSocketMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
 			c.Locals("allowed", true)
+			if token := c.Query("token"); token != "" {
+				claims, err := middleware.ParseToken(token)
+				if err != nil || claims["is_temp"] == true {
+					return c.Status(fiber.StatusUnauthorized).SendString("Invalid token")
+				}
+				c.Locals("user_id", claims["user_id"])
+			}
 			return c.Next()
 		}
 		return c.Status(fiber.StatusUpgradeRequired).SendString("Upgrade Required")
@@ -0,0 +1,222 @@
+package idpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+const IDPayBaseURL = "https://api.idpay.ir/v1.1"
+
+// Payment statuses reported by IDPay
+const (
+	statusAwaitingVerify  = 10
+	statusVerified        = 100
+	statusAlreadyVerified = 101
+	statusSettled         = 200
+)
+
+type IDPayAdapter struct {
+	APIKey string
+	// BaseURL is the API root, e.g. for a proxy or test server
+	BaseURL string
+	// Sandbox sends test payments that move no money
+	Sandbox bool
+	Client  *http.Client
+}
+
+func NewIDPayAdapter(apiKey string) *IDPayAdapter {
+	return &IDPayAdapter{
+		APIKey:  apiKey,
+		BaseURL: IDPayBaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports a missing API key
+func (a *IDPayAdapter) CheckConfig() error {
+	if a.APIKey == "" {
+		return errors.New("IDPAY_API_KEY is not set")
+	}
+	return nil
+}
+
+// Unit reports that IDPay counts in Rials
+func (a *IDPayAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
+// IDPay verifies with both its payment ID and the merchant's order ID, so
+// the authority carries both as "id:order_id"
+func authority(id, orderID string) string {
+	return id + ":" + orderID
+}
+
+func splitAuthority(authority string) (id, orderID string) {
+	id, orderID, _ = strings.Cut(authority, ":")
+	return id, orderID
+}
+
+type errorResponse struct {
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// post sends payload to path and decodes a successful response into out.
+// Failures come back as non-2xx statuses with an error code.
+func (a *IDPayAdapter) post(ctx context.Context, path string, payload, out interface{}) ([]byte, error) {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", a.BaseURL+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-KEY", a.APIKey)
+	if a.Sandbox {
+		req.Header.Set("X-SANDBOX", "1")
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var result errorResponse
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return raw, fmt.Errorf("idpay returned status %d: %w", resp.StatusCode, err)
+		}
+		return raw, &ports.GatewayError{Gateway: "idpay", Code: result.ErrorCode, Message: result.ErrorMessage, Raw: raw}
+	}
+	return raw, json.Unmarshal(raw, out)
+}
+
+type requestPayload struct {
+	OrderID  string `json:"order_id"`
+	Amount   int64  `json:"amount"`
+	Phone    string `json:"phone,omitempty"`
+	Mail     string `json:"mail,omitempty"`
+	Desc     string `json:"desc,omitempty"`
+	Callback string `json:"callback"`
+}
+
+type requestResponse struct {
+	ID   string `json:"id"`
+	Link string `json:"link"`
+}
+
+func (a *IDPayAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(a.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	// IDPay requires an order ID
+	orderID := intent.OrderID
+	if orderID == "" {
+		orderID = uuid.NewString()
+	}
+
+	payload := requestPayload{
+		OrderID:  orderID,
+		Amount:   amount.Amount,
+		Phone:    intent.PayerMobile,
+		Mail:     intent.PayerEmail,
+		Desc:     intent.Description,
+		Callback: intent.CallbackURL,
+	}
+
+	var result requestResponse
+	raw, err := a.post(ctx, "/payment", payload, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.ID == "" || result.Link == "" {
+		return nil, fmt.Errorf("idpay returned no payment link: %s", raw)
+	}
+
+	return &ports.PaymentRequestResult{
+		PaymentURL: result.Link,
+		Authority:  authority(result.ID, orderID),
+		Raw:        raw,
+	}, nil
+}
+
+type verifyPayload struct {
+	ID      string `json:"id"`
+	OrderID string `json:"order_id"`
+}
+
+type verifyResponse struct {
+	Status  int         `json:"status"`
+	TrackID json.Number `json:"track_id"`
+	Amount  json.Number `json:"amount"`
+	Payment struct {
+		TrackID      json.Number `json:"track_id"`
+		CardNo       string      `json:"card_no"`
+		HashedCardNo string      `json:"hashed_card_no"`
+	} `json:"payment"`
+}
+
+func (a *IDPayAdapter) VerifyPayment(ctx context.Context, authority string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	expected, err := amount.In(a.Unit())
+	if err != nil {
+		return nil, err
+	}
+	id, orderID := splitAuthority(authority)
+
+	var result verifyResponse
+	raw, err := a.post(ctx, "/payment/verify", verifyPayload{ID: id, OrderID: orderID}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status != statusVerified && result.Status != statusAlreadyVerified {
+		return nil, &ports.GatewayError{Gateway: "idpay", Code: result.Status, Message: "payment is not verified", Raw: raw}
+	}
+	// IDPay verifies by ID alone, so check it settled the expected amount
+	if paid, err := result.Amount.Int64(); err == nil && paid != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "idpay", Code: result.Status, Message: fmt.Sprintf("paid amount %d does not match %d", paid, expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:           result.Payment.TrackID.String(),
+		AlreadyVerified: result.Status == statusAlreadyVerified,
+		CardPan:         result.Payment.CardNo,
+		CardHash:        result.Payment.HashedCardNo,
+		Raw:             raw,
+	}, nil
+}
+
+// ParseCallback reads the status, id and order_id IDPay posts back. Status
+// 10 means paid and awaiting verification; anything below failed or was
+// cancelled.
+func (a *IDPayAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	id, orderID := params.Get("id"), params.Get("order_id")
+	if id == "" {
+		return ports.PaymentCallback{}
+	}
+
+	paid := false
+	switch params.Get("status") {
+	case fmt.Sprint(statusAwaitingVerify), fmt.Sprint(statusVerified), fmt.Sprint(statusAlreadyVerified), fmt.Sprint(statusSettled):
+		paid = true
+	}
+	return ports.PaymentCallback{Authority: authority(id, orderID), Paid: paid}
+}
@@ -0,0 +1,186 @@
+package idpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a server answering every request with
+// status and body
+func newTestAdapter(t *testing.T, status int, body string, check func(r *http.Request, payload map[string]interface{})) *IDPayAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if check != nil {
+			check(r, payload)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewIDPayAdapter("test-key")
+	a.BaseURL = server.URL
+	a.Sandbox = true
+	return a
+}
+
+func TestRequestPayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantAuthority string
+		wantURL       string
+		wantCode      int
+		wantErr       bool
+	}{
+		{
+			name:          "Success",
+			status:        201,
+			body:          `{"id":"d2e353189823079e1e4181772cff5292","link":"https://idpay.ir/p/ws-sandbox/d2e353189823079e1e4181772cff5292"}`,
+			wantAuthority: "d2e353189823079e1e4181772cff5292:order-1",
+			wantURL:       "https://idpay.ir/p/ws-sandbox/d2e353189823079e1e4181772cff5292",
+		},
+		{name: "Rejected", status: 406, body: `{"error_code":32,"error_message":"invalid order_id"}`, wantErr: true, wantCode: 32},
+		{name: "Malformed", status: 201, body: `<html>bad gateway</html>`, wantErr: true},
+		{name: "Missing Link", status: 201, body: `{"id":"abc"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.status, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/payment" {
+					t.Errorf("path = %s, want /payment", r.URL.Path)
+				}
+				if r.Header.Get("X-API-KEY") != "test-key" || r.Header.Get("X-SANDBOX") != "1" {
+					t.Errorf("headers = %v, want API key and sandbox", r.Header)
+				}
+				if payload["amount"] != float64(15000) || payload["order_id"] != "order-1" {
+					t.Errorf("payload = %v, want 15000 Rials for order-1", payload)
+				}
+			})
+
+			intent := ports.PaymentIntent{Amount: domain.Tomans(1500), CallbackURL: "https://example.com/cb", OrderID: "order-1"}
+			result, err := a.RequestPayment(context.Background(), intent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RequestPayment() error = nil, want error")
+				}
+				var gwErr *ports.GatewayError
+				if tt.wantCode != 0 && (!errors.As(err, &gwErr) || gwErr.Code != tt.wantCode) {
+					t.Errorf("RequestPayment() error = %v, want gateway code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+			if result.Authority != tt.wantAuthority || result.PaymentURL != tt.wantURL {
+				t.Errorf("RequestPayment() = %+v, want authority %s and URL %s", result, tt.wantAuthority, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestVerifyPayment(t *testing.T) {
+	tests := []struct {
+		name                string
+		status              int
+		body                string
+		wantRefID           string
+		wantAlreadyVerified bool
+		wantGatewayErr      bool
+		wantErr             bool
+	}{
+		{
+			name:      "Success",
+			status:    200,
+			body:      `{"status":100,"track_id":"10012","id":"abc","order_id":"order-1","amount":"15000","payment":{"track_id":"888001","amount":"15000","card_no":"123456******1234","hashed_card_no":"E59FA6241C94B8836E3D03120DF33E80FD988888BBA0A122240C2E7D23B48295"}}`,
+			wantRefID: "888001",
+		},
+		{
+			name:                "Already Verified",
+			status:              200,
+			body:                `{"status":101,"track_id":10012,"amount":15000,"payment":{"track_id":888001,"card_no":"123456******1234"}}`,
+			wantRefID:           "888001",
+			wantAlreadyVerified: true,
+		},
+		{name: "Cancelled", status: 405, body: `{"error_code":53,"error_message":"payment cannot be verified"}`, wantGatewayErr: true},
+		{name: "Not Paid", status: 200, body: `{"status":7,"amount":"15000"}`, wantGatewayErr: true},
+		{name: "Amount Mismatch", status: 200, body: `{"status":100,"amount":"1000","payment":{"track_id":"888001"}}`, wantGatewayErr: true},
+		{name: "Malformed", status: 200, body: `{"status":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.status, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/payment/verify" {
+					t.Errorf("path = %s, want /payment/verify", r.URL.Path)
+				}
+				if payload["id"] != "abc" || payload["order_id"] != "order-1" {
+					t.Errorf("payload = %v, want id abc and order-1", payload)
+				}
+			})
+
+			result, err := a.VerifyPayment(context.Background(), "abc:order-1", domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want decoding error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyPayment() error = %v", err)
+			}
+			if result.RefID != tt.wantRefID || result.AlreadyVerified != tt.wantAlreadyVerified || result.CardPan == "" {
+				t.Errorf("VerifyPayment() = %+v, want ref %s, already verified %v", result, tt.wantRefID, tt.wantAlreadyVerified)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		want   ports.PaymentCallback
+	}{
+		{
+			name:   "Paid",
+			params: url.Values{"status": {"10"}, "id": {"abc"}, "order_id": {"order-1"}, "track_id": {"1"}},
+			want:   ports.PaymentCallback{Authority: "abc:order-1", Paid: true},
+		},
+		{
+			name:   "Cancelled",
+			params: url.Values{"status": {"7"}, "id": {"abc"}, "order_id": {"order-1"}},
+			want:   ports.PaymentCallback{Authority: "abc:order-1", Paid: false},
+		},
+		{name: "Malformed", params: url.Values{"status": {"10"}}, want: ports.PaymentCallback{}},
+	}
+
+	a := NewIDPayAdapter("test-key")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.ParseCallback(tt.params); got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package mellat
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+const MellatBaseURL = "https://bpm.shaparak.ir/pgwchannel"
+
+// Result codes reported by Behpardakht Mellat
+const (
+	codeOK = 0
+	// codeAlreadyVerified and codeAlreadySettled are returned when a
+	// transaction is verified or settled twice
+	codeAlreadyVerified = 43
+	codeAlreadySettled  = 45
+)
+
+// Behpardakht Mellat's bank IPG. Payments are requested and verified over
+// SOAP, and the user is sent to the bank with a form POST.
+type MellatAdapter struct {
+	TerminalID int64
+	Username   string
+	Password   string
+	// BaseURL is the channel root holding the SOAP service and the start
+	// page, e.g. for a proxy or test server
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewMellatAdapter(terminalID int64, username, password string) *MellatAdapter {
+	return &MellatAdapter{
+		TerminalID: terminalID,
+		Username:   username,
+		Password:   password,
+		BaseURL:    MellatBaseURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports missing terminal credentials
+func (m *MellatAdapter) CheckConfig() error {
+	if m.TerminalID == 0 || m.Username == "" || m.Password == "" {
+		return errors.New("MELLAT_TERMINAL_ID, MELLAT_USERNAME and MELLAT_PASSWORD must be set")
+	}
+	return nil
+}
+
+// Unit reports that Mellat counts in Rials
+func (m *MellatAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
+// Mellat verifies with the numeric order ID the payment was requested with,
+// so the authority carries both the RefId and the order ID as
+// "ref_id:order_id"
+func authority(refID string, orderID int64) string {
+	return refID + ":" + strconv.FormatInt(orderID, 10)
+}
+
+func splitAuthority(authority string) (refID string, orderID int64, err error) {
+	refID, order, _ := strings.Cut(authority, ":")
+	orderID, err = strconv.ParseInt(order, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed mellat authority %q", authority)
+	}
+	return refID, orderID, nil
+}
+
+// newOrderID returns a random positive order ID; Mellat requires a unique
+// number per request
+func newOrderID() int64 {
+	id := uuid.New()
+	return int64(binary.BigEndian.Uint64(id[:8]) &^ (1 << 63))
+}
+
+type envelope struct {
+	XMLName xml.Name `xml:"soapenv:Envelope"`
+	SoapEnv string   `xml:"xmlns:soapenv,attr"`
+	Int     string   `xml:"xmlns:int,attr"`
+	Body    struct {
+		Request interface{}
+	} `xml:"soapenv:Body"`
+}
+
+type responseEnvelope struct {
+	Body struct {
+		Fault *struct {
+			Code   string `xml:"faultcode"`
+			String string `xml:"faultstring"`
+		} `xml:"Fault"`
+		Response struct {
+			Return string `xml:"return"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// call invokes a SOAP operation and returns the text of its return element
+func (m *MellatAdapter) call(ctx context.Context, request interface{}) (string, []byte, error) {
+	env := envelope{SoapEnv: "http://schemas.xmlsoap.org/soap/envelope/", Int: "http://interfaces.core.sw.bps.com/"}
+	env.Body.Request = request
+	body, err := xml.Marshal(env)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", m.BaseURL+"/services/pgw", bytes.NewBuffer(append([]byte(xml.Header), body...)))
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var result responseEnvelope
+	if err := xml.Unmarshal(raw, &result); err != nil {
+		return "", raw, fmt.Errorf("mellat returned status %d: %w", resp.StatusCode, err)
+	}
+	if result.Body.Fault != nil {
+		return "", raw, fmt.Errorf("mellat SOAP fault %s: %s", result.Body.Fault.Code, result.Body.Fault.String)
+	}
+	ret := strings.TrimSpace(result.Body.Response.Return)
+	if ret == "" {
+		return "", raw, fmt.Errorf("mellat returned no result: %s", raw)
+	}
+	return ret, raw, nil
+}
+
+// resultCode parses the numeric code leading a return value
+func resultCode(ret string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(ret, ",", 2)[0]))
+	if err != nil {
+		return 0, fmt.Errorf("mellat returned a malformed result %q", ret)
+	}
+	return code, nil
+}
+
+type payRequest struct {
+	XMLName        xml.Name `xml:"int:bpPayRequest"`
+	TerminalID     int64    `xml:"terminalId"`
+	UserName       string   `xml:"userName"`
+	UserPassword   string   `xml:"userPassword"`
+	OrderID        int64    `xml:"orderId"`
+	Amount         int64    `xml:"amount"`
+	LocalDate      string   `xml:"localDate"`
+	LocalTime      string   `xml:"localTime"`
+	AdditionalData string   `xml:"additionalData"`
+	CallBackURL    string   `xml:"callBackUrl"`
+	PayerID        string   `xml:"payerId"`
+}
+
+func (m *MellatAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(m.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orderID := newOrderID()
+	request := payRequest{
+		TerminalID:     m.TerminalID,
+		UserName:       m.Username,
+		UserPassword:   m.Password,
+		OrderID:        orderID,
+		Amount:         amount.Amount,
+		LocalDate:      now.Format("20060102"),
+		LocalTime:      now.Format("150405"),
+		AdditionalData: intent.Description,
+		CallBackURL:    intent.CallbackURL,
+		PayerID:        "0",
+	}
+
+	ret, raw, err := m.call(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// A successful request returns "0,<RefId>"; anything else is an error
+	// code
+	code, err := resultCode(ret)
+	if err != nil {
+		return nil, err
+	}
+	_, refID, _ := strings.Cut(ret, ",")
+	if code != codeOK || refID == "" {
+		return nil, &ports.GatewayError{Gateway: "mellat", Code: code, Raw: raw}
+	}
+
+	form := map[string]string{"RefId": refID}
+	if intent.PayerMobile != "" {
+		form["MobileNo"] = intent.PayerMobile
+	}
+	return &ports.PaymentRequestResult{
+		PaymentURL: m.BaseURL + "/startpay.mellat",
+		Form:       form,
+		Authority:  authority(refID, orderID),
+		Raw:        raw,
+	}, nil
+}
+
+// VerifyPayment cannot verify without the sale reference the bank posts to
+// the callback
+func (m *MellatAdapter) VerifyPayment(ctx context.Context, authority string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	return nil, &ports.GatewayError{Gateway: "mellat", Message: "payments can only be verified from their callback"}
+}
+
+type verifyRequest struct {
+	XMLName         xml.Name
+	TerminalID      int64  `xml:"terminalId"`
+	UserName        string `xml:"userName"`
+	UserPassword    string `xml:"userPassword"`
+	OrderID         int64  `xml:"orderId"`
+	SaleOrderID     int64  `xml:"saleOrderId"`
+	SaleReferenceID string `xml:"saleReferenceId"`
+}
+
+// VerifyReference verifies and then settles the sale. Mellat only moves the
+// money once a sale is settled, and reverses sales left unsettled. The
+// RefId was issued for the requested amount, so the amount is not checked
+// again.
+func (m *MellatAdapter) VerifyReference(ctx context.Context, authority, saleReferenceID string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	_, orderID, err := splitAuthority(authority)
+	if err != nil {
+		return nil, err
+	}
+
+	request := verifyRequest{
+		TerminalID:      m.TerminalID,
+		UserName:        m.Username,
+		UserPassword:    m.Password,
+		OrderID:         orderID,
+		SaleOrderID:     orderID,
+		SaleReferenceID: saleReferenceID,
+	}
+
+	request.XMLName = xml.Name{Local: "int:bpVerifyRequest"}
+	ret, raw, err := m.call(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	code, err := resultCode(ret)
+	if err != nil {
+		return nil, err
+	}
+	if code != codeOK && code != codeAlreadyVerified {
+		return nil, &ports.GatewayError{Gateway: "mellat", Code: code, Raw: raw}
+	}
+	alreadyVerified := code == codeAlreadyVerified
+
+	request.XMLName = xml.Name{Local: "int:bpSettleRequest"}
+	ret, raw, err = m.call(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	code, err = resultCode(ret)
+	if err != nil {
+		return nil, err
+	}
+	if code != codeOK && code != codeAlreadySettled {
+		return nil, &ports.GatewayError{Gateway: "mellat", Code: code, Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:           saleReferenceID,
+		AlreadyVerified: alreadyVerified,
+		Raw:             raw,
+	}, nil
+}
+
+// ParseCallback reads the form the bank posts back: RefId, ResCode,
+// SaleOrderId and, for paid sales, SaleReferenceId
+func (m *MellatAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	refID := params.Get("RefId")
+	orderID, err := strconv.ParseInt(params.Get("SaleOrderId"), 10, 64)
+	if refID == "" || err != nil {
+		return ports.PaymentCallback{}
+	}
+
+	reference := params.Get("SaleReferenceId")
+	return ports.PaymentCallback{
+		Authority: authority(refID, orderID),
+		Paid:      params.Get("ResCode") == "0" && reference != "",
+		Reference: reference,
+	}
+}
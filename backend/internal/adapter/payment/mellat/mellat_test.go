@@ -0,0 +1,172 @@
+package mellat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a server answering each SOAP
+// operation with a fixture from testdata
+func newTestAdapter(t *testing.T, fixtures map[string]string) *MellatAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/services/pgw" {
+			t.Errorf("path = %s, want /services/pgw", r.URL.Path)
+		}
+		if !strings.Contains(string(body), "<terminalId>1234</terminalId><userName>user</userName><userPassword>pass</userPassword>") {
+			t.Errorf("request = %s, want terminal credentials", body)
+		}
+		if strings.Contains(string(body), "bpPayRequest") && !strings.Contains(string(body), "<amount>15000</amount>") {
+			t.Errorf("request = %s, want 15000 Rials", body)
+		}
+		for operation, fixture := range fixtures {
+			if strings.Contains(string(body), "<int:"+operation+">") {
+				if fixture == "" {
+					_, _ = w.Write([]byte("<html>Service Unavailable</html>"))
+					return
+				}
+				data, err := os.ReadFile(filepath.Join("testdata", fixture))
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Header().Set("Content-Type", "text/xml")
+				_, _ = w.Write(data)
+				return
+			}
+		}
+		t.Errorf("unexpected request: %s", body)
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewMellatAdapter(1234, "user", "pass")
+	a.BaseURL = server.URL
+	return a
+}
+
+func TestRequestPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "Success", fixture: "pay_ok.xml"},
+		{name: "Rejected", fixture: "pay_rejected.xml", wantErr: true, wantCode: 21},
+		{name: "Fault", fixture: "fault.xml", wantErr: true},
+		{name: "Malformed", fixture: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, map[string]string{"bpPayRequest": tt.fixture})
+
+			intent := ports.PaymentIntent{Amount: domain.Tomans(1500), CallbackURL: "https://example.com/cb", PayerMobile: "09120000000"}
+			result, err := a.RequestPayment(context.Background(), intent)
+			if tt.wantErr {
+				var gwErr *ports.GatewayError
+				if err == nil || (tt.wantCode != 0 && (!errors.As(err, &gwErr) || gwErr.Code != tt.wantCode)) {
+					t.Errorf("RequestPayment() error = %v, want gateway code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+			if result.PaymentURL != a.BaseURL+"/startpay.mellat" || result.Form["RefId"] != "AF82041a2Bf6989c7fF9" || result.Form["MobileNo"] != "09120000000" {
+				t.Errorf("RequestPayment() = %+v, want a form POST of the RefId", result)
+			}
+			if refID, _, err := splitAuthority(result.Authority); err != nil || refID != "AF82041a2Bf6989c7fF9" {
+				t.Errorf("RequestPayment() authority = %s, want RefId and order ID", result.Authority)
+			}
+		})
+	}
+}
+
+func TestVerifyReference(t *testing.T) {
+	tests := []struct {
+		name                string
+		verify              string
+		settle              string
+		wantAlreadyVerified bool
+		wantGatewayErr      bool
+		wantErr             bool
+	}{
+		{name: "Success", verify: "verify_ok.xml", settle: "settle_ok.xml"},
+		{name: "Already Verified", verify: "verify_already_verified.xml", settle: "settle_already_settled.xml", wantAlreadyVerified: true},
+		{name: "Cancelled", verify: "verify_cancelled.xml", wantGatewayErr: true},
+		{name: "Fault", verify: "fault.xml", wantErr: true},
+		{name: "Malformed", verify: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures := map[string]string{"bpVerifyRequest": tt.verify}
+			if tt.settle != "" {
+				fixtures["bpSettleRequest"] = tt.settle
+			}
+			a := newTestAdapter(t, fixtures)
+
+			result, err := a.VerifyReference(context.Background(), "AF82041a2Bf6989c7fF9:42", "123456789", domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyReference() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyReference() error = %v, want decoding error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyReference() error = %v", err)
+			}
+			if result.RefID != "123456789" || result.AlreadyVerified != tt.wantAlreadyVerified {
+				t.Errorf("VerifyReference() = %+v, want ref 123456789, already verified %v", result, tt.wantAlreadyVerified)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		want   ports.PaymentCallback
+	}{
+		{
+			name:   "Paid",
+			params: url.Values{"RefId": {"AF82041a2Bf6989c7fF9"}, "ResCode": {"0"}, "SaleOrderId": {"42"}, "SaleReferenceId": {"123456789"}},
+			want:   ports.PaymentCallback{Authority: "AF82041a2Bf6989c7fF9:42", Paid: true, Reference: "123456789"},
+		},
+		{
+			name:   "Cancelled",
+			params: url.Values{"RefId": {"AF82041a2Bf6989c7fF9"}, "ResCode": {"17"}, "SaleOrderId": {"42"}},
+			want:   ports.PaymentCallback{Authority: "AF82041a2Bf6989c7fF9:42"},
+		},
+		{name: "Malformed", params: url.Values{"RefId": {"AF82041a2Bf6989c7fF9"}, "SaleOrderId": {"x"}}, want: ports.PaymentCallback{}},
+	}
+
+	a := NewMellatAdapter(1234, "user", "pass")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.ParseCallback(tt.params); got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package nextpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+const NextPayBaseURL = "https://nextpay.org/nx/gateway"
+
+// Result codes reported by NextPay
+const (
+	codeTokenCreated = -1
+	codeVerified     = 0
+	// codeAlreadyVerified is returned when a transaction is verified twice
+	codeAlreadyVerified = -49
+)
+
+type NextPayAdapter struct {
+	APIKey string
+	// BaseURL is the gateway root, e.g. for a proxy or test server
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewNextPayAdapter(apiKey string) *NextPayAdapter {
+	return &NextPayAdapter{
+		APIKey:  apiKey,
+		BaseURL: NextPayBaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports a missing API key
+func (n *NextPayAdapter) CheckConfig() error {
+	if n.APIKey == "" {
+		return errors.New("NEXTPAY_API_KEY is not set")
+	}
+	return nil
+}
+
+// Unit reports that NextPay counts in Tomans. It also accepts Rials, but
+// Tomans are its default and what its panel shows.
+func (n *NextPayAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyToman
+}
+
+// post sends payload to path and decodes the response into out
+func (n *NextPayAdapter) post(ctx context.Context, path string, payload, out interface{}) ([]byte, error) {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", n.BaseURL+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return raw, fmt.Errorf("nextpay returned status %d: %w", resp.StatusCode, err)
+	}
+	return raw, nil
+}
+
+type requestPayload struct {
+	APIKey        string `json:"api_key"`
+	OrderID       string `json:"order_id"`
+	Amount        int64  `json:"amount"`
+	CallbackURI   string `json:"callback_uri"`
+	Currency      string `json:"currency"`
+	CustomerPhone string `json:"customer_phone,omitempty"`
+	PayerDesc     string `json:"payer_desc,omitempty"`
+}
+
+type requestResponse struct {
+	Code    int    `json:"code"`
+	TransID string `json:"trans_id"`
+}
+
+func (n *NextPayAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(n.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	// NextPay requires an order ID
+	orderID := intent.OrderID
+	if orderID == "" {
+		orderID = uuid.NewString()
+	}
+
+	payload := requestPayload{
+		APIKey:        n.APIKey,
+		OrderID:       orderID,
+		Amount:        amount.Amount,
+		CallbackURI:   intent.CallbackURL,
+		Currency:      string(amount.Unit),
+		CustomerPhone: intent.PayerMobile,
+		PayerDesc:     intent.Description,
+	}
+
+	var result requestResponse
+	raw, err := n.post(ctx, "/token", payload, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Code != codeTokenCreated {
+		return nil, &ports.GatewayError{Gateway: "nextpay", Code: result.Code, Raw: raw}
+	}
+	if result.TransID == "" {
+		return nil, fmt.Errorf("nextpay returned no transaction ID: %s", raw)
+	}
+
+	return &ports.PaymentRequestResult{
+		PaymentURL: n.BaseURL + "/payment/" + url.PathEscape(result.TransID),
+		Authority:  result.TransID,
+		Raw:        raw,
+	}, nil
+}
+
+type verifyPayload struct {
+	APIKey   string `json:"api_key"`
+	TransID  string `json:"trans_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type verifyResponse struct {
+	Code          int         `json:"code"`
+	Amount        json.Number `json:"amount"`
+	ShaparakRefID json.Number `json:"Shaparak_Ref_Id"`
+	CardHolder    string      `json:"card_holder"`
+}
+
+func (n *NextPayAdapter) VerifyPayment(ctx context.Context, transID string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	expected, err := amount.In(n.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := verifyPayload{
+		APIKey:   n.APIKey,
+		TransID:  transID,
+		Amount:   expected.Amount,
+		Currency: string(expected.Unit),
+	}
+
+	var result verifyResponse
+	raw, err := n.post(ctx, "/verify", payload, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != codeVerified && result.Code != codeAlreadyVerified {
+		return nil, &ports.GatewayError{Gateway: "nextpay", Code: result.Code, Raw: raw}
+	}
+	if paid, err := result.Amount.Int64(); err == nil && paid != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "nextpay", Code: result.Code, Message: fmt.Sprintf("paid amount %d does not match %d", paid, expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:           result.ShaparakRefID.String(),
+		AlreadyVerified: result.Code == codeAlreadyVerified,
+		CardPan:         result.CardHolder,
+		Raw:             raw,
+	}, nil
+}
+
+// ParseCallback reads the ?trans_id=...&order_id=...&amount=... return
+// redirect. NextPay sends it for paid and cancelled payments alike, so
+// verification decides the outcome.
+func (n *NextPayAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	transID := params.Get("trans_id")
+	return ports.PaymentCallback{Authority: transID, Paid: transID != ""}
+}
@@ -0,0 +1,166 @@
+package nextpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a server answering every request with
+// body
+func newTestAdapter(t *testing.T, body string, check func(r *http.Request, payload map[string]interface{})) *NextPayAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if check != nil {
+			check(r, payload)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewNextPayAdapter("test-key")
+	a.BaseURL = server.URL
+	return a
+}
+
+func TestRequestPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   domain.Money
+		body     string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "Success", amount: domain.Rials(15000), body: `{"code":-1,"trans_id":"f7c07568-c6d1-4bee-87b1-4a9e5ed2e4c1","amount":1500}`},
+		{name: "Rejected", amount: domain.Rials(15000), body: `{"code":-32,"trans_id":""}`, wantErr: true, wantCode: -32},
+		{name: "Malformed", amount: domain.Rials(15000), body: `Service Unavailable`, wantErr: true},
+		{name: "Missing Transaction", amount: domain.Rials(15000), body: `{"code":-1}`, wantErr: true},
+		{name: "Inexact Amount", amount: domain.Rials(15005), body: `{"code":-1,"trans_id":"abc"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/token" {
+					t.Errorf("path = %s, want /token", r.URL.Path)
+				}
+				if payload["api_key"] != "test-key" || payload["amount"] != float64(1500) || payload["currency"] != "IRT" || payload["order_id"] != "order-1" {
+					t.Errorf("payload = %v, want 1500 Tomans for order-1", payload)
+				}
+			})
+
+			intent := ports.PaymentIntent{Amount: tt.amount, CallbackURL: "https://example.com/cb", OrderID: "order-1"}
+			result, err := a.RequestPayment(context.Background(), intent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RequestPayment() error = nil, want error")
+				}
+				var gwErr *ports.GatewayError
+				if tt.wantCode != 0 && (!errors.As(err, &gwErr) || gwErr.Code != tt.wantCode) {
+					t.Errorf("RequestPayment() error = %v, want gateway code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+			wantURL := a.BaseURL + "/payment/f7c07568-c6d1-4bee-87b1-4a9e5ed2e4c1"
+			if result.Authority != "f7c07568-c6d1-4bee-87b1-4a9e5ed2e4c1" || result.PaymentURL != wantURL {
+				t.Errorf("RequestPayment() = %+v, want payment URL %s", result, wantURL)
+			}
+		})
+	}
+}
+
+func TestVerifyPayment(t *testing.T) {
+	tests := []struct {
+		name                string
+		body                string
+		wantRefID           string
+		wantAlreadyVerified bool
+		wantGatewayErr      bool
+		wantErr             bool
+	}{
+		{
+			name:      "Success",
+			body:      `{"code":0,"amount":1500,"order_id":"order-1","card_holder":"6037-99**-****-1234","customer_phone":"09120000000","Shaparak_Ref_Id":"201904121233","custom":{}}`,
+			wantRefID: "201904121233",
+		},
+		{
+			name:                "Already Verified",
+			body:                `{"code":-49,"amount":1500,"card_holder":"6037-99**-****-1234","Shaparak_Ref_Id":201904121233}`,
+			wantRefID:           "201904121233",
+			wantAlreadyVerified: true,
+		},
+		{name: "Cancelled", body: `{"code":-4,"amount":0}`, wantGatewayErr: true},
+		{name: "Amount Mismatch", body: `{"code":0,"amount":100,"Shaparak_Ref_Id":"201904121233"}`, wantGatewayErr: true},
+		{name: "Malformed", body: `{"code":0,"amount":}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/verify" {
+					t.Errorf("path = %s, want /verify", r.URL.Path)
+				}
+				if payload["trans_id"] != "abc" || payload["amount"] != float64(1500) || payload["currency"] != "IRT" {
+					t.Errorf("payload = %v, want 1500 Tomans for abc", payload)
+				}
+			})
+
+			result, err := a.VerifyPayment(context.Background(), "abc", domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want decoding error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyPayment() error = %v", err)
+			}
+			if result.RefID != tt.wantRefID || result.AlreadyVerified != tt.wantAlreadyVerified || result.CardPan == "" {
+				t.Errorf("VerifyPayment() = %+v, want ref %s, already verified %v", result, tt.wantRefID, tt.wantAlreadyVerified)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		want   ports.PaymentCallback
+	}{
+		{
+			name:   "Returned",
+			params: url.Values{"trans_id": {"abc"}, "order_id": {"order-1"}, "amount": {"1500"}},
+			want:   ports.PaymentCallback{Authority: "abc", Paid: true},
+		},
+		{name: "Malformed", params: url.Values{"order_id": {"order-1"}}, want: ports.PaymentCallback{}},
+	}
+
+	a := NewNextPayAdapter("test-key")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.ParseCallback(tt.params); got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
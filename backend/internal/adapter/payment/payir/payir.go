@@ -0,0 +1,190 @@
+package payir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+const PayIRBaseURL = "https://pay.ir/pg"
+
+// errAlreadyVerified is the verify error code Pay.ir documents for a token
+// that was verified before
+const errAlreadyVerified = -15
+
+type PayIRAdapter struct {
+	APIKey string
+	// BaseURL is the gateway root, e.g. for a proxy or test server. Users
+	// are sent to BaseURL/<token> to pay.
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewPayIRAdapter(apiKey string) *PayIRAdapter {
+	return &PayIRAdapter{
+		APIKey:  apiKey,
+		BaseURL: PayIRBaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports a missing API key
+func (p *PayIRAdapter) CheckConfig() error {
+	if p.APIKey == "" {
+		return errors.New("PAYIR_API_KEY is not set")
+	}
+	return nil
+}
+
+// Unit reports that Pay.ir counts in Rials
+func (p *PayIRAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
+// response holds the fields every Pay.ir response shares. Codes and amounts
+// arrive as numbers or strings depending on the endpoint.
+type response struct {
+	Status       int         `json:"status"`
+	ErrorCode    json.Number `json:"errorCode"`
+	ErrorMessage string      `json:"errorMessage"`
+}
+
+func (r response) gatewayError(raw []byte) *ports.GatewayError {
+	code, _ := r.ErrorCode.Int64()
+	return &ports.GatewayError{Gateway: "payir", Code: int(code), Message: r.ErrorMessage, Raw: raw}
+}
+
+// post sends payload to path and decodes the response into out
+func (p *PayIRAdapter) post(ctx context.Context, path string, payload, out interface{}) ([]byte, error) {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", p.BaseURL+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return raw, fmt.Errorf("payir returned status %d: %w", resp.StatusCode, err)
+	}
+	return raw, nil
+}
+
+type requestPayload struct {
+	APIKey       string `json:"api"`
+	Amount       int64  `json:"amount"`
+	Redirect     string `json:"redirect"`
+	Mobile       string `json:"mobile,omitempty"`
+	FactorNumber string `json:"factorNumber,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type requestResponse struct {
+	response
+	Token string `json:"token"`
+}
+
+func (p *PayIRAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(p.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := requestPayload{
+		APIKey:       p.APIKey,
+		Amount:       amount.Amount,
+		Redirect:     intent.CallbackURL,
+		Mobile:       intent.PayerMobile,
+		FactorNumber: intent.OrderID,
+		Description:  intent.Description,
+	}
+
+	var result requestResponse
+	raw, err := p.post(ctx, "/send", payload, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != 1 {
+		return nil, result.gatewayError(raw)
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("payir returned no token: %s", raw)
+	}
+
+	return &ports.PaymentRequestResult{
+		PaymentURL: p.BaseURL + "/" + url.PathEscape(result.Token),
+		Authority:  result.Token,
+		Raw:        raw,
+	}, nil
+}
+
+type verifyPayload struct {
+	APIKey string `json:"api"`
+	Token  string `json:"token"`
+}
+
+type verifyResponse struct {
+	response
+	Amount     json.Number `json:"amount"`
+	TransID    json.Number `json:"transId"`
+	CardNumber string      `json:"cardNumber"`
+}
+
+func (p *PayIRAdapter) VerifyPayment(ctx context.Context, token string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	expected, err := amount.In(p.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	var result verifyResponse
+	raw, err := p.post(ctx, "/verify", verifyPayload{APIKey: p.APIKey, Token: token}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status != 1 {
+		gwErr := result.gatewayError(raw)
+		if gwErr.Code != errAlreadyVerified {
+			return nil, gwErr
+		}
+		// Pay.ir does not repeat the transaction details for a repeated
+		// verification
+		return &ports.PaymentVerifyResult{AlreadyVerified: true, Raw: raw}, nil
+	}
+	// Pay.ir verifies by token alone, so check it settled the expected amount
+	if paid, err := result.Amount.Int64(); err == nil && paid != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "payir", Code: result.Status, Message: fmt.Sprintf("paid amount %d does not match %d", paid, expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:   result.TransID.String(),
+		CardPan: result.CardNumber,
+		Raw:     raw,
+	}, nil
+}
+
+// ParseCallback reads the ?status=1|0&token=... return redirect
+func (p *PayIRAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	return ports.PaymentCallback{
+		Authority: params.Get("token"),
+		Paid:      params.Get("status") == "1",
+	}
+}
@@ -0,0 +1,161 @@
+package payir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a server answering every request with
+// body
+func newTestAdapter(t *testing.T, body string, check func(r *http.Request, payload map[string]interface{})) *PayIRAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if check != nil {
+			check(r, payload)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewPayIRAdapter("test-key")
+	a.BaseURL = server.URL
+	return a
+}
+
+func TestRequestPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantURL  string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "Success", body: `{"status":1,"token":"tok123"}`, wantURL: "/tok123"},
+		{name: "Rejected", body: `{"status":0,"errorCode":-3,"errorMessage":"amount is too low"}`, wantErr: true, wantCode: -3},
+		{name: "Rejected With String Code", body: `{"status":0,"errorCode":"-1","errorMessage":"api key is required"}`, wantErr: true, wantCode: -1},
+		{name: "Malformed", body: `<html>502</html>`, wantErr: true},
+		{name: "Missing Token", body: `{"status":1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/send" {
+					t.Errorf("path = %s, want /send", r.URL.Path)
+				}
+				if payload["api"] != "test-key" || payload["amount"] != float64(15000) || payload["factorNumber"] != "order-1" {
+					t.Errorf("payload = %v, want 15000 Rials for order-1", payload)
+				}
+			})
+
+			intent := ports.PaymentIntent{Amount: domain.Tomans(1500), CallbackURL: "https://example.com/cb", OrderID: "order-1"}
+			result, err := a.RequestPayment(context.Background(), intent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RequestPayment() error = nil, want error")
+				}
+				var gwErr *ports.GatewayError
+				if tt.wantCode != 0 && (!errors.As(err, &gwErr) || gwErr.Code != tt.wantCode) {
+					t.Errorf("RequestPayment() error = %v, want gateway code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+			if result.Authority != "tok123" || result.PaymentURL != a.BaseURL+tt.wantURL {
+				t.Errorf("RequestPayment() = %+v, want token tok123 at %s", result, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestVerifyPayment(t *testing.T) {
+	tests := []struct {
+		name                string
+		body                string
+		wantRefID           string
+		wantAlreadyVerified bool
+		wantGatewayErr      bool
+		wantErr             bool
+	}{
+		{
+			name:      "Success",
+			body:      `{"status":1,"amount":"15000","transId":"1234567","factorNumber":"order-1","cardNumber":"603799******1234"}`,
+			wantRefID: "1234567",
+		},
+		{
+			name:                "Already Verified",
+			body:                `{"status":0,"errorCode":-15,"errorMessage":"transaction was already verified"}`,
+			wantAlreadyVerified: true,
+		},
+		{name: "Cancelled", body: `{"status":0,"errorCode":-13,"errorMessage":"transaction failed or was cancelled"}`, wantGatewayErr: true},
+		{name: "Amount Mismatch", body: `{"status":1,"amount":1000,"transId":1234567}`, wantGatewayErr: true},
+		{name: "Malformed", body: `{"status":"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAdapter(t, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/verify" {
+					t.Errorf("path = %s, want /verify", r.URL.Path)
+				}
+				if payload["api"] != "test-key" || payload["token"] != "tok123" {
+					t.Errorf("payload = %v, want token tok123", payload)
+				}
+			})
+
+			result, err := a.VerifyPayment(context.Background(), "tok123", domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want decoding error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyPayment() error = %v", err)
+			}
+			if result.RefID != tt.wantRefID || result.AlreadyVerified != tt.wantAlreadyVerified {
+				t.Errorf("VerifyPayment() = %+v, want ref %q, already verified %v", result, tt.wantRefID, tt.wantAlreadyVerified)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		want   ports.PaymentCallback
+	}{
+		{name: "Paid", params: url.Values{"status": {"1"}, "token": {"tok123"}}, want: ports.PaymentCallback{Authority: "tok123", Paid: true}},
+		{name: "Cancelled", params: url.Values{"status": {"0"}, "token": {"tok123"}}, want: ports.PaymentCallback{Authority: "tok123"}},
+		{name: "Malformed", params: url.Values{"status": {"1"}}, want: ports.PaymentCallback{Paid: true}},
+	}
+
+	a := NewPayIRAdapter("test-key")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.ParseCallback(tt.params); got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
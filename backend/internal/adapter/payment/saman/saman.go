@@ -0,0 +1,221 @@
+package saman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+const SamanBaseURL = "https://sep.shaparak.ir"
+
+// statusPaid is the callback status of a successful sale
+const statusPaid = "2"
+
+// Saman's SEP bank IPG. A token is requested over JSON, the user is sent to
+// the bank with a form POST, and the sale is verified over SOAP.
+type SamanAdapter struct {
+	TerminalID string
+	// BaseURL is the SEP root holding the token API, the payment page and
+	// the verification service, e.g. for a proxy or test server
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewSamanAdapter(terminalID string) *SamanAdapter {
+	return &SamanAdapter{
+		TerminalID: terminalID,
+		BaseURL:    SamanBaseURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports a missing terminal ID
+func (s *SamanAdapter) CheckConfig() error {
+	if s.TerminalID == "" {
+		return errors.New("SAMAN_TERMINAL_ID is not set")
+	}
+	return nil
+}
+
+// Unit reports that SEP counts in Rials
+func (s *SamanAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
+type tokenPayload struct {
+	Action      string `json:"action"`
+	TerminalID  string `json:"TerminalId"`
+	Amount      int64  `json:"Amount"`
+	ResNum      string `json:"ResNum"`
+	RedirectURL string `json:"RedirectUrl"`
+	CellNumber  string `json:"CellNumber,omitempty"`
+}
+
+type tokenResponse struct {
+	Status    int         `json:"status"`
+	Token     string      `json:"token"`
+	ErrorCode json.Number `json:"errorCode"`
+	ErrorDesc string      `json:"errorDesc"`
+}
+
+// RequestPayment requests a token for a new reservation number, which
+// becomes the authority; SEP posts it back to the callback as ResNum
+func (s *SamanAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(s.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := tokenPayload{
+		Action:      "token",
+		TerminalID:  s.TerminalID,
+		Amount:      amount.Amount,
+		ResNum:      uuid.NewString(),
+		RedirectURL: intent.CallbackURL,
+		CellNumber:  intent.PayerMobile,
+	}
+
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/onlinepg/onlinepg", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result tokenResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("saman returned status %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != 1 {
+		code, _ := result.ErrorCode.Int64()
+		return nil, &ports.GatewayError{Gateway: "saman", Code: int(code), Message: result.ErrorDesc, Raw: raw}
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("saman returned no token: %s", raw)
+	}
+
+	return &ports.PaymentRequestResult{
+		PaymentURL: s.BaseURL + "/OnlinePG/OnlinePG",
+		Form:       map[string]string{"Token": result.Token},
+		Authority:  payload.ResNum,
+		Raw:        raw,
+	}, nil
+}
+
+// VerifyPayment cannot verify without the reference number the bank posts
+// to the callback
+func (s *SamanAdapter) VerifyPayment(ctx context.Context, resNum string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	return nil, &ports.GatewayError{Gateway: "saman", Message: "payments can only be verified from their callback"}
+}
+
+type verifyEnvelope struct {
+	XMLName xml.Name `xml:"soapenv:Envelope"`
+	SoapEnv string   `xml:"xmlns:soapenv,attr"`
+	Urn     string   `xml:"xmlns:urn,attr"`
+	RefNum  string   `xml:"soapenv:Body>urn:verifyTransaction>String_1"`
+	MID     string   `xml:"soapenv:Body>urn:verifyTransaction>String_2"`
+}
+
+type verifyResponseEnvelope struct {
+	Body struct {
+		Fault *struct {
+			Code   string `xml:"faultcode"`
+			String string `xml:"faultstring"`
+		} `xml:"Fault"`
+		Response struct {
+			Result string `xml:"result"`
+		} `xml:"verifyTransactionResponse"`
+	} `xml:"Body"`
+}
+
+// VerifyReference verifies the sale with the RefNum from the callback. SEP
+// answers with the amount it settled, or a negative error code. A repeated
+// verification returns the amount again, so it cannot be told apart; the
+// payments table allows each reference to settle only one payment.
+func (s *SamanAdapter) VerifyReference(ctx context.Context, resNum, refNum string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	expected, err := amount.In(s.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	env := verifyEnvelope{SoapEnv: "http://schemas.xmlsoap.org/soap/envelope/", Urn: "urn:Foo", RefNum: refNum, MID: s.TerminalID}
+	body, err := xml.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/payments/referencepayment.asmx", bytes.NewBuffer(append([]byte(xml.Header), body...)))
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", "urn:Foo#verifyTransaction")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result verifyResponseEnvelope
+	if err := xml.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("saman returned status %d: %w", resp.StatusCode, err)
+	}
+	if result.Body.Fault != nil {
+		return nil, fmt.Errorf("saman SOAP fault %s: %s", result.Body.Fault.Code, result.Body.Fault.String)
+	}
+	settled, err := strconv.ParseFloat(strings.TrimSpace(result.Body.Response.Result), 64)
+	if err != nil {
+		return nil, fmt.Errorf("saman returned a malformed result %q", result.Body.Response.Result)
+	}
+
+	if settled <= 0 {
+		return nil, &ports.GatewayError{Gateway: "saman", Code: int(settled), Raw: raw}
+	}
+	if int64(settled) != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "saman", Message: fmt.Sprintf("paid amount %d does not match %d", int64(settled), expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{RefID: refNum, Raw: raw}, nil
+}
+
+// ParseCallback reads the form the bank posts back: ResNum, Status and, for
+// paid sales, RefNum
+func (s *SamanAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	resNum := params.Get("ResNum")
+	if resNum == "" {
+		return ports.PaymentCallback{}
+	}
+
+	refNum := params.Get("RefNum")
+	return ports.PaymentCallback{
+		Authority: resNum,
+		Paid:      params.Get("Status") == statusPaid && refNum != "",
+		Reference: refNum,
+	}
+}
@@ -0,0 +1,176 @@
+package saman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a server answering every request with
+// body
+func newTestAdapter(t *testing.T, body string, check func(r *http.Request, body []byte)) *SamanAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if check != nil {
+			check(r, payload)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewSamanAdapter("21002")
+	a.BaseURL = server.URL
+	return a
+}
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRequestPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "Success", body: `{"status":1,"token":"2c3c1fefac5a48geb9f9be7e445dd9b2"}`},
+		{name: "Rejected", body: `{"status":-1,"errorCode":"5","errorDesc":"invalid parameters"}`, wantErr: true, wantCode: 5},
+		{name: "Malformed", body: `<html>Service Unavailable</html>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resNum string
+			a := newTestAdapter(t, tt.body, func(r *http.Request, body []byte) {
+				var payload map[string]interface{}
+				_ = json.Unmarshal(body, &payload)
+				if r.URL.Path != "/onlinepg/onlinepg" {
+					t.Errorf("path = %s, want /onlinepg/onlinepg", r.URL.Path)
+				}
+				if payload["action"] != "token" || payload["TerminalId"] != "21002" || payload["Amount"] != float64(15000) {
+					t.Errorf("payload = %v, want a token for 15000 Rials", payload)
+				}
+				resNum, _ = payload["ResNum"].(string)
+			})
+
+			intent := ports.PaymentIntent{Amount: domain.Tomans(1500), CallbackURL: "https://example.com/cb"}
+			result, err := a.RequestPayment(context.Background(), intent)
+			if tt.wantErr {
+				var gwErr *ports.GatewayError
+				if err == nil || (tt.wantCode != 0 && (!errors.As(err, &gwErr) || gwErr.Code != tt.wantCode)) {
+					t.Errorf("RequestPayment() error = %v, want gateway code %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+			if result.PaymentURL != a.BaseURL+"/OnlinePG/OnlinePG" || result.Form["Token"] != "2c3c1fefac5a48geb9f9be7e445dd9b2" {
+				t.Errorf("RequestPayment() = %+v, want a form POST of the token", result)
+			}
+			if resNum == "" || result.Authority != resNum {
+				t.Errorf("RequestPayment() authority = %s, want ResNum %s", result.Authority, resNum)
+			}
+		})
+	}
+}
+
+func TestVerifyReference(t *testing.T) {
+	tests := []struct {
+		name           string
+		fixture        string
+		wantGatewayErr bool
+		wantErr        bool
+	}{
+		{name: "Success", fixture: "verify_ok.xml"},
+		{name: "Reversed", fixture: "verify_reversed.xml", wantGatewayErr: true},
+		{name: "Amount Mismatch", fixture: "verify_mismatch.xml", wantGatewayErr: true},
+		{name: "Fault", fixture: "fault.xml", wantErr: true},
+		{name: "Malformed", fixture: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := "<html>Service Unavailable</html>"
+			if tt.fixture != "" {
+				body = fixture(t, tt.fixture)
+			}
+			a := newTestAdapter(t, body, func(r *http.Request, body []byte) {
+				if r.URL.Path != "/payments/referencepayment.asmx" {
+					t.Errorf("path = %s, want /payments/referencepayment.asmx", r.URL.Path)
+				}
+				if !strings.Contains(string(body), "<urn:verifyTransaction><String_1>GmshtyjwKSu5lKOLquYrzO9BqjUMb/TPUK0qak/iVs</String_1><String_2>21002</String_2></urn:verifyTransaction>") {
+					t.Errorf("request = %s, want RefNum and terminal", body)
+				}
+			})
+
+			result, err := a.VerifyReference(context.Background(), "res-1", "GmshtyjwKSu5lKOLquYrzO9BqjUMb/TPUK0qak/iVs", domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyReference() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyReference() error = %v, want decoding error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyReference() error = %v", err)
+			}
+			if result.RefID != "GmshtyjwKSu5lKOLquYrzO9BqjUMb/TPUK0qak/iVs" {
+				t.Errorf("VerifyReference() = %+v, want the RefNum as reference", result)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+		want   ports.PaymentCallback
+	}{
+		{
+			name:   "Paid",
+			params: url.Values{"State": {"OK"}, "Status": {"2"}, "ResNum": {"res-1"}, "RefNum": {"ref-1"}, "TerminalId": {"21002"}},
+			want:   ports.PaymentCallback{Authority: "res-1", Paid: true, Reference: "ref-1"},
+		},
+		{
+			name:   "Cancelled",
+			params: url.Values{"State": {"CanceledByUser"}, "Status": {"1"}, "ResNum": {"res-1"}},
+			want:   ports.PaymentCallback{Authority: "res-1"},
+		},
+		{name: "Malformed", params: url.Values{"Status": {"2"}, "RefNum": {"ref-1"}}, want: ports.PaymentCallback{}},
+	}
+
+	a := NewSamanAdapter("21002")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.ParseCallback(tt.params); got != tt.want {
+				t.Errorf("ParseCallback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
+)
+
+// OutcomeMetadataKey is the payment metadata key scripting the fake IPG's
+// outcome for that payment, e.g. {"sandbox_outcome": "failure"}
+const OutcomeMetadataKey = "sandbox_outcome"
+
+// SandboxAdapter pays through the fake IPG in Server. It moves no money and
+// must never be configured in production.
+type SandboxAdapter struct {
+	// BaseURL is where the fake IPG is served, e.g. http://localhost:8090
+	// for cmd/fakepay
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewSandboxAdapter(baseURL string) *SandboxAdapter {
+	return &SandboxAdapter{
+		BaseURL: baseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CheckConfig reports a missing fake IPG URL
+func (a *SandboxAdapter) CheckConfig() error {
+	if a.BaseURL == "" {
+		return errors.New("SANDBOX_GATEWAY_URL is not set")
+	}
+	return nil
+}
+
+// Unit reports that the fake IPG counts in Rials
+func (a *SandboxAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
+// post sends payload to path and decodes the response into out
+func (a *SandboxAdapter) post(ctx context.Context, path string, payload interface{}, out *response) ([]byte, error) {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", a.BaseURL+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return raw, fmt.Errorf("sandbox returned status %d: %w", resp.StatusCode, err)
+	}
+	return raw, nil
+}
+
+func (a *SandboxAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(a.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := requestPayload{
+		Amount:      amount.Amount,
+		CallbackURL: intent.CallbackURL,
+		Description: intent.Description,
+		Outcome:     Outcome(intent.Metadata[OutcomeMetadataKey]),
+	}
+
+	var result response
+	raw, err := a.post(ctx, "/payment", payload, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Code != codeOK {
+		return nil, &ports.GatewayError{Gateway: "sandbox", Code: result.Code, Message: result.Message, Raw: raw}
+	}
+
+	return &ports.PaymentRequestResult{
+		PaymentURL: a.BaseURL + "/pay/" + url.PathEscape(result.Authority),
+		Authority:  result.Authority,
+		Raw:        raw,
+	}, nil
+}
+
+func (a *SandboxAdapter) VerifyPayment(ctx context.Context, authority string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	expected, err := amount.In(a.Unit())
+	if err != nil {
+		return nil, err
+	}
+
+	var result response
+	raw, err := a.post(ctx, "/verify", verifyPayload{Authority: authority, Amount: expected.Amount}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != codeOK && result.Code != codeAlreadyVerified {
+		return nil, &ports.GatewayError{Gateway: "sandbox", Code: result.Code, Message: result.Message, Raw: raw}
+	}
+	if result.Amount != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "sandbox", Code: result.Code, Message: fmt.Sprintf("paid amount %d does not match %d", result.Amount, expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:           result.RefID,
+		AlreadyVerified: result.Code == codeAlreadyVerified,
+		CardPan:         result.CardPan,
+		Raw:             raw,
+	}, nil
+}
+
+// ParseCallback reads the ?authority=...&status=OK|NOK return redirect
+func (a *SandboxAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	return ports.PaymentCallback{
+		Authority: params.Get("authority"),
+		Paid:      params.Get("status") == "OK",
+	}
+}
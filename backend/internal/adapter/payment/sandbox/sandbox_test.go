@@ -0,0 +1,158 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestAdapter points an adapter at a fresh fake IPG
+func newTestAdapter(t *testing.T) (*SandboxAdapter, *Server) {
+	t.Helper()
+	server := NewServer()
+	server.Delay = time.Second
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	a := NewSandboxAdapter(ts.URL)
+	a.Client.Timeout = 100 * time.Millisecond
+	return a, server
+}
+
+// completePayment submits the pay page as the user would and returns the
+// callback the fake IPG redirects to
+func completePayment(t *testing.T, paymentURL, action string) ports.PaymentCallback {
+	t.Helper()
+	resp, err := http.Get(paymentURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pay page status = %d, want 200", resp.StatusCode)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err = client.PostForm(paymentURL, url.Values{"action": {action}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), "https://example.com/cb?") {
+		t.Fatalf("redirect = %q, want the callback URL", resp.Header.Get("Location"))
+	}
+	return (&SandboxAdapter{}).ParseCallback(location.Query())
+}
+
+func TestSandboxOutcomes(t *testing.T) {
+	tests := []struct {
+		name                string
+		outcome             Outcome
+		action              string
+		wantPaid            bool
+		wantAlreadyVerified bool
+		wantGatewayErr      bool
+		wantErr             bool
+	}{
+		{name: "Success", outcome: OutcomeSuccess, action: "pay", wantPaid: true},
+		{name: "Cancelled", outcome: OutcomeSuccess, action: "cancel", wantGatewayErr: true},
+		{name: "Failure", outcome: OutcomeFailure, action: "pay", wantPaid: true, wantGatewayErr: true},
+		{name: "Timeout", outcome: OutcomeTimeout, action: "pay", wantPaid: true, wantErr: true},
+		{name: "Double Verify", outcome: OutcomeDoubleVerify, action: "pay", wantPaid: true, wantAlreadyVerified: true},
+		{name: "Amount Mismatch", outcome: OutcomeAmountMismatch, action: "pay", wantPaid: true, wantGatewayErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestAdapter(t)
+			ctx := context.Background()
+
+			intent := ports.PaymentIntent{
+				Amount:      domain.Tomans(1500),
+				CallbackURL: "https://example.com/cb",
+				Metadata:    map[string]string{OutcomeMetadataKey: string(tt.outcome)},
+			}
+			result, err := a.RequestPayment(ctx, intent)
+			if err != nil {
+				t.Fatalf("RequestPayment() error = %v", err)
+			}
+
+			callback := completePayment(t, result.PaymentURL, tt.action)
+			if callback.Authority != result.Authority || callback.Paid != tt.wantPaid {
+				t.Fatalf("callback = %+v, want authority %s, paid %v", callback, result.Authority, tt.wantPaid)
+			}
+
+			verified, err := a.VerifyPayment(ctx, callback.Authority, domain.Rials(15000))
+			var gwErr *ports.GatewayError
+			switch {
+			case tt.wantGatewayErr:
+				if !errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want gateway error", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.As(err, &gwErr) {
+					t.Errorf("VerifyPayment() error = %v, want transport error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyPayment() error = %v", err)
+			}
+			if verified.RefID == "" || verified.AlreadyVerified != tt.wantAlreadyVerified {
+				t.Errorf("VerifyPayment() = %+v, want a reference, already verified %v", verified, tt.wantAlreadyVerified)
+			}
+
+			// Verifying again is always reported as a repeat
+			again, err := a.VerifyPayment(ctx, callback.Authority, domain.Rials(15000))
+			if err != nil || !again.AlreadyVerified || again.RefID != verified.RefID {
+				t.Errorf("second VerifyPayment() = %+v, %v, want already verified", again, err)
+			}
+		})
+	}
+}
+
+func TestSandboxDefaultOutcome(t *testing.T) {
+	a, server := newTestAdapter(t)
+	server.Outcome = OutcomeFailure
+
+	result, err := a.RequestPayment(context.Background(), ports.PaymentIntent{Amount: domain.Rials(15000), CallbackURL: "https://example.com/cb"})
+	if err != nil {
+		t.Fatalf("RequestPayment() error = %v", err)
+	}
+	completePayment(t, result.PaymentURL, "pay")
+
+	var gwErr *ports.GatewayError
+	if _, err := a.VerifyPayment(context.Background(), result.Authority, domain.Rials(15000)); !errors.As(err, &gwErr) || gwErr.Code != codeDeclined {
+		t.Errorf("VerifyPayment() error = %v, want declined", err)
+	}
+}
+
+func TestSandboxRejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		intent ports.PaymentIntent
+	}{
+		{name: "Unknown Outcome", intent: ports.PaymentIntent{Amount: domain.Rials(15000), CallbackURL: "https://example.com/cb", Metadata: map[string]string{OutcomeMetadataKey: "explode"}}},
+		{name: "Missing Callback", intent: ports.PaymentIntent{Amount: domain.Rials(15000)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestAdapter(t)
+			var gwErr *ports.GatewayError
+			if _, err := a.RequestPayment(context.Background(), tt.intent); !errors.As(err, &gwErr) || gwErr.Code != codeInvalidRequest {
+				t.Errorf("RequestPayment() error = %v, want invalid request", err)
+			}
+		})
+	}
+}
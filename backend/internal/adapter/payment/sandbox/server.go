@@ -0,0 +1,272 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outcome is what the fake IPG does with a payment once the user pays
+type Outcome string
+
+const (
+	// OutcomeSuccess verifies the payment
+	OutcomeSuccess Outcome = "success"
+	// OutcomeFailure declines the payment at verification
+	OutcomeFailure Outcome = "failure"
+	// OutcomeTimeout holds verification requests for the server's Delay
+	OutcomeTimeout Outcome = "timeout"
+	// OutcomeDoubleVerify reports the payment as already verified
+	OutcomeDoubleVerify Outcome = "double_verify"
+	// OutcomeAmountMismatch reports half the requested amount as paid
+	OutcomeAmountMismatch Outcome = "amount_mismatch"
+)
+
+// Valid reports whether o is a known outcome
+func (o Outcome) Valid() bool {
+	switch o {
+	case OutcomeSuccess, OutcomeFailure, OutcomeTimeout, OutcomeDoubleVerify, OutcomeAmountMismatch:
+		return true
+	}
+	return false
+}
+
+// Result codes returned by the fake IPG
+const (
+	codeOK              = 100
+	codeAlreadyVerified = 101
+	codeInvalidRequest  = -1
+	codeNotFound        = -11
+	codeAmountMismatch  = -50
+	codeDeclined        = -51
+	codeNotPaid         = -52
+)
+
+type paymentStatus string
+
+const (
+	statusPending   paymentStatus = "pending"
+	statusPaid      paymentStatus = "paid"
+	statusCancelled paymentStatus = "cancelled"
+	statusVerified  paymentStatus = "verified"
+)
+
+type fakePayment struct {
+	Authority   string
+	Amount      int64
+	CallbackURL string
+	Description string
+	Outcome     Outcome
+	Status      paymentStatus
+	RefID       string
+}
+
+// Server is an in-memory IPG for development and tests. It renders a
+// pay/cancel page, redirects back to the callback URL and verifies payments
+// with a scripted outcome. Payments are lost when it stops.
+type Server struct {
+	// Outcome applies to payments requested without one
+	Outcome Outcome
+	// Delay is how long verification hangs under OutcomeTimeout
+	Delay time.Duration
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	mux      *http.ServeMux
+}
+
+func NewServer() *Server {
+	s := &Server{
+		Outcome:  OutcomeSuccess,
+		Delay:    30 * time.Second,
+		payments: make(map[string]*fakePayment),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /payment", s.handleRequest)
+	s.mux.HandleFunc("GET /pay/{authority}", s.handlePage)
+	s.mux.HandleFunc("POST /pay/{authority}", s.handleComplete)
+	s.mux.HandleFunc("POST /verify", s.handleVerify)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type response struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message,omitempty"`
+	Authority string `json:"authority,omitempty"`
+	Amount    int64  `json:"amount,omitempty"`
+	RefID     string `json:"ref_id,omitempty"`
+	CardPan   string `json:"card_pan,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) payment(authority string) (*fakePayment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.payments[authority]
+	return p, ok
+}
+
+type requestPayload struct {
+	Amount      int64   `json:"amount"`
+	CallbackURL string  `json:"callback_url"`
+	Description string  `json:"description"`
+	Outcome     Outcome `json:"outcome"`
+}
+
+func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
+	var req requestPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Code: codeInvalidRequest, Message: "invalid request"})
+		return
+	}
+	if req.Amount <= 0 || req.CallbackURL == "" {
+		writeJSON(w, http.StatusBadRequest, response{Code: codeInvalidRequest, Message: "amount and callback_url are required"})
+		return
+	}
+	if req.Outcome == "" {
+		req.Outcome = s.Outcome
+	}
+	if !req.Outcome.Valid() {
+		writeJSON(w, http.StatusBadRequest, response{Code: codeInvalidRequest, Message: fmt.Sprintf("unknown outcome %q", req.Outcome)})
+		return
+	}
+
+	p := &fakePayment{
+		Authority:   uuid.NewString(),
+		Amount:      req.Amount,
+		CallbackURL: req.CallbackURL,
+		Description: req.Description,
+		Outcome:     req.Outcome,
+		Status:      statusPending,
+	}
+	s.mu.Lock()
+	s.payments[p.Authority] = p
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, response{Code: codeOK, Authority: p.Authority})
+}
+
+var payPage = template.Must(template.New("pay").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sandbox payment</title></head>
+<body>
+<h1>Sandbox payment</h1>
+<p>{{.Amount}} Rials{{if .Description}} for {{.Description}}{{end}}</p>
+<p>Scripted outcome: {{.Outcome}}</p>
+<form method="post">
+<button type="submit" name="action" value="pay">Pay</button>
+<button type="submit" name="action" value="cancel">Cancel</button>
+</form>
+</body>
+</html>
+`))
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.payment(r.PathValue("authority"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = payPage.Execute(w, p)
+}
+
+// handleComplete records the user's choice on the pay page and sends them
+// back to the callback URL with ?authority=...&status=OK|NOK
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	p, ok := s.payments[r.PathValue("authority")]
+	if ok && p.Status == statusPending {
+		if r.FormValue("action") == "pay" {
+			p.Status = statusPaid
+			p.RefID = fmt.Sprint(time.Now().UnixNano())
+			if p.Outcome == OutcomeDoubleVerify {
+				p.Status = statusVerified
+			}
+		} else {
+			p.Status = statusCancelled
+		}
+	}
+	status := "NOK"
+	if ok && p.Status != statusPending && p.Status != statusCancelled {
+		status = "OK"
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	callback, err := url.Parse(p.CallbackURL)
+	if err != nil {
+		http.Error(w, "invalid callback URL", http.StatusBadRequest)
+		return
+	}
+	query := callback.Query()
+	query.Set("authority", p.Authority)
+	query.Set("status", status)
+	callback.RawQuery = query.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+type verifyPayload struct {
+	Authority string `json:"authority"`
+	Amount    int64  `json:"amount"`
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Code: codeInvalidRequest, Message: "invalid request"})
+		return
+	}
+	p, ok := s.payment(req.Authority)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, response{Code: codeNotFound, Message: "payment not found"})
+		return
+	}
+
+	if p.Outcome == OutcomeTimeout {
+		select {
+		case <-time.After(s.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	paid := p.Amount
+	if p.Outcome == OutcomeAmountMismatch {
+		paid /= 2
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case req.Amount != p.Amount:
+		writeJSON(w, http.StatusOK, response{Code: codeAmountMismatch, Message: "amount does not match the payment"})
+	case p.Status == statusPending || p.Status == statusCancelled:
+		writeJSON(w, http.StatusOK, response{Code: codeNotPaid, Message: "payment was not completed"})
+	case p.Outcome == OutcomeFailure:
+		writeJSON(w, http.StatusOK, response{Code: codeDeclined, Message: "payment was declined"})
+	case p.Status == statusVerified:
+		writeJSON(w, http.StatusOK, response{Code: codeAlreadyVerified, Amount: paid, RefID: p.RefID, CardPan: "502229******5995"})
+	default:
+		p.Status = statusVerified
+		writeJSON(w, http.StatusOK, response{Code: codeOK, Amount: paid, RefID: p.RefID, CardPan: "502229******5995"})
+	}
+}
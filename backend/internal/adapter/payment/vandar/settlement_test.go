@@ -0,0 +1,173 @@
+package vandar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// newTestSettlement points an adapter at a server answering every request
+// with status and body
+func newTestSettlement(t *testing.T, status int, body string, check func(r *http.Request, payload map[string]interface{})) *SettlementAdapter {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if check != nil {
+			check(r, payload)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	a := NewSettlementAdapter("acme", "token")
+	a.APIURL = server.URL
+	return a
+}
+
+func testPayout() domain.Payout {
+	return domain.Payout{
+		ID:          "5f0c8a3e-1d2b-4c5d-9e8f-0a1b2c3d4e5f",
+		Amount:      1_500_000,
+		Sheba:       "IR820540102680020817909002",
+		Description: "March earnings",
+		Status:      domain.PayoutProcessing,
+	}
+}
+
+func TestSendPayouts(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus domain.PayoutStatus
+		wantRef    string
+		wantGwErr  bool
+		wantErr    bool
+	}{
+		{
+			name:       "Pending",
+			status:     200,
+			body:       `{"status":1,"data":{"settlement":[{"id":"f7a1","transaction_id":161234,"status":"PENDING"}]},"message":"ok"}`,
+			wantStatus: domain.PayoutProcessing,
+			wantRef:    "f7a1",
+		},
+		{
+			name:       "Numeric ID",
+			status:     200,
+			body:       `{"status":1,"data":{"settlement":[{"id":98765,"status":"INIT"}]}}`,
+			wantStatus: domain.PayoutProcessing,
+			wantRef:    "98765",
+		},
+		{
+			name:       "Done",
+			status:     200,
+			body:       `{"status":1,"data":{"settlement":[{"id":"f7a1","status":"DONE"}]}}`,
+			wantStatus: domain.PayoutPaid,
+			wantRef:    "f7a1",
+		},
+		{name: "Rejected", status: 422, body: `{"status":0,"message":"invalid iban","errors":["iban is invalid"]}`, wantGwErr: true},
+		{name: "Malformed", status: 502, body: `<html>bad gateway</html>`, wantErr: true},
+		{name: "Missing Settlement", status: 200, body: `{"status":1,"data":{}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestSettlement(t, tt.status, tt.body, func(r *http.Request, payload map[string]interface{}) {
+				if r.URL.Path != "/business/acme/settlement/store" || r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("request = %s %s, auth %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+				}
+				if payload["iban"] != "IR820540102680020817909002" || payload["amount"] != float64(1_500_000) ||
+					payload["track_id"] != "5f0c8a3e-1d2b-4c5d-9e8f-0a1b2c3d4e5f" {
+					t.Errorf("payload = %v", payload)
+				}
+			})
+
+			batch, err := a.SendPayouts(context.Background(), "b1", []domain.Payout{testPayout()})
+			if err != nil {
+				t.Fatalf("SendPayouts() error = %v", err)
+			}
+			if len(batch.Results) != 1 {
+				t.Fatalf("SendPayouts() results = %d, want 1", len(batch.Results))
+			}
+
+			result := batch.Results[0]
+			var gwErr *ports.GatewayError
+			if isGwErr := errors.As(result.Err, &gwErr); isGwErr != tt.wantGwErr {
+				t.Fatalf("result error = %v, want gateway error %v", result.Err, tt.wantGwErr)
+			}
+			if tt.wantGwErr {
+				return
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("result error = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if result.Status != tt.wantStatus || result.Reference != tt.wantRef {
+				t.Errorf("result = %v %q, want %v %q", result.Status, result.Reference, tt.wantStatus, tt.wantRef)
+			}
+		})
+	}
+}
+
+func TestPayoutStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus domain.PayoutStatus
+		wantNote   bool
+	}{
+		{name: "Pending", body: `{"status":1,"data":{"settlement":{"id":"f7a1","status":"PENDING"}}}`, wantStatus: domain.PayoutProcessing},
+		{name: "Done", body: `{"status":1,"data":{"settlement":{"id":"f7a1","status":"DONE"}}}`, wantStatus: domain.PayoutPaid},
+		{name: "Failed", body: `{"status":1,"data":{"settlement":{"id":"f7a1","status":"FAILED","description":"account closed"}}}`, wantStatus: domain.PayoutFailed, wantNote: true},
+		{name: "Canceled", body: `{"status":1,"data":{"settlement":{"id":"f7a1","status":"CANCELED"}}}`, wantStatus: domain.PayoutFailed, wantNote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestSettlement(t, 200, tt.body, func(r *http.Request, _ map[string]interface{}) {
+				if r.Method != http.MethodGet || r.URL.Path != "/business/acme/settlement/f7a1" {
+					t.Errorf("request = %s %s", r.Method, r.URL.Path)
+				}
+			})
+
+			payout := testPayout()
+			payout.Reference = "f7a1"
+			result, err := a.PayoutStatus(context.Background(), &payout)
+			if err != nil {
+				t.Fatalf("PayoutStatus() error = %v", err)
+			}
+			if result.Status != tt.wantStatus || (result.Note != "") != tt.wantNote {
+				t.Errorf("PayoutStatus() = %v %q, want %v", result.Status, result.Note, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("No Reference", func(t *testing.T) {
+		a := newTestSettlement(t, 200, `{}`, func(r *http.Request, _ map[string]interface{}) {
+			t.Error("unexpected request")
+		})
+		payout := testPayout()
+		if _, err := a.PayoutStatus(context.Background(), &payout); err == nil {
+			t.Error("PayoutStatus() error = nil, want an error without a settlement ID")
+		}
+	})
+}
+
+func TestSettlementCheckConfig(t *testing.T) {
+	if err := NewSettlementAdapter("", "token").CheckConfig(); err == nil {
+		t.Error("CheckConfig() = nil without a business")
+	}
+	if err := NewSettlementAdapter("acme", "token").CheckConfig(); err != nil {
+		t.Errorf("CheckConfig() = %v", err)
+	}
+}
@@ -4,20 +4,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
 )
 
 const (
 	VandarRequestURL = "https://ipg.vandar.io/api/v3/send"
 	VandarVerifyURL  = "https://ipg.vandar.io/api/v3/verify"
 	VandarStartURL   = "https://ipg.vandar.io/v3/"
+	VandarAPIURL     = "https://api.vandar.io/v3"
 )
 
 type VandarAdapter struct {
 	APIKey string
-	Client *http.Client
+	// Business and AccessToken authorize the business API used for
+	// refunds; refunds are manual when they are not set
+	Business    string
+	AccessToken string
+	Client      *http.Client
 }
 
 func NewVandarAdapter(apiKey string) *VandarAdapter {
@@ -27,12 +40,26 @@ func NewVandarAdapter(apiKey string) *VandarAdapter {
 	}
 }
 
+// CheckConfig reports a missing API key
+func (v *VandarAdapter) CheckConfig() error {
+	if v.APIKey == "" {
+		return errors.New("VANDAR_API_KEY is not set")
+	}
+	return nil
+}
+
+// Unit reports that Vandar's IPG counts in Rials
+func (v *VandarAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
 type requestPayload struct {
-	APIKey      string `json:"api_key"`
-	Amount      int64  `json:"amount"`
-	CallbackURL string `json:"callback_url"`
-	Mobile      string `json:"mobile_number,omitempty"`
-	Description string `json:"description,omitempty"`
+	APIKey       string `json:"api_key"`
+	Amount       int64  `json:"amount"`
+	CallbackURL  string `json:"callback_url"`
+	Mobile       string `json:"mobile_number,omitempty"`
+	FactorNumber string `json:"factorNumber,omitempty"`
+	Description  string `json:"description,omitempty"`
 }
 
 type requestResponse struct {
@@ -41,13 +68,19 @@ type requestResponse struct {
 	Errors []string `json:"errors,omitempty"`
 }
 
-func (v *VandarAdapter) RequestPayment(ctx context.Context, amount int64, callbackURL string, description string, mobile string) (string, string, error) {
+func (v *VandarAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(v.Unit())
+	if err != nil {
+		return nil, err
+	}
+
 	payload := requestPayload{
-		APIKey:      v.APIKey,
-		Amount:      amount, // Vandar uses Rials usually, check docs if Toman
-		CallbackURL: callbackURL,
-		Mobile:      mobile,
-		Description: description,
+		APIKey:       v.APIKey,
+		Amount:       amount.Amount,
+		CallbackURL:  intent.CallbackURL,
+		Mobile:       intent.PayerMobile,
+		FactorNumber: intent.OrderID,
+		Description:  intent.Description,
 	}
 
 	body, _ := json.Marshal(payload)
@@ -56,20 +89,29 @@ func (v *VandarAdapter) RequestPayment(ctx context.Context, amount int64, callba
 
 	resp, err := v.Client.Do(req)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var result requestResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", "", err
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
 	}
 
 	if result.Status != 1 {
-		return "", "", fmt.Errorf("vandar error: %v", result.Errors)
+		return nil, &ports.GatewayError{Gateway: "vandar", Code: result.Status, Message: fmt.Sprint(result.Errors), Raw: raw}
 	}
 
-	return VandarStartURL + result.Token, result.Token, nil
+	return &ports.PaymentRequestResult{
+		PaymentURL: VandarStartURL + result.Token,
+		Authority:  result.Token,
+		Raw:        raw,
+	}, nil
 }
 
 type verifyPayload struct {
@@ -84,7 +126,8 @@ type verifyResponse struct {
 	Errors  []string `json:"errors,omitempty"`
 }
 
-func (v *VandarAdapter) VerifyPayment(ctx context.Context, token string, amount int64) (string, error) {
+func (v *VandarAdapter) VerifyPayment(ctx context.Context, token string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
 	payload := verifyPayload{
 		APIKey: v.APIKey,
 		Token:  token,
@@ -96,18 +139,92 @@ func (v *VandarAdapter) VerifyPayment(ctx context.Context, token string, amount
 
 	resp, err := v.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var result verifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
 	}
 
 	if result.Status != 1 {
-		return "", fmt.Errorf("verification failed: %v", result.Errors)
+		return nil, &ports.GatewayError{Gateway: "vandar", Code: result.Status, Message: fmt.Sprint(result.Errors), Raw: raw}
+	}
+	// Vandar verifies by token alone, so check it settled the expected amount
+	expected, err := amount.In(v.Unit())
+	if err != nil {
+		return nil, err
 	}
+	if paid, err := strconv.ParseInt(result.Amount, 10, 64); err == nil && paid != expected.Amount {
+		return nil, &ports.GatewayError{Gateway: "vandar", Code: result.Status, Message: fmt.Sprintf("paid amount %d does not match %d", paid, expected.Amount), Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID: result.TransId,
+		Raw:   raw,
+	}, nil
+}
+
+type refundResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Errors  []string        `json:"errors,omitempty"`
+}
 
-	return result.TransId, nil
+// Refund returns a verified transaction through Vandar's business API.
+// Vandar refunds whole transactions only.
+func (v *VandarAdapter) Refund(ctx context.Context, payment *domain.Payment, amount domain.Money, reason string) (*ports.RefundResult, error) {
+	if v.Business == "" || v.AccessToken == "" {
+		return nil, fmt.Errorf("%w: vandar business API is not configured", domain.ErrRefundUnsupported)
+	}
+	if (payment.Status != domain.PaymentVerified && payment.Status != domain.PaymentOnHold) || amount.ToRials() != payment.Amount {
+		return nil, fmt.Errorf("%w: vandar only refunds whole transactions", domain.ErrRefundUnsupported)
+	}
+
+	body, _ := json.Marshal(map[string]string{"comment": reason})
+	endpoint := fmt.Sprintf("%s/business/%s/transaction/%s/refund", VandarAPIURL, url.PathEscape(v.Business), url.PathEscape(payment.RefID))
+	req, _ := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+v.AccessToken)
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result refundResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Status != 1 {
+		message := result.Message
+		if len(result.Errors) > 0 {
+			message = fmt.Sprint(result.Errors)
+		}
+		return nil, &ports.GatewayError{Gateway: "vandar", Code: result.Status, Message: message, Raw: raw}
+	}
+
+	return &ports.RefundResult{Reference: payment.RefID, Raw: raw}, nil
+}
+
+// ParseCallback reads the ?token=...&payment_status=OK|FAILED return redirect
+func (v *VandarAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	return ports.PaymentCallback{
+		Authority: params.Get("token"),
+		Paid:      params.Get("payment_status") == "OK",
+	}
 }
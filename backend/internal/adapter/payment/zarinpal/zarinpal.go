@@ -4,15 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
 )
 
 const (
-	ZarinpalRequestURL = "https://api.zarinpal.com/pg/v4/payment/request.json"
-	ZarinpalVerifyURL  = "https://api.zarinpal.com/pg/v4/payment/verify.json"
-	ZarinpalStartURL   = "https://www.zarinpal.com/pg/StartPay/"
+	ZarinpalRequestURL    = "https://api.zarinpal.com/pg/v4/payment/request.json"
+	ZarinpalVerifyURL     = "https://api.zarinpal.com/pg/v4/payment/verify.json"
+	ZarinpalUnverifiedURL = "https://api.zarinpal.com/pg/v4/payment/unVerified.json"
+	ZarinpalReverseURL    = "https://api.zarinpal.com/pg/v4/payment/reverse.json"
+	ZarinpalStartURL      = "https://www.zarinpal.com/pg/StartPay/"
 )
 
 type ZarinpalAdapter struct {
@@ -27,13 +36,30 @@ func NewZarinpalAdapter(merchantID string) *ZarinpalAdapter {
 	}
 }
 
+// CheckConfig reports a missing merchant ID
+func (z *ZarinpalAdapter) CheckConfig() error {
+	if z.MerchantID == "" {
+		return errors.New("ZARINPAL_MERCHANT_ID is not set")
+	}
+	return nil
+}
+
+// Unit reports that Zarinpal is sent amounts in Rials; the currency is also
+// sent explicitly so the merchant's default does not matter
+func (z *ZarinpalAdapter) Unit() domain.CurrencyUnit {
+	return domain.CurrencyRial
+}
+
 type requestPayload struct {
 	MerchantID  string `json:"merchant_id"`
 	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency,omitempty"`
 	CallbackURL string `json:"callback_url"`
 	Description string `json:"description"`
 	Metadata    struct {
-		Email string `json:"email,omitempty"`
+		Mobile  string `json:"mobile,omitempty"`
+		Email   string `json:"email,omitempty"`
+		OrderID string `json:"order_id,omitempty"`
 	} `json:"metadata,omitempty"`
 }
 
@@ -48,14 +74,22 @@ type requestResponse struct {
 	Errors []interface{} `json:"errors"`
 }
 
-func (z *ZarinpalAdapter) RequestPayment(ctx context.Context, amount int64, callbackURL string, description string, email string) (string, string, error) {
+func (z *ZarinpalAdapter) RequestPayment(ctx context.Context, intent ports.PaymentIntent) (*ports.PaymentRequestResult, error) {
+	amount, err := intent.Amount.In(z.Unit())
+	if err != nil {
+		return nil, err
+	}
+
 	payload := requestPayload{
 		MerchantID:  z.MerchantID,
-		Amount:      amount,
-		CallbackURL: callbackURL,
-		Description: description,
+		Amount:      amount.Amount,
+		Currency:    string(amount.Unit),
+		CallbackURL: intent.CallbackURL,
+		Description: intent.Description,
 	}
-	payload.Metadata.Email = email
+	payload.Metadata.Mobile = intent.PayerMobile
+	payload.Metadata.Email = intent.PayerEmail
+	payload.Metadata.OrderID = intent.OrderID
 
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequestWithContext(ctx, "POST", ZarinpalRequestURL, bytes.NewBuffer(body))
@@ -63,20 +97,29 @@ func (z *ZarinpalAdapter) RequestPayment(ctx context.Context, amount int64, call
 
 	resp, err := z.Client.Do(req)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var result requestResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", "", err
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
 	}
 
 	if result.Data.Code != 100 {
-		return "", "", fmt.Errorf("zarinpal error code: %d", result.Data.Code)
+		return nil, &ports.GatewayError{Gateway: "zarinpal", Code: result.Data.Code, Message: result.Data.Message, Raw: raw}
 	}
 
-	return ZarinpalStartURL + result.Data.Authority, result.Data.Authority, nil
+	return &ports.PaymentRequestResult{
+		PaymentURL: ZarinpalStartURL + result.Data.Authority,
+		Authority:  result.Data.Authority,
+		Raw:        raw,
+	}, nil
 }
 
 type verifyPayload struct {
@@ -98,10 +141,16 @@ type verifyResponse struct {
 	Errors []interface{} `json:"errors"`
 }
 
-func (z *ZarinpalAdapter) VerifyPayment(ctx context.Context, authority string, amount int64) (string, error) {
+func (z *ZarinpalAdapter) VerifyPayment(ctx context.Context, authority string, amount domain.Money) (*ports.PaymentVerifyResult, error) {
+	ctx = resilience.Idempotent(ctx)
+	amount, err := amount.In(z.Unit())
+	if err != nil {
+		return nil, err
+	}
+
 	payload := verifyPayload{
 		MerchantID: z.MerchantID,
-		Amount:     amount,
+		Amount:     amount.Amount,
 		Authority:  authority,
 	}
 
@@ -111,19 +160,126 @@ func (z *ZarinpalAdapter) VerifyPayment(ctx context.Context, authority string, a
 
 	resp, err := z.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var result verifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
 	}
 
 	// 100 = Success, 101 = Verified Already
 	if result.Data.Code != 100 && result.Data.Code != 101 {
-		return "", fmt.Errorf("verification failed code: %d", result.Data.Code)
+		return nil, &ports.GatewayError{Gateway: "zarinpal", Code: result.Data.Code, Message: result.Data.Message, Raw: raw}
+	}
+
+	return &ports.PaymentVerifyResult{
+		RefID:           fmt.Sprintf("%d", result.Data.RefID),
+		AlreadyVerified: result.Data.Code == 101,
+		CardPan:         result.Data.CardPan,
+		CardHash:        result.Data.CardHash,
+		Raw:             raw,
+	}, nil
+}
+
+type unverifiedResponse struct {
+	Data struct {
+		Code        int    `json:"code"`
+		Message     string `json:"message"`
+		Authorities []struct {
+			Authority   string `json:"authority"`
+			Amount      int64  `json:"amount"`
+			CallbackURL string `json:"callback_url"`
+			Date        string `json:"date"`
+		} `json:"authorities"`
+	} `json:"data"`
+	Errors []interface{} `json:"errors"`
+}
+
+// ListUnverified returns the authorities of successful payments that were
+// never verified, e.g. because the user closed the tab before the callback
+func (z *ZarinpalAdapter) ListUnverified(ctx context.Context) ([]string, error) {
+	ctx = resilience.Idempotent(ctx)
+	body, _ := json.Marshal(map[string]string{"merchant_id": z.MerchantID})
+	req, _ := http.NewRequestWithContext(ctx, "POST", ZarinpalUnverifiedURL, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := z.Client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return fmt.Sprintf("%d", result.Data.RefID), nil
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result unverifiedResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Data.Code != 100 {
+		return nil, &ports.GatewayError{Gateway: "zarinpal", Code: result.Data.Code, Message: result.Data.Message, Raw: raw}
+	}
+
+	authorities := make([]string, 0, len(result.Data.Authorities))
+	for _, a := range result.Data.Authorities {
+		authorities = append(authorities, a.Authority)
+	}
+	return authorities, nil
+}
+
+// reverseWindow is how long after verification Zarinpal accepts a reversal
+const reverseWindow = 30 * time.Minute
+
+// Refund reverses a recently verified payment. Zarinpal only reverses the
+// full amount shortly after verification; anything else is refunded
+// manually.
+func (z *ZarinpalAdapter) Refund(ctx context.Context, payment *domain.Payment, amount domain.Money, reason string) (*ports.RefundResult, error) {
+	// UpdatedAt is the verification time while the payment is untouched
+	if (payment.Status != domain.PaymentVerified && payment.Status != domain.PaymentOnHold) || amount.ToRials() != payment.Amount || time.Since(payment.UpdatedAt) > reverseWindow {
+		return nil, fmt.Errorf("%w: zarinpal only reverses full payments within %s of verification", domain.ErrRefundUnsupported, reverseWindow)
+	}
+
+	body, _ := json.Marshal(map[string]string{"merchant_id": z.MerchantID, "authority": payment.Authority})
+	req, _ := http.NewRequestWithContext(ctx, "POST", ZarinpalReverseURL, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := z.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result requestResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Data.Code != 100 {
+		return nil, &ports.GatewayError{Gateway: "zarinpal", Code: result.Data.Code, Message: result.Data.Message, Raw: raw}
+	}
+
+	return &ports.RefundResult{Reference: payment.Authority, Raw: raw}, nil
+}
+
+// ParseCallback reads the ?Authority=...&Status=OK|NOK return redirect
+func (z *ZarinpalAdapter) ParseCallback(params url.Values) ports.PaymentCallback {
+	return ports.PaymentCallback{
+		Authority: params.Get("Authority"),
+		Paid:      params.Get("Status") == "OK",
+	}
 }
@@ -0,0 +1,93 @@
+package manual
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"strings"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// utf8BOM makes spreadsheet programs read the Persian names as UTF-8
+const utf8BOM = "\uFEFF"
+
+var header = []string{"row", "sheba", "amount", "owner_name", "bank", "description", "payout_id"}
+
+// ExportAdapter hands payouts to finance as a bank batch file for a group
+// Paya transfer. Nothing is sent; finance uploads the file to the bank and
+// confirms each payout's outcome by hand.
+type ExportAdapter struct {
+	// Source is the Sheba number the transfers are paid from, written in
+	// the file's header row when set
+	Source string
+}
+
+func NewExportAdapter(source string) *ExportAdapter {
+	return &ExportAdapter{Source: domain.NormalizeSheba(source)}
+}
+
+// SendPayouts exports the batch; every payout stays processing
+func (a *ExportAdapter) SendPayouts(ctx context.Context, batchID string, payouts []domain.Payout) (*ports.PayoutBatch, error) {
+	file, name, err := a.ExportPayouts(batchID, payouts)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &ports.PayoutBatch{Results: make([]ports.PayoutResult, len(payouts)), File: file, FileName: name}
+	for i := range batch.Results {
+		batch.Results[i].Status = domain.PayoutProcessing
+	}
+	return batch, nil
+}
+
+// ExportPayouts writes a CSV file with a row per payout: its position,
+// destination Sheba, amount in Rials, account owner, bank, description and
+// payout ID, which finance quotes when confirming the payout
+func (a *ExportAdapter) ExportPayouts(batchID string, payouts []domain.Payout) ([]byte, string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(utf8BOM)
+
+	w := csv.NewWriter(&buf)
+	if a.Source != "" {
+		_ = w.Write(padded("source", a.Source))
+	}
+	_ = w.Write(header)
+
+	var total int64
+	for i, p := range payouts {
+		_ = w.Write([]string{
+			strconv.Itoa(i + 1),
+			p.Sheba,
+			strconv.FormatInt(p.Amount, 10),
+			p.OwnerName,
+			p.Bank().PersianName,
+			oneLine(p.Description),
+			p.ID,
+		})
+		total += p.Amount
+	}
+	_ = w.Write(padded("total", "", strconv.FormatInt(total, 10)))
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "payouts-" + batchID + ".csv", nil
+}
+
+// padded fills a summary row to the header's width, since bank portals
+// expect every row to have the same columns
+func padded(fields ...string) []string {
+	row := make([]string, len(header))
+	copy(row, fields)
+	return row
+}
+
+// oneLine keeps descriptions on one line for bank portals that read the
+// file line by line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,62 @@
+package manual
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+func TestSendPayouts(t *testing.T) {
+	payouts := []domain.Payout{
+		{ID: "p1", Amount: 1_500_000, Sheba: "IR820540102680020817909002", OwnerName: "علی رضایی", Description: "March\nearnings"},
+		{ID: "p2", Amount: 250_000, Sheba: "IR590170000000100326276001", OwnerName: "Sara, Ahmadi"},
+	}
+
+	a := NewExportAdapter("ir64 0560 0000 0000 0123 4567 89")
+	batch, err := a.SendPayouts(context.Background(), "b1", payouts)
+	if err != nil {
+		t.Fatalf("SendPayouts() error = %v", err)
+	}
+	if batch.FileName != "payouts-b1.csv" {
+		t.Errorf("FileName = %q", batch.FileName)
+	}
+	for i, r := range batch.Results {
+		if r.Status != domain.PayoutProcessing || r.Err != nil {
+			t.Errorf("result %d = %v %v, want processing", i, r.Status, r.Err)
+		}
+	}
+
+	if !bytes.HasPrefix(batch.File, []byte(utf8BOM)) {
+		t.Fatal("file does not start with a UTF-8 BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(batch.File, []byte(utf8BOM)))).ReadAll()
+	if err != nil {
+		t.Fatalf("file is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"source", "IR640560000000000123456789", "", "", "", "", ""},
+		{"row", "sheba", "amount", "owner_name", "bank", "description", "payout_id"},
+		{"1", "IR820540102680020817909002", "1500000", "علی رضایی", "بانک پارسیان", "March earnings", "p1"},
+		{"2", "IR590170000000100326276001", "250000", "Sara, Ahmadi", "بانک ملی ایران", "", "p2"},
+		{"total", "", "1750000", "", "", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("file has %d rows, want %d: %v", len(records), len(want), records)
+	}
+	for i := range want {
+		if len(records[i]) != len(want[i]) {
+			t.Errorf("row %d = %v, want %v", i, records[i], want[i])
+			continue
+		}
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("row %d = %v, want %v", i, records[i], want[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/pkg/persian"
+)
+
+// DefaultFontPath is where Alpine's font-dejavu package installs DejaVu
+// Sans, which covers the Arabic presentation forms receipts are drawn with
+const DefaultFontPath = "/usr/share/fonts/dejavu/DejaVuSans.ttf"
+
+const fontFamily = "receipt"
+
+// Issuer is the business printed on receipts
+type Issuer struct {
+	Name         string
+	EconomicCode string
+	Address      string
+	Phone        string
+}
+
+// ReceiptRenderer draws payment receipts as A5 PDFs in Persian with Jalali
+// dates. fpdf neither joins Arabic letters nor lays out right-to-left text,
+// so every line is shaped and reordered before it is drawn.
+type ReceiptRenderer struct {
+	Issuer Issuer
+	font   []byte
+}
+
+func NewReceiptRenderer() (*ReceiptRenderer, error) {
+	fontPath := os.Getenv("RECEIPT_FONT_PATH")
+	if fontPath == "" {
+		fontPath = DefaultFontPath
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load receipt font: %w", err)
+	}
+
+	return &ReceiptRenderer{
+		Issuer: Issuer{
+			Name:         os.Getenv("RECEIPT_ISSUER_NAME"),
+			EconomicCode: os.Getenv("RECEIPT_ISSUER_ECONOMIC_CODE"),
+			Address:      os.Getenv("RECEIPT_ISSUER_ADDRESS"),
+			Phone:        os.Getenv("RECEIPT_ISSUER_PHONE"),
+		},
+		font: font,
+	}, nil
+}
+
+var gatewayNames = map[string]string{
+	"zarinpal":   "زرین‌پال",
+	"vandar":     "وندار",
+	"idpay":      "آیدی‌پی",
+	"payir":      "پی‌دات‌آی‌آر",
+	"nextpay":    "نکست‌پی",
+	"mellat":     "بانک ملت",
+	"saman":      "بانک سامان",
+	"cardtocard": "کارت به کارت",
+}
+
+const (
+	pageMargin  = 12.0
+	labelWidth  = 38.0
+	rowHeight   = 9.0
+	textSize    = 10.0
+	titleSize   = 16.0
+	captionSize = 8.0
+)
+
+func (r *ReceiptRenderer) RenderReceipt(receipt *domain.PaymentReceipt, payer *domain.User) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A5", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, pageMargin)
+	pdf.SetTitle("Receipt "+receipt.Number(), true)
+	pdf.SetCreator(r.Issuer.Name, true)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", r.font)
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pageMargin
+
+	pdf.SetFont(fontFamily, "", titleSize)
+	pdf.CellFormat(contentWidth, 12, persian.Display("رسید پرداخت"), "", 1, "C", false, 0, "")
+	if r.Issuer.Name != "" {
+		pdf.SetFont(fontFamily, "", textSize+2)
+		pdf.CellFormat(contentWidth, 8, persian.Display(r.Issuer.Name), "", 1, "C", false, 0, "")
+	}
+	pdf.SetFont(fontFamily, "", captionSize)
+	if r.Issuer.EconomicCode != "" {
+		pdf.CellFormat(contentWidth, 5, persian.Display("کد اقتصادی: "+persian.Digits(r.Issuer.EconomicCode)), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	payerName := ""
+	if payer != nil {
+		payerName = joinNonEmpty(" - ", persian.Digits(payer.Phone), payer.Email)
+	}
+	gateway := gatewayNames[receipt.Gateway]
+	if gateway == "" {
+		gateway = receipt.Gateway
+	}
+
+	rows := [][2]string{
+		{"شماره رسید", persian.Digits(receipt.Number())},
+		{"تاریخ پرداخت", formatDateTime(receipt)},
+		{"تاریخ صدور", persian.JalaliDate(receipt.IssuedAt).Long()},
+		{"پرداخت‌کننده", payerName},
+		{"شرح", receipt.Description},
+		{"درگاه پرداخت", gateway},
+		{"شماره پیگیری", persian.Digits(receipt.RefID)},
+		{"شماره کارت", receipt.CardPan},
+		{"مبلغ", persian.FormatAmount(receipt.Amount) + " ریال"},
+	}
+
+	pdf.SetFont(fontFamily, "", textSize)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetFillColor(245, 245, 245)
+	fill := true
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		value := fitWidth(pdf, row[1], contentWidth-labelWidth-4)
+		pdf.CellFormat(contentWidth-labelWidth, rowHeight, persian.Display(value), "B", 0, "R", fill, 0, "")
+		pdf.CellFormat(labelWidth, rowHeight, persian.Display(row[0]+":"), "B", 1, "R", fill, 0, "")
+		fill = !fill
+	}
+
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "", captionSize)
+	if contact := joinNonEmpty(" - ", r.Issuer.Address, persian.Digits(r.Issuer.Phone)); contact != "" {
+		pdf.CellFormat(contentWidth, 5, persian.Display(fitWidth(pdf, contact, contentWidth)), "", 1, "C", false, 0, "")
+	}
+	pdf.CellFormat(contentWidth, 5, persian.Display("این رسید به صورت الکترونیکی صادر شده و نیاز به مهر و امضا ندارد."), "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDateTime formats when the receipt was paid as "۵ مهر ۱۴۰۳ ساعت ۱۴:۳۰"
+func formatDateTime(receipt *domain.PaymentReceipt) string {
+	paidAt := receipt.PaidAt.In(persian.Tehran)
+	return persian.JalaliDate(paidAt).Long() + " ساعت " + persian.Digits(paidAt.Format("15:04"))
+}
+
+// fitWidth shortens s with an ellipsis until it fits within width at the
+// current font size. Widths are measured on the shaped text.
+func fitWidth(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(persian.Shape(s)) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimSpace(string(runes)) + "…"
+		if pdf.GetStringWidth(persian.Shape(shortened)) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,72 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/pkg/persian"
+)
+
+// newTestRenderer loads the font from RECEIPT_FONT_PATH or the default
+// path, skipping the test where neither has one
+func newTestRenderer(t *testing.T) *ReceiptRenderer {
+	t.Helper()
+	r, err := NewReceiptRenderer()
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("receipt font not installed; set RECEIPT_FONT_PATH to run")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Issuer = Issuer{Name: "فروشگاه نمونه", EconomicCode: "411111111111", Address: "تهران، خیابان آزادی", Phone: "021-88888888"}
+	return r
+}
+
+func TestRenderReceipt(t *testing.T) {
+	r := newTestRenderer(t)
+
+	receipt := &domain.PaymentReceipt{
+		Series:      "1403",
+		Sequence:    42,
+		Amount:      12_500_000,
+		Description: "اشتراک ماهانه پلن حرفه‌ای",
+		Gateway:     "zarinpal",
+		RefID:       "201458796",
+		CardPan:     "6037-99**-****-1234",
+		PaidAt:      time.Date(2024, 9, 26, 11, 0, 0, 0, time.UTC),
+		IssuedAt:    time.Date(2024, 9, 26, 11, 0, 5, 0, time.UTC),
+	}
+	payer := &domain.User{Phone: "09121234567", Email: "user@example.com"}
+
+	out, err := r.RenderReceipt(receipt, payer)
+	if err != nil {
+		t.Fatalf("RenderReceipt() error = %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Errorf("RenderReceipt() output does not start with a PDF header")
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	r := newTestRenderer(t)
+	pdf := fpdf.New("P", "mm", "A5", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", r.font)
+	pdf.SetFont(fontFamily, "", textSize)
+
+	short := "اشتراک ماهانه"
+	if got := fitWidth(pdf, short, 80); got != short {
+		t.Errorf("fitWidth() = %q, want %q unchanged", got, short)
+	}
+
+	long := strings.Repeat("شرح بسیار طولانی ", 40)
+	got := fitWidth(pdf, long, 80)
+	if !strings.HasSuffix(got, "…") || pdf.GetStringWidth(persian.Shape(got)) > 80 {
+		t.Errorf("fitWidth() = %q, want it shortened to 80mm with an ellipsis", got)
+	}
+}
@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"gopkg.in/yaml.v3"
+)
+
+type rbacFile struct {
+	Permissions []struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Resource    string `yaml:"resource"`
+		Action      string `yaml:"action"`
+	} `yaml:"permissions"`
+	Roles []struct {
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Permissions []string `yaml:"permissions"`
+	} `yaml:"roles"`
+}
+
+// LoadRBACPolicy reads and validates an rbac.yaml policy file
+func LoadRBACPolicy(path string) (*domain.RBACPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRBACPolicy(data)
+}
+
+// ParseRBACPolicy decodes and validates an RBAC policy document
+func ParseRBACPolicy(data []byte) (*domain.RBACPolicy, error) {
+	var file rbacFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rbac policy: %w", err)
+	}
+
+	policy := &domain.RBACPolicy{}
+	for _, p := range file.Permissions {
+		policy.Permissions = append(policy.Permissions, domain.PolicyPermission{
+			Name:        p.Name,
+			Description: p.Description,
+			Resource:    p.Resource,
+			Action:      p.Action,
+		})
+	}
+	for _, r := range file.Roles {
+		policy.Roles = append(policy.Roles, domain.PolicyRole{
+			Name:        r.Name,
+			Description: r.Description,
+			Permissions: r.Permissions,
+		})
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
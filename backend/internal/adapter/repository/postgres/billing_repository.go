@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const (
+	planColumns         = `id, code, name, price, period_days, grace_days, active, created_at, updated_at`
+	subscriptionColumns = `s.id, s.user_id, s.plan_id, COALESCE(s.pending_plan_id::text, ''), s.status, s.current_period_start, s.current_period_end, s.cancel_at_period_end, s.created_at, s.updated_at`
+	invoiceColumns      = `id, subscription_id, user_id, plan_id, kind, amount, status, period_start, period_end, due_at, COALESCE(payment_id::text, ''), reminded_at, paid_at, created_at, updated_at`
+)
+
+// currentSubscriptionStatuses are the statuses covered by the one current
+// subscription per user index
+var currentSubscriptionStatuses = []string{
+	string(domain.SubscriptionPending), string(domain.SubscriptionActive), string(domain.SubscriptionPastDue),
+}
+
+type BillingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBillingRepository(db *pgxpool.Pool) ports.BillingRepository {
+	return &BillingRepository{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func scanPlan(row pgx.Row) (*domain.Plan, error) {
+	var p domain.Plan
+	err := row.Scan(&p.ID, &p.Code, &p.Name, &p.Price, &p.PeriodDays, &p.GraceDays, &p.Active, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *BillingRepository) CreatePlan(ctx context.Context, plan *domain.Plan) error {
+	query := `
+		INSERT INTO plans (code, name, price, period_days, grace_days, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query,
+		plan.Code, plan.Name, plan.Price, plan.PeriodDays, plan.GraceDays, plan.Active, plan.CreatedAt, plan.UpdatedAt,
+	).Scan(&plan.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrPlanCodeTaken
+	}
+	return err
+}
+
+func (r *BillingRepository) UpdatePlan(ctx context.Context, plan *domain.Plan) error {
+	query := `
+		UPDATE plans
+		SET name = $1, price = $2, period_days = $3, grace_days = $4, active = $5, updated_at = $6
+		WHERE id = $7`
+
+	tag, err := r.db.Exec(ctx, query, plan.Name, plan.Price, plan.PeriodDays, plan.GraceDays, plan.Active, plan.UpdatedAt, plan.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPlanNotFound
+	}
+	return nil
+}
+
+func (r *BillingRepository) GetPlan(ctx context.Context, id string) (*domain.Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM plans WHERE id = $1`
+	return scanPlan(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *BillingRepository) GetPlanByCode(ctx context.Context, code string) (*domain.Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM plans WHERE code = $1`
+	return scanPlan(r.db.QueryRow(ctx, query, code))
+}
+
+func (r *BillingRepository) ListPlans(ctx context.Context, activeOnly bool) ([]domain.Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM plans WHERE active OR NOT $1 ORDER BY price, code`
+
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []domain.Plan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+
+	return plans, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
+	var (
+		s          domain.Subscription
+		start, end *time.Time
+	)
+	err := row.Scan(&s.ID, &s.UserID, &s.PlanID, &s.PendingPlanID, &s.Status, &start, &end, &s.CancelAtPeriodEnd, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if start != nil {
+		s.CurrentPeriodStart = *start
+	}
+	if end != nil {
+		s.CurrentPeriodEnd = *end
+	}
+	return &s, nil
+}
+
+func (r *BillingRepository) listSubscriptions(ctx context.Context, query string, args ...interface{}) ([]domain.Subscription, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (r *BillingRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	query := `
+		INSERT INTO subscriptions (user_id, plan_id, pending_plan_id, status, current_period_start, current_period_end, cancel_at_period_end, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query,
+		sub.UserID, sub.PlanID, sub.PendingPlanID, string(sub.Status), nullTime(sub.CurrentPeriodStart), nullTime(sub.CurrentPeriodEnd),
+		sub.CancelAtPeriodEnd, sub.CreatedAt, sub.UpdatedAt,
+	).Scan(&sub.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadySubscribed
+	}
+	return err
+}
+
+func (r *BillingRepository) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.id = $1`
+	return scanSubscription(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *BillingRepository) GetCurrentSubscription(ctx context.Context, userID string) (*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.user_id = $1 AND s.status = ANY($2)`
+	return scanSubscription(r.db.QueryRow(ctx, query, userID, currentSubscriptionStatuses))
+}
+
+func (r *BillingRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	return updateSubscription(ctx, r.db, sub)
+}
+
+// dbExecutor is satisfied by both the pool and transactions
+type dbExecutor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func updateSubscription(ctx context.Context, db dbExecutor, sub *domain.Subscription) error {
+	query := `
+		UPDATE subscriptions
+		SET plan_id = $1, pending_plan_id = NULLIF($2, '')::uuid, status = $3, current_period_start = $4, current_period_end = $5,
+			cancel_at_period_end = $6, updated_at = $7
+		WHERE id = $8`
+
+	tag, err := db.Exec(ctx, query,
+		sub.PlanID, sub.PendingPlanID, string(sub.Status), nullTime(sub.CurrentPeriodStart), nullTime(sub.CurrentPeriodEnd),
+		sub.CancelAtPeriodEnd, sub.UpdatedAt, sub.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadySubscribed
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *BillingRepository) ListRenewable(ctx context.Context, before time.Time, limit int) ([]domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.status IN ('active', 'past_due') AND NOT s.cancel_at_period_end AND s.current_period_end <= $1
+			AND NOT EXISTS (
+				SELECT 1 FROM invoices i
+				WHERE i.subscription_id = s.id AND i.kind = 'renewal' AND i.period_start = s.current_period_end AND i.status <> 'void'
+			)
+		ORDER BY s.current_period_end
+		LIMIT $2`
+	return r.listSubscriptions(ctx, query, before, limit)
+}
+
+func (r *BillingRepository) ListLapsed(ctx context.Context, now time.Time, limit int) ([]domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		INNER JOIN plans p ON s.plan_id = p.id
+		WHERE (s.status = 'active' AND s.current_period_end <= $1)
+			OR (s.status = 'past_due' AND s.current_period_end + make_interval(days => p.grace_days) <= $1)
+		ORDER BY s.current_period_end
+		LIMIT $2`
+	return r.listSubscriptions(ctx, query, now, limit)
+}
+
+func scanInvoice(row pgx.Row) (*domain.Invoice, error) {
+	var inv domain.Invoice
+	err := row.Scan(&inv.ID, &inv.SubscriptionID, &inv.UserID, &inv.PlanID, &inv.Kind, &inv.Amount, &inv.Status,
+		&inv.PeriodStart, &inv.PeriodEnd, &inv.DueAt, &inv.PaymentID, &inv.RemindedAt, &inv.PaidAt, &inv.CreatedAt, &inv.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *BillingRepository) listInvoices(ctx context.Context, query string, args ...interface{}) ([]domain.Invoice, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []domain.Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *inv)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (r *BillingRepository) CreateInvoice(ctx context.Context, inv *domain.Invoice) error {
+	query := `
+		INSERT INTO invoices (subscription_id, user_id, plan_id, kind, amount, status, period_start, period_end, due_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query,
+		inv.SubscriptionID, inv.UserID, inv.PlanID, string(inv.Kind), inv.Amount, string(inv.Status),
+		inv.PeriodStart, inv.PeriodEnd, inv.DueAt, inv.CreatedAt, inv.UpdatedAt,
+	).Scan(&inv.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrInvoiceExists
+	}
+	return err
+}
+
+func (r *BillingRepository) GetInvoice(ctx context.Context, id string) (*domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1`
+	return scanInvoice(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *BillingRepository) ListInvoices(ctx context.Context, userID string, limit, offset int) ([]domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	return r.listInvoices(ctx, query, userID, limit, offset)
+}
+
+func (r *BillingRepository) ListUnreminded(ctx context.Context, remindedBefore time.Time, limit int) ([]domain.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices
+		WHERE status = 'open' AND (reminded_at IS NULL OR reminded_at <= $1)
+		ORDER BY due_at
+		LIMIT $2`
+	return r.listInvoices(ctx, query, remindedBefore, limit)
+}
+
+func (r *BillingRepository) MarkReminded(ctx context.Context, invoiceID string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE invoices SET reminded_at = $1 WHERE id = $2`, at, invoiceID)
+	return err
+}
+
+func (r *BillingRepository) VoidOpenInvoices(ctx context.Context, subscriptionID string, kind domain.InvoiceKind) error {
+	query := `
+		UPDATE invoices SET status = 'void', updated_at = CURRENT_TIMESTAMP
+		WHERE subscription_id = $1 AND status = 'open' AND ($2 = '' OR kind = $2)`
+
+	_, err := r.db.Exec(ctx, query, subscriptionID, string(kind))
+	return err
+}
+
+func (r *BillingRepository) PayInvoice(ctx context.Context, inv *domain.Invoice, sub *domain.Subscription) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE invoices
+		SET status = $1, payment_id = $2, paid_at = $3, updated_at = $4
+		WHERE id = $5 AND status = 'open'`
+
+	tag, err := tx.Exec(ctx, query, string(inv.Status), inv.PaymentID, inv.PaidAt, inv.UpdatedAt, inv.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvoiceNotPayable
+	}
+
+	if err := updateSubscription(ctx, tx, sub); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
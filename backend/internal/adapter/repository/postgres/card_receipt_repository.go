@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const cardReceiptColumns = `id, payment_id, user_id, amount, tracking_number, image_key, paid_at, COALESCE(description, ''), status, flags, COALESCE(reviewer_id::text, ''), COALESCE(review_reason, ''), reviewed_at, created_at, updated_at`
+
+type CardReceiptRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCardReceiptRepository(db *pgxpool.Pool) ports.CardReceiptRepository {
+	return &CardReceiptRepository{db: db}
+}
+
+func scanCardReceipt(row pgx.Row) (*domain.CardReceipt, error) {
+	var r domain.CardReceipt
+	err := row.Scan(&r.ID, &r.PaymentID, &r.UserID, &r.Amount, &r.TrackingNumber, &r.ImageKey, &r.PaidAt, &r.Description,
+		&r.Status, &r.Flags, &r.ReviewerID, &r.ReviewReason, &r.ReviewedAt, &r.CreatedAt, &r.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *CardReceiptRepository) listReceipts(ctx context.Context, query string, args ...interface{}) ([]domain.CardReceipt, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []domain.CardReceipt
+	for rows.Next() {
+		receipt, err := scanCardReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, *receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+func (r *CardReceiptRepository) Create(ctx context.Context, receipt *domain.CardReceipt) error {
+	flags := receipt.Flags
+	if flags == nil {
+		flags = []string{}
+	}
+
+	query := `
+		INSERT INTO card_receipts (payment_id, user_id, amount, tracking_number, image_key, paid_at, description, status, flags, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11)
+		RETURNING id`
+
+	return r.db.QueryRow(ctx, query,
+		receipt.PaymentID, receipt.UserID, receipt.Amount, receipt.TrackingNumber, receipt.ImageKey, receipt.PaidAt,
+		receipt.Description, string(receipt.Status), flags, receipt.CreatedAt, receipt.UpdatedAt,
+	).Scan(&receipt.ID)
+}
+
+func (r *CardReceiptRepository) GetByID(ctx context.Context, id string) (*domain.CardReceipt, error) {
+	query := `SELECT ` + cardReceiptColumns + ` FROM card_receipts WHERE id = $1`
+	return scanCardReceipt(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *CardReceiptRepository) List(ctx context.Context, filter domain.CardReceiptFilter) ([]domain.CardReceipt, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Status != "" {
+		addCondition("status = $%d", string(filter.Status))
+	}
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.Flagged != nil {
+		addCondition("(cardinality(flags) > 0) = $%d", *filter.Flagged)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+
+	query := `SELECT ` + cardReceiptColumns + ` FROM card_receipts`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	return r.listReceipts(ctx, query, args...)
+}
+
+func (r *CardReceiptRepository) FindPossibleDuplicates(ctx context.Context, trackingNumber, imageKey string, amount int64, paidAt time.Time, window time.Duration) ([]domain.CardReceipt, error) {
+	query := `
+		SELECT ` + cardReceiptColumns + `
+		FROM card_receipts
+		WHERE tracking_number = $1
+		   OR image_key = $2
+		   OR (amount = $3 AND paid_at BETWEEN $4 AND $5)
+		ORDER BY created_at
+		LIMIT 20`
+
+	return r.listReceipts(ctx, query, trackingNumber, imageKey, amount, paidAt.Add(-window), paidAt.Add(window))
+}
+
+func (r *CardReceiptRepository) SaveReview(ctx context.Context, receipt *domain.CardReceipt) error {
+	query := `
+		UPDATE card_receipts
+		SET status = $1, reviewer_id = NULLIF($2, '')::uuid, review_reason = NULLIF($3, ''), reviewed_at = $4, updated_at = $5
+		WHERE id = $6 AND status = $7`
+
+	tag, err := r.db.Exec(ctx, query,
+		string(receipt.Status), receipt.ReviewerID, receipt.ReviewReason, receipt.ReviewedAt, receipt.UpdatedAt,
+		receipt.ID, string(domain.ReceiptPending),
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReceiptAlreadyReviewed
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const foreignKeyViolation = "23503"
+
+const couponColumns = `id, code, discount_type, value, max_discount, max_redemptions, per_user_limit, redemptions, valid_from, valid_until, plan_ids::text[], active, COALESCE(created_by::text, ''), created_at, updated_at`
+
+const redemptionColumns = `id, coupon_id, user_id, payment_id, discount, status, created_at, updated_at`
+
+type CouponRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCouponRepository(db *pgxpool.Pool) ports.CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+func scanCoupon(row pgx.Row) (*domain.Coupon, error) {
+	var (
+		c           domain.Coupon
+		from, until *time.Time
+	)
+	err := row.Scan(&c.ID, &c.Code, &c.Type, &c.Value, &c.MaxDiscount, &c.MaxRedemptions, &c.PerUserLimit, &c.Redemptions,
+		&from, &until, &c.PlanIDs, &c.Active, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCouponNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from != nil {
+		c.ValidFrom = *from
+	}
+	if until != nil {
+		c.ValidUntil = *until
+	}
+	return &c, nil
+}
+
+// planIDs prepares a coupon's plans for a NOT NULL array column
+func planIDs(coupon *domain.Coupon) []string {
+	if coupon.PlanIDs == nil {
+		return []string{}
+	}
+	return coupon.PlanIDs
+}
+
+func (r *CouponRepository) Create(ctx context.Context, coupon *domain.Coupon) error {
+	query := `
+		INSERT INTO coupons (code, discount_type, value, max_discount, max_redemptions, per_user_limit, valid_from, valid_until, plan_ids, active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::text[]::uuid[], $10, NULLIF($11, '')::uuid, $12, $13)
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query,
+		coupon.Code, string(coupon.Type), coupon.Value, coupon.MaxDiscount, coupon.MaxRedemptions, coupon.PerUserLimit,
+		nullTime(coupon.ValidFrom), nullTime(coupon.ValidUntil), planIDs(coupon), coupon.Active, coupon.CreatedBy, coupon.CreatedAt, coupon.UpdatedAt,
+	).Scan(&coupon.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrCouponCodeTaken
+	}
+	return err
+}
+
+func (r *CouponRepository) Update(ctx context.Context, coupon *domain.Coupon) error {
+	query := `
+		UPDATE coupons
+		SET code = $1, discount_type = $2, value = $3, max_discount = $4, max_redemptions = $5, per_user_limit = $6,
+			valid_from = $7, valid_until = $8, plan_ids = $9::text[]::uuid[], active = $10, updated_at = $11
+		WHERE id = $12`
+
+	tag, err := r.db.Exec(ctx, query,
+		coupon.Code, string(coupon.Type), coupon.Value, coupon.MaxDiscount, coupon.MaxRedemptions, coupon.PerUserLimit,
+		nullTime(coupon.ValidFrom), nullTime(coupon.ValidUntil), planIDs(coupon), coupon.Active, coupon.UpdatedAt, coupon.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrCouponCodeTaken
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCouponNotFound
+	}
+	return nil
+}
+
+func (r *CouponRepository) GetByID(ctx context.Context, id string) (*domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE id = $1`
+	return scanCoupon(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE code = $1`
+	return scanCoupon(r.db.QueryRow(ctx, query, code))
+}
+
+func (r *CouponRepository) List(ctx context.Context, limit, offset int) ([]domain.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var coupons []domain.Coupon
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, *coupon)
+	}
+
+	return coupons, rows.Err()
+}
+
+func (r *CouponRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return domain.ErrCouponInUse
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCouponNotFound
+	}
+	return nil
+}
+
+func (r *CouponRepository) Redeem(ctx context.Context, redemption *domain.CouponRedemption) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Raising the count locks the coupon row until commit, which also
+	// serializes the per-user check below
+	query := `
+		UPDATE coupons
+		SET redemptions = redemptions + 1
+		WHERE id = $1 AND active AND (max_redemptions = 0 OR redemptions < max_redemptions)
+		RETURNING per_user_limit`
+
+	var perUserLimit int
+	err = tx.QueryRow(ctx, query, redemption.CouponID).Scan(&perUserLimit)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrCouponExhausted
+	}
+	if err != nil {
+		return err
+	}
+
+	if perUserLimit > 0 {
+		query = `SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = $1 AND user_id = $2 AND status <> $3`
+
+		var used int
+		if err := tx.QueryRow(ctx, query, redemption.CouponID, redemption.UserID, string(domain.RedemptionReleased)).Scan(&used); err != nil {
+			return err
+		}
+		if used >= perUserLimit {
+			return domain.ErrCouponUserLimit
+		}
+	}
+
+	query = `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, payment_id, discount, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err = tx.QueryRow(ctx, query,
+		redemption.CouponID, redemption.UserID, redemption.PaymentID, redemption.Discount, string(redemption.Status),
+		redemption.CreatedAt, redemption.UpdatedAt,
+	).Scan(&redemption.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *CouponRepository) SettleRedemption(ctx context.Context, paymentID string, status domain.RedemptionStatus) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE coupon_redemptions
+		SET status = $1, updated_at = $2
+		WHERE payment_id = $3 AND status = $4
+		RETURNING coupon_id`
+
+	var couponID string
+	err = tx.QueryRow(ctx, query, string(status), time.Now(), paymentID, string(domain.RedemptionReserved)).Scan(&couponID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == domain.RedemptionReleased {
+		if _, err := tx.Exec(ctx, `UPDATE coupons SET redemptions = redemptions - 1 WHERE id = $1`, couponID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *CouponRepository) ListRedemptions(ctx context.Context, couponID string, limit, offset int) ([]domain.CouponRedemption, error) {
+	query := `SELECT ` + redemptionColumns + ` FROM coupon_redemptions WHERE coupon_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, couponID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []domain.CouponRedemption
+	for rows.Next() {
+		var rd domain.CouponRedemption
+		if err := rows.Scan(&rd.ID, &rd.CouponID, &rd.UserID, &rd.PaymentID, &rd.Discount, &rd.Status, &rd.CreatedAt, &rd.UpdatedAt); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, rd)
+	}
+
+	return redemptions, rows.Err()
+}
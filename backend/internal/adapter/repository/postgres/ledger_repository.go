@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const uniqueViolation = "23505"
+
+type LedgerRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLedgerRepository(db *pgxpool.Pool) ports.LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+func (r *LedgerRepository) GetOrCreateAccount(ctx context.Context, accountType domain.AccountType, ownerID string) (*domain.LedgerAccount, error) {
+	// The no-op update makes RETURNING yield existing rows too
+	query := `
+		INSERT INTO ledger_accounts (type, owner_id)
+		VALUES ($1, $2)
+		ON CONFLICT (type, owner_id) DO UPDATE SET type = EXCLUDED.type
+		RETURNING id, type, owner_id, balance, version, created_at`
+
+	var a domain.LedgerAccount
+	err := r.db.QueryRow(ctx, query, string(accountType), ownerID).Scan(&a.ID, &a.Type, &a.OwnerID, &a.Balance, &a.Version, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *LedgerRepository) Post(ctx context.Context, entry *domain.JournalEntry, accounts []*domain.LedgerAccount) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO journal_entries (kind, reference, description, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id`
+
+	err = tx.QueryRow(ctx, query, string(entry.Kind), entry.Reference, entry.Description, entry.CreatedAt).Scan(&entry.ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrDuplicateEntry
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, p := range entry.Postings {
+		_, err := tx.Exec(ctx, `INSERT INTO journal_postings (entry_id, account_id, debit, credit) VALUES ($1, $2, $3, $4)`,
+			entry.ID, p.AccountID, p.Debit, p.Credit)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range accounts {
+		tag, err := tx.Exec(ctx, `UPDATE ledger_accounts SET balance = $1, version = version + 1 WHERE id = $2 AND version = $3`,
+			a.Balance, a.ID, a.Version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrLedgerConflict
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		a.Version++
+	}
+	return nil
+}
+
+func (r *LedgerRepository) GetEntry(ctx context.Context, kind domain.JournalKind, reference string) (*domain.JournalEntry, error) {
+	query := `
+		SELECT id, kind, reference, COALESCE(description, ''), created_at
+		FROM journal_entries
+		WHERE kind = $1 AND reference = $2`
+
+	var e domain.JournalEntry
+	err := r.db.QueryRow(ctx, query, string(kind), reference).Scan(&e.ID, &e.Kind, &e.Reference, &e.Description, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT account_id, debit, credit FROM journal_postings WHERE entry_id = $1`, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p domain.Posting
+		if err := rows.Scan(&p.AccountID, &p.Debit, &p.Credit); err != nil {
+			return nil, err
+		}
+		e.Postings = append(e.Postings, p)
+	}
+	return &e, rows.Err()
+}
+
+func (r *LedgerRepository) ListStatements(ctx context.Context, accountID string, limit, offset int) ([]domain.LedgerStatement, error) {
+	query := `
+		SELECT e.id, e.kind, e.reference, COALESCE(e.description, ''), p.debit, p.credit, e.created_at
+		FROM journal_postings p
+		INNER JOIN journal_entries e ON p.entry_id = e.id
+		WHERE p.account_id = $1
+		ORDER BY e.created_at DESC, e.id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []domain.LedgerStatement
+	for rows.Next() {
+		var s domain.LedgerStatement
+		if err := rows.Scan(&s.EntryID, &s.Kind, &s.Reference, &s.Description, &s.Debit, &s.Credit, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+
+	return statements, rows.Err()
+}
+
+func (r *LedgerRepository) CheckConsistency(ctx context.Context) ([]domain.LedgerMismatch, error) {
+	var mismatches []domain.LedgerMismatch
+
+	entryRows, err := r.db.Query(ctx, `
+		SELECT entry_id, SUM(debit), SUM(credit)
+		FROM journal_postings
+		GROUP BY entry_id
+		HAVING SUM(debit) <> SUM(credit)`)
+	if err != nil {
+		return nil, err
+	}
+	for entryRows.Next() {
+		var m domain.LedgerMismatch
+		if err := entryRows.Scan(&m.EntryID, &m.Expected, &m.Actual); err != nil {
+			entryRows.Close()
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+	entryRows.Close()
+	if err := entryRows.Err(); err != nil {
+		return nil, err
+	}
+
+	accountRows, err := r.db.Query(ctx, `
+		SELECT a.id, a.type, a.balance, COALESCE(SUM(p.debit - p.credit), 0)
+		FROM ledger_accounts a
+		LEFT JOIN journal_postings p ON p.account_id = a.id
+		GROUP BY a.id, a.type, a.balance`)
+	if err != nil {
+		return nil, err
+	}
+	defer accountRows.Close()
+
+	for accountRows.Next() {
+		var (
+			id          string
+			accountType domain.AccountType
+			balance     int64
+			net         int64
+		)
+		if err := accountRows.Scan(&id, &accountType, &balance, &net); err != nil {
+			return nil, err
+		}
+		if accountType.CreditNormal() {
+			net = -net
+		}
+		if net != balance {
+			mismatches = append(mismatches, domain.LedgerMismatch{AccountID: id, Expected: net, Actual: balance})
+		}
+	}
+
+	return mismatches, accountRows.Err()
+}
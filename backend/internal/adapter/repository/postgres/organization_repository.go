@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+type OrganizationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOrganizationRepository(db *pgxpool.Pool) ports.OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Organization operations
+
+// Create inserts the organization and makes its creator the first member
+func (r *OrganizationRepository) Create(ctx context.Context, org *domain.Organization) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO organizations (name, slug, created_by, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	if err := tx.QueryRow(ctx, query, org.Name, org.Slug, org.CreatedBy, org.CreatedAt, org.UpdatedAt).Scan(&org.ID); err != nil {
+		return err
+	}
+
+	memberQuery := `INSERT INTO memberships (organization_id, user_id, created_at) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(ctx, memberQuery, org.ID, org.CreatedBy, org.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *OrganizationRepository) GetByID(ctx context.Context, id string) (*domain.Organization, error) {
+	query := `SELECT id, name, slug, COALESCE(created_by::text, ''), created_at, updated_at FROM organizations WHERE id = $1`
+
+	var org domain.Organization
+	err := r.db.QueryRow(ctx, query, id).Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) GetBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
+	query := `SELECT id, name, slug, COALESCE(created_by::text, ''), created_at, updated_at FROM organizations WHERE slug = $1`
+
+	var org domain.Organization
+	err := r.db.QueryRow(ctx, query, slug).Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) ListForUser(ctx context.Context, userID string) ([]domain.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.slug, COALESCE(o.created_by::text, ''), o.created_at, o.updated_at
+		FROM organizations o
+		INNER JOIN memberships m ON o.id = m.organization_id
+		WHERE m.user_id = $1
+		ORDER BY o.name`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []domain.Organization
+	for rows.Next() {
+		var org domain.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// Membership operations
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, organizationID, userID string) error {
+	query := `INSERT INTO memberships (organization_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	_, err := r.db.Exec(ctx, query, organizationID, userID)
+	return err
+}
+
+// RemoveMember deletes the membership and every role granted in the
+// organization. The organization row is locked while its owners are counted
+// so concurrent removals cannot take away the last owner.
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM organizations WHERE id = $1 FOR UPDATE`, organizationID); err != nil {
+		return err
+	}
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE ur.user_id = $2)
+		FROM user_roles ur
+		INNER JOIN roles ro ON ur.role_id = ro.id
+		WHERE ro.name = $3 AND ur.scope = $1 AND (ur.expires_at IS NULL OR ur.expires_at > NOW())`
+
+	var owners, targetOwner int
+	if err := tx.QueryRow(ctx, query, organizationID, userID, domain.RoleOrgOwner).Scan(&owners, &targetOwner); err != nil {
+		return err
+	}
+	if targetOwner > 0 && owners == 1 {
+		return domain.ErrLastOrgOwner
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM memberships WHERE organization_id = $1 AND user_id = $2`, organizationID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_roles WHERE scope = $1 AND user_id = $2`, organizationID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *OrganizationRepository) IsMember(ctx context.Context, organizationID, userID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM memberships WHERE organization_id = $1 AND user_id = $2)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, organizationID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context) ([]domain.Membership, error) {
+	cond, args, err := tenantFilter(ctx, "m.organization_id", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT m.organization_id, m.user_id, u.email, COALESCE(u.phone, ''), m.created_at
+		FROM memberships m
+		INNER JOIN users u ON m.user_id = u.id
+		WHERE ` + cond + `
+		ORDER BY m.created_at`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []domain.Membership
+	for rows.Next() {
+		var m domain.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Email, &m.Phone, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// Invitation operations
+
+func (r *OrganizationRepository) CreateInvitation(ctx context.Context, inv *domain.Invitation) error {
+	query := `
+		INSERT INTO invitations (organization_id, email, phone, role_id, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, NULLIF($6, '')::uuid, $7, $8)
+		RETURNING id`
+
+	return r.db.QueryRow(ctx, query, inv.OrganizationID, inv.Email, inv.Phone, inv.RoleID, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt, inv.CreatedAt).Scan(&inv.ID)
+}
+
+func (r *OrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	query := `
+		SELECT id, organization_id, COALESCE(email, ''), COALESCE(phone, ''), role_id, token_hash,
+			COALESCE(invited_by::text, ''), expires_at, accepted_at, created_at
+		FROM invitations WHERE token_hash = $1`
+
+	var inv domain.Invitation
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Phone, &inv.RoleID, &inv.TokenHash,
+		&inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *OrganizationRepository) MarkInvitationAccepted(ctx context.Context, id string) error {
+	query := `UPDATE invitations SET accepted_at = NOW() WHERE id = $1 AND accepted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvitationAccepted
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const paymentReceiptColumns = `id, payment_id, user_id, series, sequence, amount, COALESCE(description, ''), gateway, COALESCE(ref_id, ''), COALESCE(card_pan, ''), paid_at, COALESCE(file_key, ''), issued_at`
+
+type PaymentReceiptRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentReceiptRepository(db *pgxpool.Pool) ports.PaymentReceiptRepository {
+	return &PaymentReceiptRepository{db: db}
+}
+
+func (r *PaymentReceiptRepository) Create(ctx context.Context, receipt *domain.PaymentReceipt) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The series row stays locked until commit, so concurrent receipts take
+	// consecutive numbers and a rolled back insert returns its number
+	query := `
+		INSERT INTO receipt_sequences (series, last_value)
+		VALUES ($1, 1)
+		ON CONFLICT (series) DO UPDATE SET last_value = receipt_sequences.last_value + 1
+		RETURNING last_value`
+
+	if err := tx.QueryRow(ctx, query, receipt.Series).Scan(&receipt.Sequence); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO payment_receipts (payment_id, user_id, series, sequence, amount, description, gateway, ref_id, card_pan, paid_at, issued_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11)
+		RETURNING id`
+
+	err = tx.QueryRow(ctx, query,
+		receipt.PaymentID, receipt.UserID, receipt.Series, receipt.Sequence, receipt.Amount, receipt.Description,
+		receipt.Gateway, receipt.RefID, receipt.CardPan, receipt.PaidAt, receipt.IssuedAt,
+	).Scan(&receipt.ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		receipt.Sequence = 0
+		return domain.ErrPaymentReceiptExists
+	}
+	if err != nil {
+		receipt.Sequence = 0
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		receipt.Sequence = 0
+		return err
+	}
+	return nil
+}
+
+func (r *PaymentReceiptRepository) GetByPaymentID(ctx context.Context, paymentID string) (*domain.PaymentReceipt, error) {
+	query := `SELECT ` + paymentReceiptColumns + ` FROM payment_receipts WHERE payment_id = $1`
+
+	var rc domain.PaymentReceipt
+	err := r.db.QueryRow(ctx, query, paymentID).Scan(
+		&rc.ID, &rc.PaymentID, &rc.UserID, &rc.Series, &rc.Sequence, &rc.Amount, &rc.Description,
+		&rc.Gateway, &rc.RefID, &rc.CardPan, &rc.PaidAt, &rc.FileKey, &rc.IssuedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPaymentReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+func (r *PaymentReceiptRepository) SetFile(ctx context.Context, id, fileKey string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE payment_receipts SET file_key = $1 WHERE id = $2`, fileKey, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPaymentReceiptNotFound
+	}
+	return nil
+}
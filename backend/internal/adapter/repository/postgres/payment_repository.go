@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const paymentColumns = `id, user_id, COALESCE(organization_id::text, ''), gateway, amount, COALESCE(description, ''), COALESCE(order_id, ''), metadata, COALESCE(authority, ''), COALESCE(ref_id, ''), COALESCE(card_pan, ''), COALESCE(card_hash, ''), COALESCE(client_ip, ''), COALESCE(risk_action, ''), risk_reasons, COALESCE(coupon_code, ''), discount, refunded_amount, status, created_at, updated_at`
+
+type PaymentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) ports.PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func scanPayment(row pgx.Row) (*domain.Payment, error) {
+	var p domain.Payment
+	err := row.Scan(&p.ID, &p.UserID, &p.OrganizationID, &p.Gateway, &p.Amount, &p.Description, &p.OrderID, &p.Metadata, &p.Authority, &p.RefID, &p.CardPan, &p.CardHash, &p.ClientIP, &p.Risk.Action, &p.Risk.Reasons, &p.CouponCode, &p.Discount, &p.RefundedAmount, &p.Status, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// rawJSON prepares a gateway response for a JSONB column, wrapping anything
+// that is not valid JSON in a JSON string
+func rawJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	if json.Valid(raw) {
+		return string(raw)
+	}
+	quoted, _ := json.Marshal(string(raw))
+	return string(quoted)
+}
+
+// metadataJSON prepares payment metadata for a JSONB column
+func metadataJSON(metadata map[string]string) interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(metadata)
+	return string(data)
+}
+
+// riskReasons prepares a risk decision's reasons for a NOT NULL array column
+func riskReasons(decision domain.RiskDecision) []string {
+	if decision.Reasons == nil {
+		return []string{}
+	}
+	return decision.Reasons
+}
+
+func insertPaymentEvent(ctx context.Context, tx pgx.Tx, event *domain.PaymentEvent) error {
+	query := `
+		INSERT INTO payment_events (payment_id, from_status, to_status, raw_response, note, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), $6)
+		RETURNING id`
+
+	return tx.QueryRow(ctx, query, event.PaymentID, string(event.FromStatus), string(event.ToStatus), rawJSON(event.RawResponse), event.Note, event.CreatedAt).Scan(&event.ID)
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment, event *domain.PaymentEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO payments (user_id, organization_id, gateway, amount, description, order_id, metadata, authority, ref_id, client_ip, risk_action, risk_reasons, coupon_code, discount, status, created_at, updated_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), $12, NULLIF($13, ''), $14, $15, $16, $17)
+		RETURNING id`
+
+	err = tx.QueryRow(ctx, query,
+		payment.UserID, payment.OrganizationID, payment.Gateway, payment.Amount, payment.Description, payment.OrderID, metadataJSON(payment.Metadata), payment.Authority, payment.RefID,
+		payment.ClientIP, string(payment.Risk.Action), riskReasons(payment.Risk), payment.CouponCode, payment.Discount, string(payment.Status), payment.CreatedAt, payment.UpdatedAt,
+	).Scan(&payment.ID)
+	if err != nil {
+		return err
+	}
+
+	event.PaymentID = payment.ID
+	if err := insertPaymentEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+	return scanPayment(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *PaymentRepository) GetByAuthority(ctx context.Context, gateway, authority string) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE gateway = $1 AND authority = $2`
+	return scanPayment(r.db.QueryRow(ctx, query, gateway, authority))
+}
+
+func (r *PaymentRepository) Save(ctx context.Context, payment *domain.Payment, from domain.PaymentStatus, event *domain.PaymentEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE payments
+		SET authority = NULLIF($1, ''), ref_id = NULLIF($2, ''), card_pan = NULLIF($3, ''), card_hash = NULLIF($4, ''),
+			risk_action = NULLIF($5, ''), risk_reasons = $6, refunded_amount = $7, status = $8, updated_at = $9
+		WHERE id = $10 AND status = $11`
+
+	tag, err := tx.Exec(ctx, query, payment.Authority, payment.RefID, payment.CardPan, payment.CardHash,
+		string(payment.Risk.Action), riskReasons(payment.Risk), payment.RefundedAmount, string(payment.Status), payment.UpdatedAt, payment.ID, string(from))
+	if isUniqueViolation(err) {
+		return domain.ErrPaymentReferenceUsed
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPaymentConcurrentUpdate
+	}
+
+	event.PaymentID = payment.ID
+	if err := insertPaymentEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PaymentRepository) ListEvents(ctx context.Context, paymentID string) ([]domain.PaymentEvent, error) {
+	query := `
+		SELECT id, payment_id, COALESCE(from_status, ''), to_status, COALESCE(raw_response::text, ''), COALESCE(note, ''), created_at
+		FROM payment_events
+		WHERE payment_id = $1
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.PaymentEvent
+	for rows.Next() {
+		var e domain.PaymentEvent
+		var raw string
+		if err := rows.Scan(&e.ID, &e.PaymentID, &e.FromStatus, &e.ToStatus, &raw, &e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if raw != "" {
+			e.RawResponse = []byte(raw)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *PaymentRepository) ListStale(ctx context.Context, statuses []domain.PaymentStatus, updatedBefore time.Time, limit int) ([]*domain.Payment, error) {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status = ANY($1) AND updated_at < $2
+		ORDER BY reconciled_at NULLS FIRST, updated_at
+		LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, names, updatedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*domain.Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *PaymentRepository) MarkReconciled(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE payments SET reconciled_at = $1 WHERE id = $2`, at, id)
+	return err
+}
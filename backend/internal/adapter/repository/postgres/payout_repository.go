@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const payoutColumns = `id, user_id, amount, sheba, bank_code, owner_name, COALESCE(description, ''), status, COALESCE(gateway, ''), COALESCE(batch_id::text, ''), COALESCE(reference, ''), COALESCE(approved_by::text, ''), COALESCE(processed_by::text, ''), COALESCE(note, ''), paid_at, created_at, updated_at`
+
+type PayoutRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPayoutRepository(db *pgxpool.Pool) ports.PayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+func scanPayout(row pgx.Row) (*domain.Payout, error) {
+	var (
+		p      domain.Payout
+		paidAt *time.Time
+	)
+	err := row.Scan(&p.ID, &p.UserID, &p.Amount, &p.Sheba, &p.BankCode, &p.OwnerName, &p.Description, &p.Status, &p.Gateway, &p.BatchID,
+		&p.Reference, &p.ApprovedBy, &p.ProcessedBy, &p.Note, &paidAt, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPayoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.PaidAt = paidAt
+	return &p, nil
+}
+
+func (r *PayoutRepository) listPayouts(ctx context.Context, query string, args ...interface{}) ([]domain.Payout, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []domain.Payout
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, *payout)
+	}
+
+	return payouts, rows.Err()
+}
+
+func (r *PayoutRepository) Create(ctx context.Context, payout *domain.Payout) error {
+	query := `
+		INSERT INTO payouts (id, user_id, amount, sheba, bank_code, owner_name, description, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)`
+
+	_, err := r.db.Exec(ctx, query,
+		payout.ID, payout.UserID, payout.Amount, payout.Sheba, payout.BankCode, payout.OwnerName, payout.Description,
+		string(payout.Status), payout.CreatedAt, payout.UpdatedAt)
+	return err
+}
+
+func (r *PayoutRepository) GetByID(ctx context.Context, id string) (*domain.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE id = $1`
+	return scanPayout(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *PayoutRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	return r.listPayouts(ctx, query, userID, limit, offset)
+}
+
+func (r *PayoutRepository) ListByStatus(ctx context.Context, status domain.PayoutStatus, limit, offset int) ([]domain.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+	return r.listPayouts(ctx, query, string(status), limit, offset)
+}
+
+func (r *PayoutRepository) ListByBatch(ctx context.Context, batchID string) ([]domain.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE batch_id = $1 ORDER BY created_at, id`
+	return r.listPayouts(ctx, query, batchID)
+}
+
+func (r *PayoutRepository) Update(ctx context.Context, payout *domain.Payout, from domain.PayoutStatus) error {
+	query := `
+		UPDATE payouts
+		SET status = $1, gateway = NULLIF($2, ''), batch_id = NULLIF($3, '')::uuid, reference = NULLIF($4, ''),
+			approved_by = NULLIF($5, '')::uuid, processed_by = NULLIF($6, '')::uuid, note = NULLIF($7, ''), paid_at = $8, updated_at = $9
+		WHERE id = $10 AND status = $11`
+
+	tag, err := r.db.Exec(ctx, query,
+		string(payout.Status), payout.Gateway, payout.BatchID, payout.Reference, payout.ApprovedBy, payout.ProcessedBy, payout.Note,
+		payout.PaidAt, payout.UpdatedAt, payout.ID, string(from))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPayoutConflict
+	}
+	return nil
+}
+
+func (r *PayoutRepository) ListUnsettled(ctx context.Context, limit int) ([]domain.Payout, error) {
+	// Every stored payout has its hold; the closing entry is a settlement
+	// for paid payouts and a reversal for the rest
+	query := `
+		SELECT ` + payoutColumns + `
+		FROM payouts p
+		WHERE p.status IN ($1, $2, $3, $4)
+		AND EXISTS (SELECT 1 FROM journal_entries e WHERE e.kind = $5 AND e.reference = p.id::text)
+		AND NOT EXISTS (
+			SELECT 1 FROM journal_entries e
+			WHERE e.reference = p.id::text
+			AND e.kind = CASE WHEN p.status = $1 THEN $6 ELSE $7 END
+		)
+		ORDER BY p.updated_at
+		LIMIT $8`
+
+	return r.listPayouts(ctx, query,
+		string(domain.PayoutPaid), string(domain.PayoutFailed), string(domain.PayoutRejected), string(domain.PayoutCancelled),
+		string(domain.JournalPayout), string(domain.JournalPayoutSettlement), string(domain.JournalPayoutReversal), limit)
+}
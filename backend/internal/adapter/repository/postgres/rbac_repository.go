@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/youruser/yourproject/internal/core/domain"
 	"github.com/youruser/yourproject/internal/core/ports"
 )
 
+// activeGrant restricts user_roles (aliased ur) to grants that have not expired
+const activeGrant = `(ur.expires_at IS NULL OR ur.expires_at > NOW())`
+
 type RBACRepository struct {
 	db *pgxpool.Pool
 }
@@ -158,6 +163,13 @@ func (r *RBACRepository) CreatePermission(ctx context.Context, permission *domai
 	return r.db.QueryRow(ctx, query, permission.Name, permission.Description, permission.Resource, permission.Action, permission.CreatedAt).Scan(&permission.ID)
 }
 
+func (r *RBACRepository) UpdatePermission(ctx context.Context, permission *domain.Permission) error {
+	query := `UPDATE permissions SET description = $1, resource = $2, action = $3 WHERE id = $4`
+
+	_, err := r.db.Exec(ctx, query, permission.Description, permission.Resource, permission.Action, permission.ID)
+	return err
+}
+
 func (r *RBACRepository) DeletePermission(ctx context.Context, id string) error {
 	query := `DELETE FROM permissions WHERE id = $1`
 
@@ -228,7 +240,7 @@ func (r *RBACRepository) GetUserRoles(ctx context.Context, userID string) ([]dom
 		SELECT r.id, r.name, r.description, r.created_at, r.updated_at
 		FROM roles r
 		INNER JOIN user_roles ur ON r.id = ur.role_id
-		WHERE ur.user_id = $1
+		WHERE ur.user_id = $1 AND ur.scope = '' AND ` + activeGrant + `
 		ORDER BY r.name`
 
 	rows, err := r.db.Query(ctx, query, userID)
@@ -255,7 +267,7 @@ func (r *RBACRepository) GetUserPermissions(ctx context.Context, userID string)
 		FROM permissions p
 		INNER JOIN role_permissions rp ON p.id = rp.permission_id
 		INNER JOIN user_roles ur ON rp.role_id = ur.role_id
-		WHERE ur.user_id = $1
+		WHERE ur.user_id = $1 AND ur.scope = '' AND ` + activeGrant + `
 		ORDER BY p.resource, p.action`
 
 	rows, err := r.db.Query(ctx, query, userID)
@@ -283,7 +295,7 @@ func (r *RBACRepository) UserHasPermission(ctx context.Context, userID, permissi
 			FROM permissions p
 			INNER JOIN role_permissions rp ON p.id = rp.permission_id
 			INNER JOIN user_roles ur ON rp.role_id = ur.role_id
-			WHERE ur.user_id = $1 AND p.name = $2
+			WHERE ur.user_id = $1 AND p.name = $2 AND ur.scope = '' AND ` + activeGrant + `
 		)`
 
 	var exists bool
@@ -297,10 +309,133 @@ func (r *RBACRepository) UserHasRole(ctx context.Context, userID, roleName strin
 			SELECT 1
 			FROM user_roles ur
 			INNER JOIN roles r ON ur.role_id = r.id
-			WHERE ur.user_id = $1 AND r.name = $2
+			WHERE ur.user_id = $1 AND r.name = $2 AND ur.scope = '' AND ` + activeGrant + `
 		)`
 
 	var exists bool
 	err := r.db.QueryRow(ctx, query, userID, roleName).Scan(&exists)
 	return exists, err
 }
+
+// Role grants
+
+func (r *RBACRepository) GrantRole(ctx context.Context, grant *domain.UserRole) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id, scope, expires_at, granted_by, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6)
+		ON CONFLICT (user_id, role_id, scope)
+		DO UPDATE SET expires_at = EXCLUDED.expires_at, granted_by = EXCLUDED.granted_by, created_at = EXCLUDED.created_at`
+
+	_, err := r.db.Exec(ctx, query, grant.UserID, grant.RoleID, grant.Scope, grant.ExpiresAt, grant.GrantedBy, grant.CreatedAt)
+	return err
+}
+
+func (r *RBACRepository) RevokeRoleGrant(ctx context.Context, userID, roleID, scope string) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2 AND scope = $3`
+
+	_, err := r.db.Exec(ctx, query, userID, roleID, scope)
+	return err
+}
+
+func (r *RBACRepository) ListRoleGrants(ctx context.Context, filter domain.RoleGrantFilter) ([]domain.UserRole, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if filter.UserID != "" {
+		addCondition("ur.user_id", filter.UserID)
+	}
+	if filter.RoleID != "" {
+		addCondition("ur.role_id", filter.RoleID)
+	}
+	if filter.GrantedBy != "" {
+		addCondition("ur.granted_by", filter.GrantedBy)
+	}
+	if filter.Scope != nil {
+		addCondition("ur.scope", *filter.Scope)
+	}
+	if !filter.IncludeExpired {
+		conditions = append(conditions, activeGrant)
+	}
+
+	query := `
+		SELECT ur.user_id, ur.role_id, r.name, ur.scope, COALESCE(ur.granted_by::text, ''), ur.expires_at, ur.created_at
+		FROM user_roles ur
+		INNER JOIN roles r ON ur.role_id = r.id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY ur.created_at DESC"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []domain.UserRole
+	for rows.Next() {
+		var grant domain.UserRole
+		if err := rows.Scan(&grant.UserID, &grant.RoleID, &grant.RoleName, &grant.Scope, &grant.GrantedBy, &grant.ExpiresAt, &grant.CreatedAt); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+func (r *RBACRepository) UserHasPermissionInScope(ctx context.Context, userID, permissionName, scope string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM permissions p
+			INNER JOIN role_permissions rp ON p.id = rp.permission_id
+			INNER JOIN user_roles ur ON rp.role_id = ur.role_id
+			WHERE ur.user_id = $1 AND p.name = $2 AND ur.scope = $3 AND ` + activeGrant + `
+		)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, userID, permissionName, scope).Scan(&exists)
+	return exists, err
+}
+
+func (r *RBACRepository) UserHasRoleInScope(ctx context.Context, userID, roleName, scope string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_roles ur
+			INNER JOIN roles r ON ur.role_id = r.id
+			WHERE ur.user_id = $1 AND r.name = $2 AND ur.scope = $3 AND ` + activeGrant + `
+		)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, userID, roleName, scope).Scan(&exists)
+	return exists, err
+}
+
+func (r *RBACRepository) DeleteExpiredRoleGrants(ctx context.Context) ([]string, error) {
+	query := `DELETE FROM user_roles WHERE expires_at IS NOT NULL AND expires_at <= NOW() RETURNING user_id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const refundColumns = `id, payment_id, amount, COALESCE(reason, ''), method, status, COALESCE(reference, ''), COALESCE(requested_by::text, ''), COALESCE(processed_by::text, ''), COALESCE(note, ''), created_at, updated_at`
+
+type RefundRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefundRepository(db *pgxpool.Pool) ports.RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func scanRefund(row pgx.Row) (*domain.Refund, error) {
+	var r domain.Refund
+	err := row.Scan(&r.ID, &r.PaymentID, &r.Amount, &r.Reason, &r.Method, &r.Status, &r.Reference, &r.RequestedBy, &r.ProcessedBy, &r.Note, &r.CreatedAt, &r.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRefundNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *RefundRepository) listRefunds(ctx context.Context, query string, args ...interface{}) ([]domain.Refund, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []domain.Refund
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	query := `
+		INSERT INTO refunds (payment_id, amount, reason, method, status, reference, requested_by, note, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, NULLIF($8, ''), $9, $10)
+		RETURNING id`
+
+	return r.db.QueryRow(ctx, query,
+		refund.PaymentID, refund.Amount, refund.Reason, refund.Method, string(refund.Status), refund.Reference,
+		refund.RequestedBy, refund.Note, refund.CreatedAt, refund.UpdatedAt,
+	).Scan(&refund.ID)
+}
+
+func (r *RefundRepository) GetByID(ctx context.Context, id string) (*domain.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE id = $1`
+	return scanRefund(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *RefundRepository) ListByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE payment_id = $1 ORDER BY created_at`
+	return r.listRefunds(ctx, query, paymentID)
+}
+
+func (r *RefundRepository) ListByStatus(ctx context.Context, status domain.RefundStatus, limit, offset int) ([]domain.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+	return r.listRefunds(ctx, query, string(status), limit, offset)
+}
+
+func (r *RefundRepository) Update(ctx context.Context, refund *domain.Refund, from domain.RefundStatus) error {
+	query := `
+		UPDATE refunds
+		SET method = $1, status = $2, reference = NULLIF($3, ''), processed_by = NULLIF($4, '')::uuid, note = NULLIF($5, ''), updated_at = $6
+		WHERE id = $7 AND status = $8`
+
+	tag, err := r.db.Exec(ctx, query, refund.Method, string(refund.Status), refund.Reference, refund.ProcessedBy, refund.Note, refund.UpdatedAt,
+		refund.ID, string(from))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRefundConflict
+	}
+	return nil
+}
+
+func (r *RefundRepository) OpenAmount(ctx context.Context, paymentID string) (int64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id = $1 AND status = ANY($2)`
+
+	var amount int64
+	open := []string{string(domain.RefundRequested), string(domain.RefundPendingManual)}
+	err := r.db.QueryRow(ctx, query, paymentID, open).Scan(&amount)
+	return amount, err
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+type RiskRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRiskRepository(db *pgxpool.Pool) ports.RiskRepository {
+	return &RiskRepository{db: db}
+}
+
+func (r *RiskRepository) Activity(ctx context.Context, userID, clientIP string, velocitySince, daySince time.Time) (*domain.RiskActivity, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE user_id = $1 AND created_at >= $3),
+			COUNT(*) FILTER (WHERE $2 <> '' AND client_ip = $2 AND created_at >= $3),
+			COALESCE(SUM(amount) FILTER (WHERE user_id = $1 AND created_at >= $4 AND status NOT IN ('failed', 'cancelled', 'expired')), 0)
+		FROM payments
+		WHERE created_at >= LEAST($3::timestamptz, $4::timestamptz) AND (user_id = $1 OR ($2 <> '' AND client_ip = $2))`
+
+	var a domain.RiskActivity
+	err := r.db.QueryRow(ctx, query, userID, clientIP, velocitySince, daySince).Scan(&a.UserPayments, &a.IPPayments, &a.DailyAmount)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *RiskRepository) IsBlocked(ctx context.Context, kind domain.BlocklistKind, value string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM risk_blocklist WHERE kind = $1 AND value = $2)`
+
+	var blocked bool
+	err := r.db.QueryRow(ctx, query, string(kind), domain.NormalizeBlocklistValue(kind, value)).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *RiskRepository) ListBlocked(ctx context.Context, kind domain.BlocklistKind, limit, offset int) ([]domain.BlocklistEntry, error) {
+	query := `
+		SELECT id, kind, value, COALESCE(reason, ''), COALESCE(created_by::text, ''), created_at
+		FROM risk_blocklist
+		WHERE $1 = '' OR kind = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, string(kind), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.BlocklistEntry
+	for rows.Next() {
+		var e domain.BlocklistEntry
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Value, &e.Reason, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *RiskRepository) Block(ctx context.Context, entry *domain.BlocklistEntry) error {
+	query := `
+		INSERT INTO risk_blocklist (kind, value, reason, created_by, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::uuid, $5)
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query, string(entry.Kind), entry.Value, entry.Reason, entry.CreatedBy, entry.CreatedAt).Scan(&entry.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrBlocklistEntryExists
+	}
+	return err
+}
+
+func (r *RiskRepository) Unblock(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM risk_blocklist WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBlocklistEntryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// tenantFilter returns a "column = $n" predicate bound to the tenant carried
+// by ctx, with the tenant ID appended to args. Tenant-owned queries must be
+// built through it so a missing tenant fails closed instead of returning
+// rows from every organization.
+//
+//	cond, args, err := tenantFilter(ctx, "m.organization_id", args)
+//	query := `SELECT ... FROM memberships m WHERE ` + cond
+func tenantFilter(ctx context.Context, column string, args []interface{}) (string, []interface{}, error) {
+	tenantID, err := domain.TenantFromContext(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, tenantID)
+	return fmt.Sprintf("%s = $%d", column, len(args)), args, nil
+}
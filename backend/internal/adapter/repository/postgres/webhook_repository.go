@@ -0,0 +1,285 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+const webhookEndpointColumns = `id, COALESCE(organization_id::text, ''), url, COALESCE(description, ''), events, secret, active, COALESCE(created_by::text, ''), created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, COALESCE(last_error, ''), delivered_at, created_at, updated_at`
+
+const webhookAttemptColumns = `id, delivery_id, attempt, status_code, COALESCE(error, ''), COALESCE(response_body, ''), duration_ms, created_at`
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) ports.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func scanWebhookEndpoint(row pgx.Row) (*domain.WebhookEndpoint, error) {
+	var e domain.WebhookEndpoint
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.URL, &e.Description, &e.Events, &e.Secret, &e.Active, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// webhookEvents prepares an endpoint's subscriptions for a NOT NULL array
+// column
+func webhookEvents(endpoint *domain.WebhookEndpoint) []string {
+	if endpoint.Events == nil {
+		return []string{}
+	}
+	return endpoint.Events
+}
+
+func (r *WebhookRepository) listEndpoints(ctx context.Context, query string, args ...interface{}) ([]domain.WebhookEndpoint, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, *endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (organization_id, url, description, events, secret, active, created_by, created_at, updated_at)
+		VALUES (NULLIF($1, '')::uuid, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, '')::uuid, $8, $9)
+		RETURNING id`
+
+	return r.db.QueryRow(ctx, query,
+		endpoint.OrganizationID, endpoint.URL, endpoint.Description, webhookEvents(endpoint), endpoint.Secret, endpoint.Active,
+		endpoint.CreatedBy, endpoint.CreatedAt, endpoint.UpdatedAt,
+	).Scan(&endpoint.ID)
+}
+
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $1, description = NULLIF($2, ''), events = $3, secret = $4, active = $5, updated_at = $6
+		WHERE id = $7`
+
+	tag, err := r.db.Exec(ctx, query,
+		endpoint.URL, endpoint.Description, webhookEvents(endpoint), endpoint.Secret, endpoint.Active, endpoint.UpdatedAt, endpoint.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1`
+	return scanWebhookEndpoint(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, organizationID string) ([]domain.WebhookEndpoint, error) {
+	query := `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE organization_id IS NOT DISTINCT FROM NULLIF($1, '')::uuid
+		ORDER BY created_at`
+	return r.listEndpoints(ctx, query, organizationID)
+}
+
+func (r *WebhookRepository) ListActiveEndpoints(ctx context.Context, organizationID string) ([]domain.WebhookEndpoint, error) {
+	query := `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE active AND (organization_id IS NULL OR organization_id = NULLIF($1, '')::uuid)
+		ORDER BY created_at`
+	return r.listEndpoints(ctx, query, organizationID)
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Enqueue(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::json, $5, $6, $7, $8, $9)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		RETURNING id`
+
+	err := r.db.QueryRow(ctx, query,
+		delivery.EndpointID, delivery.EventID, delivery.EventType, string(delivery.Payload), string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt,
+	).Scan(&delivery.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, lease time.Duration) (*domain.WebhookDelivery, error) {
+	// SKIP LOCKED lets replicas claim different deliveries concurrently;
+	// the lease makes a delivery due again if its sender dies mid-attempt
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id = (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = $1 AND d.next_attempt_at <= NOW() AND e.active
+			ORDER BY d.next_attempt_at
+			LIMIT 1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	return scanWebhookDelivery(r.db.QueryRow(ctx, query, string(domain.WebhookPending), lease.Seconds()))
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateWebhookDelivery(ctx, tx, delivery); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, response_body, duration_ms, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7)
+		RETURNING id`
+	err = tx.QueryRow(ctx, query,
+		attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.ResponseBody, attempt.Duration.Milliseconds(), attempt.CreatedAt,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return updateWebhookDelivery(ctx, r.db, delivery)
+}
+
+func updateWebhookDelivery(ctx context.Context, db dbExecutor, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = NULLIF($5, ''), delivered_at = $6, updated_at = $7
+		WHERE id = $8`
+
+	tag, err := db.Exec(ctx, query,
+		string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError,
+		delivery.DeliveredAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	return scanWebhookDelivery(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, endpointID, string(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) ListAttempts(ctx context.Context, deliveryID string) ([]domain.WebhookAttempt, error) {
+	query := `SELECT ` + webhookAttemptColumns + ` FROM webhook_attempts WHERE delivery_id = $1 ORDER BY attempt, created_at`
+
+	rows, err := r.db.Query(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []domain.WebhookAttempt
+	for rows.Next() {
+		var (
+			a          domain.WebhookAttempt
+			durationMS int64
+		)
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.ResponseBody, &durationMS, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
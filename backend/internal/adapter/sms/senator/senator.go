@@ -14,18 +14,33 @@ const SenatorAPIURL = "https://api.fast-creat.ir/sms"
 type SenatorAdapter struct {
 	APIKey     string
 	TemplateID string
-	Client     *http.Client
+	// NotifyTemplateID is a template whose single placeholder carries the
+	// whole notification text
+	NotifyTemplateID string
+	Client           *http.Client
 }
 
 func NewSenatorAdapter() *SenatorAdapter {
 	return &SenatorAdapter{
-		APIKey:     os.Getenv("SENATOR_API_KEY"),
-		TemplateID: os.Getenv("SENATOR_TEMPLATE_ID"),
-		Client:     &http.Client{Timeout: 10 * time.Second},
+		APIKey:           os.Getenv("SENATOR_API_KEY"),
+		TemplateID:       os.Getenv("SENATOR_TEMPLATE_ID"),
+		NotifyTemplateID: os.Getenv("SENATOR_NOTIFY_TEMPLATE_ID"),
+		Client:           &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
 func (s *SenatorAdapter) SendOTP(ctx context.Context, phoneNumber string, code string) error {
+	return s.send(ctx, phoneNumber, code, s.TemplateID)
+}
+
+func (s *SenatorAdapter) SendMessage(ctx context.Context, phoneNumber string, message string) error {
+	if s.NotifyTemplateID == "" {
+		return fmt.Errorf("senator notification template is not configured")
+	}
+	return s.send(ctx, phoneNumber, message, s.NotifyTemplateID)
+}
+
+func (s *SenatorAdapter) send(ctx context.Context, phoneNumber string, code string, templateID string) error {
 	// https://api.fast-creat.ir/sms?apikey=xxxxx&type=sms&code=xxxxx&phone=xxxxx&template=xxxxx
 
 	params := url.Values{}
@@ -33,7 +48,7 @@ func (s *SenatorAdapter) SendOTP(ctx context.Context, phoneNumber string, code s
 	params.Add("type", "sms")
 	params.Add("code", code)
 	params.Add("phone", phoneNumber)
-	params.Add("template", s.TemplateID)
+	params.Add("template", templateID)
 
 	reqURL := fmt.Sprintf("%s?%s", SenatorAPIURL, params.Encode())
 
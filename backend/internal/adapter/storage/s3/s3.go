@@ -2,15 +2,22 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"os"
+	"path"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/resilience"
 )
 
 type S3Adapter struct {
@@ -19,8 +26,12 @@ type S3Adapter struct {
 	bucket        string
 }
 
-func NewS3Adapter() (*S3Adapter, error) {
+// NewS3Adapter sends requests with httpClient, whose transport is expected
+// to handle retries, so the SDK's own retryer is disabled
+func NewS3Adapter(httpClient *http.Client) (*S3Adapter, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithHTTPClient(httpClient),
+		config.WithRetryer(func() aws.Retryer { return aws.NopRetryer{} }),
 		config.WithRegion(os.Getenv("AWS_REGION")),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			os.Getenv("AWS_ACCESS_KEY_ID"),
@@ -73,3 +84,43 @@ func (s *S3Adapter) GeneratePresignedURL(ctx context.Context, key string, lifeti
 	}
 	return req.URL, nil
 }
+
+func (s *S3Adapter) GenerateDownloadURL(ctx context.Context, key, fileName string, lifetimeSecs int64) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	}
+	if contentType := mime.TypeByExtension(path.Ext(fileName)); contentType != "" {
+		input.ResponseContentType = aws.String(contentType)
+	}
+
+	req, err := s.presignClient.PresignGetObject(ctx, input, func(opts *s3.PresignOptions) {
+		opts.Expires = time.Duration(lifetimeSecs) * time.Second
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate download URL: %w", err)
+	}
+	return req.URL, nil
+}
+
+func (s *S3Adapter) StatObject(ctx context.Context, key string) (*ports.ObjectInfo, error) {
+	ctx = resilience.Idempotent(ctx)
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return nil, ports.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat S3 object: %w", err)
+	}
+
+	return &ports.ObjectInfo{
+		Key:         key,
+		Size:        aws.ToInt64(out.ContentLength),
+		ContentType: aws.ToString(out.ContentType),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package httpsender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/webhook"
+)
+
+// maxResponseBody is how much of an endpoint's answer is kept in the
+// delivery log
+const maxResponseBody = 1024
+
+// ErrForbiddenAddress is returned for endpoints resolving to an internal
+// address
+var ErrForbiddenAddress = errors.New("webhook endpoint resolves to a private address")
+
+// carrierGradeNAT is the shared address space of RFC 6598, which
+// netip.Addr.IsPrivate does not cover
+var carrierGradeNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+// Sender posts signed webhook deliveries over HTTP. Redirects are not
+// followed, so a 3xx answer counts as a failed attempt.
+type Sender struct {
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewSender creates a sender. Endpoint URLs are entered by merchants, so
+// unless allowPrivate is set connections to loopback, private and
+// link-local addresses are refused when dialing, after DNS resolution.
+func NewSender(allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+		// A proxy would be dialed instead of the endpoint and defeat the check
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// Deliver posts the delivery's payload to the endpoint, signed with its
+// secret
+func (s *Sender) Deliver(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (*ports.WebhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventIDHeader, delivery.EventID)
+	req.Header.Set(webhook.EventTypeHeader, delivery.EventType)
+	req.Header.Set(webhook.SignatureHeader, webhook.Header(endpoint.Secret, s.now(), delivery.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return &ports.WebhookResponse{StatusCode: resp.StatusCode, Body: strings.ToValidUTF8(string(body), "")}, nil
+}
+
+// refusePrivate is a net.Dialer control function rejecting connections to
+// addresses that are not publicly routable
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || carrierGradeNAT.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package httpsender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/pkg/webhook"
+)
+
+func testDelivery() *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:        "d1",
+		EventID:   "0b6f1c7e-2a3d-4e5f-8a9b-0c1d2e3f4a5b",
+		EventType: domain.WebhookPaymentVerified,
+		Payload:   []byte(`{"id":"0b6f1c7e-2a3d-4e5f-8a9b-0c1d2e3f4a5b","type":"payment.verified"}`),
+		Status:    domain.WebhookPending,
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Accepted", status: 200, body: "ok", wantStatus: 200, wantBody: "ok"},
+		{name: "Server Error", status: 500, body: "boom", wantStatus: 500, wantBody: "boom"},
+		{name: "Long Body", status: 200, body: strings.Repeat("a", 5000), wantStatus: 200, wantBody: strings.Repeat("a", maxResponseBody)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := testDelivery()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != http.MethodPost || string(body) != string(delivery.Payload) || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request = %s %q %q", r.Method, body, r.Header.Get("Content-Type"))
+				}
+				if r.Header.Get(webhook.EventIDHeader) != delivery.EventID || r.Header.Get(webhook.EventTypeHeader) != "payment.verified" {
+					t.Errorf("event headers = %v", r.Header)
+				}
+				if err := webhook.Verify("whsec_test", r.Header.Get(webhook.SignatureHeader), body, webhook.DefaultTolerance, signedAt); err != nil {
+					t.Errorf("signature = %q: %v", r.Header.Get(webhook.SignatureHeader), err)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := NewSender(true)
+			s.now = func() time.Time { return signedAt }
+			endpoint := &domain.WebhookEndpoint{URL: server.URL + "/hooks", Secret: "whsec_test"}
+
+			resp, err := s.Deliver(context.Background(), endpoint, delivery)
+			if err != nil {
+				t.Fatalf("Deliver() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || resp.Body != tt.wantBody {
+				t.Errorf("Deliver() = %d %q, want %d %q", resp.StatusCode, resp.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer server.Close()
+
+	resp, err := NewSender(true).Deliver(context.Background(), &domain.WebhookEndpoint{URL: server.URL, Secret: "whsec_test"}, testDelivery())
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusFound || followed {
+		t.Errorf("Deliver() = %d, followed = %v, want the redirect itself", resp.StatusCode, followed)
+	}
+}
+
+func TestDeliverRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request to a loopback endpoint")
+	}))
+	defer server.Close()
+
+	_, err := NewSender(false).Deliver(context.Background(), &domain.WebhookEndpoint{URL: server.URL, Secret: "whsec_test"}, testDelivery())
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Deliver() error = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestRefusePrivate(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: "93.184.216.34:443"},
+		{address: "[2606:2800:220:1:248:1893:25c8:1946]:443"},
+		{address: "127.0.0.1:80", wantErr: true},
+		{address: "10.1.2.3:443", wantErr: true},
+		{address: "172.16.0.1:443", wantErr: true},
+		{address: "192.168.1.10:8080", wantErr: true},
+		{address: "169.254.169.254:80", wantErr: true},
+		{address: "100.64.0.1:443", wantErr: true},
+		{address: "0.0.0.0:80", wantErr: true},
+		{address: "[::1]:443", wantErr: true},
+		{address: "[fd00::1]:443", wantErr: true},
+		{address: "[fe80::1%eth0]:443", wantErr: true},
+		{address: "[::ffff:127.0.0.1]:443", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := refusePrivate("tcp", tt.address, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refusePrivate(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuthzGrantEvaluation describes how a single role grant contributed to an
+// authorization decision
+type AuthzGrantEvaluation struct {
+	Grant UserRole
+	// Permissions is the full set of permission names the role carries
+	Permissions []string
+	// Grants reports whether this grant, on its own, satisfies the check
+	Grants bool
+	// Reason explains why the grant did or did not apply
+	Reason string
+}
+
+// AuthzDecision is the explained outcome of a permission check
+type AuthzDecision struct {
+	UserID      string
+	Permission  string
+	Scope       string
+	Allowed     bool
+	Reason      string
+	Evaluations []AuthzGrantEvaluation
+}
+
+// ExplainPermission evaluates every role grant of a user against a
+// permission in a scope and records why each one granted or was skipped.
+// rolePermissions maps role IDs to the permissions they carry.
+func ExplainPermission(userID, permission, scope string, grants []UserRole, rolePermissions map[string][]Permission, now time.Time) *AuthzDecision {
+	decision := &AuthzDecision{
+		UserID:     userID,
+		Permission: permission,
+		Scope:      scope,
+	}
+
+	var grantedBy []string
+	for _, grant := range grants {
+		eval := AuthzGrantEvaluation{Grant: grant}
+		hasPermission := false
+		for _, p := range rolePermissions[grant.RoleID] {
+			eval.Permissions = append(eval.Permissions, p.Name)
+			if p.Name == permission {
+				hasPermission = true
+			}
+		}
+
+		switch {
+		case !hasPermission:
+			eval.Reason = fmt.Sprintf("role %s does not carry %s", grant.RoleName, permission)
+		case grant.IsExpired(now):
+			eval.Reason = fmt.Sprintf("grant of role %s expired at %s", grant.RoleName, grant.ExpiresAt.Format(time.RFC3339))
+		case grant.IsGlobal() && scope != "":
+			eval.Reason = fmt.Sprintf("role %s is granted globally, not in scope %s", grant.RoleName, scope)
+		case grant.Scope != scope:
+			eval.Reason = fmt.Sprintf("role %s is only granted in scope %s", grant.RoleName, grant.Scope)
+		default:
+			eval.Grants = true
+			eval.Reason = fmt.Sprintf("role %s carries %s", grant.RoleName, permission)
+			grantedBy = append(grantedBy, grant.RoleName)
+		}
+		decision.Evaluations = append(decision.Evaluations, eval)
+	}
+
+	switch {
+	case len(grantedBy) > 0:
+		decision.Allowed = true
+		decision.Reason = fmt.Sprintf("granted by role(s) %v", grantedBy)
+	case len(grants) == 0:
+		decision.Reason = "user has no role grants"
+	default:
+		decision.Reason = fmt.Sprintf("no active role grant carries %s", permission)
+	}
+
+	return decision
+}
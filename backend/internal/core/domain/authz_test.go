@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExplainPermission(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	rolePermissions := map[string][]Permission{
+		"r-admin": {{Name: "admin:access"}, {Name: "users:read"}},
+		"r-user":  {{Name: "users:read"}},
+	}
+
+	tests := []struct {
+		name        string
+		scope       string
+		grants      []UserRole
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name:        "No Grants",
+			grants:      nil,
+			wantAllowed: false,
+			wantReason:  "user has no role grants",
+		},
+		{
+			name:        "Role Without Permission",
+			grants:      []UserRole{{RoleID: "r-user", RoleName: "user"}},
+			wantAllowed: false,
+			wantReason:  "no active role grant carries admin:access",
+		},
+		{
+			name:        "Active Global Grant",
+			grants:      []UserRole{{RoleID: "r-admin", RoleName: "admin"}},
+			wantAllowed: true,
+			wantReason:  "granted by role(s) [admin]",
+		},
+		{
+			name:        "Expired Grant",
+			grants:      []UserRole{{RoleID: "r-admin", RoleName: "admin", ExpiresAt: &past}},
+			wantAllowed: false,
+		},
+		{
+			name:        "Grant In Other Scope",
+			scope:       "tenant-a",
+			grants:      []UserRole{{RoleID: "r-admin", RoleName: "admin", Scope: "tenant-b"}},
+			wantAllowed: false,
+		},
+		{
+			name:        "Global Grant In Scope",
+			scope:       "tenant-a",
+			grants:      []UserRole{{RoleID: "r-admin", RoleName: "admin"}},
+			wantAllowed: false,
+		},
+		{
+			name:        "Grant In Same Scope",
+			scope:       "tenant-a",
+			grants:      []UserRole{{RoleID: "r-admin", RoleName: "admin", Scope: "tenant-a"}},
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := ExplainPermission("u1", "admin:access", tt.scope, tt.grants, rolePermissions, now)
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("Allowed = %v, want %v (%s)", decision.Allowed, tt.wantAllowed, decision.Reason)
+			}
+			if tt.wantReason != "" && decision.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", decision.Reason, tt.wantReason)
+			}
+			if len(decision.Evaluations) != len(tt.grants) {
+				t.Errorf("got %d evaluations, want %d", len(decision.Evaluations), len(tt.grants))
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrReceiptNotFound        = errors.New("receipt not found")
+	ErrInvalidReceipt         = errors.New("invalid receipt")
+	ErrReceiptAlreadyReviewed = errors.New("receipt was already reviewed")
+)
+
+// DuplicateReceiptWindow is how close two transfers of the same amount must
+// be to be flagged as a possible duplicate
+const DuplicateReceiptWindow = 10 * time.Minute
+
+// ReceiptStatus is the review state of a card-to-card receipt
+type ReceiptStatus string
+
+const (
+	ReceiptPending  ReceiptStatus = "pending"
+	ReceiptApproved ReceiptStatus = "approved"
+	ReceiptRejected ReceiptStatus = "rejected"
+)
+
+// CardReceipt is a card-to-card transfer a user reported for manual review
+type CardReceipt struct {
+	ID        string
+	PaymentID string
+	UserID    string
+	// Amount is in Rials
+	Amount int64
+	// TrackingNumber is the bank's reference for the transfer
+	TrackingNumber string
+	// ImageKey is the storage key of the uploaded receipt image
+	ImageKey    string
+	PaidAt      time.Time
+	Description string
+	Status      ReceiptStatus
+	// Flags explain why the receipt looks like a duplicate
+	Flags        []string
+	ReviewerID   string
+	ReviewReason string
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// CardReceiptFilter narrows the review queue
+type CardReceiptFilter struct {
+	Status  ReceiptStatus
+	UserID  string
+	Flagged *bool
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+func NewCardReceipt(userID string, amount int64, trackingNumber, imageKey string, paidAt time.Time, description string) (*CardReceipt, error) {
+	if err := Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+	trackingNumber = strings.TrimSpace(trackingNumber)
+	if trackingNumber == "" {
+		return nil, fmt.Errorf("%w: tracking number is required", ErrInvalidReceipt)
+	}
+	if imageKey == "" {
+		return nil, fmt.Errorf("%w: receipt image is required", ErrInvalidReceipt)
+	}
+	now := time.Now()
+	if paidAt.IsZero() || paidAt.After(now.Add(5*time.Minute)) {
+		return nil, fmt.Errorf("%w: invalid transfer time", ErrInvalidReceipt)
+	}
+
+	return &CardReceipt{
+		UserID:         userID,
+		Amount:         amount,
+		TrackingNumber: trackingNumber,
+		ImageKey:       imageKey,
+		PaidAt:         paidAt,
+		Description:    description,
+		Status:         ReceiptPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// Flag records a reason to look at the receipt more carefully
+func (r *CardReceipt) Flag(reason string) {
+	r.Flags = append(r.Flags, reason)
+}
+
+// IsFlagged reports whether the receipt looks like a duplicate
+func (r *CardReceipt) IsFlagged() bool {
+	return len(r.Flags) > 0
+}
+
+// CheckDuplicates flags the receipt against earlier receipts that reuse its
+// tracking number or image, or report the same amount at about the same time
+func (r *CardReceipt) CheckDuplicates(others []CardReceipt) {
+	for _, other := range others {
+		if other.ID == r.ID || other.Status == ReceiptRejected {
+			continue
+		}
+		switch {
+		case other.TrackingNumber == r.TrackingNumber:
+			r.Flag(fmt.Sprintf("tracking number already submitted in receipt %s", other.ID))
+		case other.ImageKey == r.ImageKey:
+			r.Flag(fmt.Sprintf("image already submitted in receipt %s", other.ID))
+		case other.Amount == r.Amount && absDuration(other.PaidAt.Sub(r.PaidAt)) <= DuplicateReceiptWindow:
+			r.Flag(fmt.Sprintf("same amount transferred within %s in receipt %s", DuplicateReceiptWindow, other.ID))
+		}
+	}
+}
+
+// Approve accepts the receipt
+func (r *CardReceipt) Approve(reviewerID, reason string) error {
+	return r.review(ReceiptApproved, reviewerID, reason)
+}
+
+// Reject declines the receipt; a reason is required so the user knows why
+func (r *CardReceipt) Reject(reviewerID, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: a reason is required to reject", ErrInvalidReceipt)
+	}
+	return r.review(ReceiptRejected, reviewerID, reason)
+}
+
+func (r *CardReceipt) review(status ReceiptStatus, reviewerID, reason string) error {
+	if r.Status != ReceiptPending {
+		return ErrReceiptAlreadyReviewed
+	}
+	now := time.Now()
+	r.Status = status
+	r.ReviewerID = reviewerID
+	r.ReviewReason = reason
+	r.ReviewedAt = &now
+	r.UpdatedAt = now
+	return nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewCardReceipt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		amount   int64
+		tracking string
+		image    string
+		paidAt   time.Time
+		wantErr  error
+	}{
+		{name: "Valid Receipt", amount: 50000, tracking: "123456", image: "receipts/a.jpg", paidAt: now},
+		{name: "Zero Amount", amount: 0, tracking: "123456", image: "receipts/a.jpg", paidAt: now, wantErr: ErrInvalidAmount},
+		{name: "Missing Tracking Number", amount: 50000, tracking: " ", image: "receipts/a.jpg", paidAt: now, wantErr: ErrInvalidReceipt},
+		{name: "Missing Image", amount: 50000, tracking: "123456", paidAt: now, wantErr: ErrInvalidReceipt},
+		{name: "Future Transfer", amount: 50000, tracking: "123456", image: "receipts/a.jpg", paidAt: now.Add(time.Hour), wantErr: ErrInvalidReceipt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewCardReceipt("u1", tt.amount, tt.tracking, tt.image, tt.paidAt, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewCardReceipt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && r.Status != ReceiptPending {
+				t.Errorf("NewCardReceipt() status = %v, want %v", r.Status, ReceiptPending)
+			}
+		})
+	}
+}
+
+func TestCardReceiptCheckDuplicates(t *testing.T) {
+	paidAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		other       CardReceipt
+		wantFlagged bool
+	}{
+		{name: "Same Tracking Number", other: CardReceipt{ID: "r0", TrackingNumber: "111", ImageKey: "b", Amount: 1, PaidAt: paidAt.Add(-48 * time.Hour)}, wantFlagged: true},
+		{name: "Same Image", other: CardReceipt{ID: "r0", TrackingNumber: "222", ImageKey: "a", Amount: 1}, wantFlagged: true},
+		{name: "Same Amount Close In Time", other: CardReceipt{ID: "r0", TrackingNumber: "222", ImageKey: "b", Amount: 50000, PaidAt: paidAt.Add(5 * time.Minute)}, wantFlagged: true},
+		{name: "Same Amount Far Apart", other: CardReceipt{ID: "r0", TrackingNumber: "222", ImageKey: "b", Amount: 50000, PaidAt: paidAt.Add(time.Hour)}},
+		{name: "Rejected Duplicate Ignored", other: CardReceipt{ID: "r0", TrackingNumber: "111", ImageKey: "b", Status: ReceiptRejected}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CardReceipt{ID: "r1", TrackingNumber: "111", ImageKey: "a", Amount: 50000, PaidAt: paidAt}
+			r.CheckDuplicates([]CardReceipt{tt.other})
+			if r.IsFlagged() != tt.wantFlagged {
+				t.Errorf("IsFlagged() = %v, want %v (flags %v)", r.IsFlagged(), tt.wantFlagged, r.Flags)
+			}
+		})
+	}
+}
+
+func TestCardReceiptReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       ReceiptStatus
+		approve    bool
+		reason     string
+		wantStatus ReceiptStatus
+		wantErr    error
+	}{
+		{name: "Approve Pending", from: ReceiptPending, approve: true, wantStatus: ReceiptApproved},
+		{name: "Reject Pending", from: ReceiptPending, reason: "amount mismatch", wantStatus: ReceiptRejected},
+		{name: "Reject Without Reason", from: ReceiptPending, wantErr: ErrInvalidReceipt},
+		{name: "Approve Rejected", from: ReceiptRejected, approve: true, wantErr: ErrReceiptAlreadyReviewed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CardReceipt{Status: tt.from}
+			var err error
+			if tt.approve {
+				err = r.Approve("admin", tt.reason)
+			} else {
+				err = r.Reject("admin", tt.reason)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (r.Status != tt.wantStatus || r.ReviewerID != "admin" || r.ReviewedAt == nil) {
+				t.Errorf("review not recorded: %+v", r)
+			}
+			if err != nil && r.Status != tt.from {
+				t.Errorf("status changed to %v on rejected review", r.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrCouponCodeTaken     = errors.New("coupon code is already taken")
+	ErrCouponInUse         = errors.New("coupon has been redeemed and can only be deactivated")
+	ErrCouponNotApplicable = errors.New("coupon cannot be applied to this payment")
+	ErrCouponExhausted     = errors.New("coupon has reached its redemption limit")
+	ErrCouponUserLimit     = errors.New("coupon was already used the maximum number of times")
+)
+
+// DiscountType is how a coupon's value is applied
+type DiscountType string
+
+const (
+	// DiscountPercent takes Value percent off, up to MaxDiscount when set
+	DiscountPercent DiscountType = "percent"
+	// DiscountFixed takes Value Rials off
+	DiscountFixed DiscountType = "fixed"
+)
+
+// MinDiscountedAmount is the least a discounted payment charges, in Rials;
+// gateways refuse smaller amounts, so coupons never make a payment free
+const MinDiscountedAmount int64 = 10_000
+
+// Coupon is a promo code that discounts payments
+type Coupon struct {
+	ID   string
+	Code string
+	Type DiscountType
+	// Value is a percentage for DiscountPercent and Rials for DiscountFixed
+	Value int64
+	// MaxDiscount caps percentage discounts in Rials; zero means no cap
+	MaxDiscount int64
+	// MaxRedemptions is how many payments may use the coupon in total and
+	// PerUserLimit how many per user; zero means unlimited
+	MaxRedemptions int
+	PerUserLimit   int
+	// Redemptions counts the payments holding or having used the coupon
+	Redemptions int
+	// ValidFrom and ValidUntil bound when the coupon applies; zero times
+	// leave that end open
+	ValidFrom  time.Time
+	ValidUntil time.Time
+	// PlanIDs restricts the coupon to payments for these plans; empty means
+	// any payment
+	PlanIDs   []string
+	Active    bool
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeCouponCode brings a code to the form it is stored in
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func NewCoupon(code string, discountType DiscountType, value int64, createdBy string) (*Coupon, error) {
+	now := time.Now()
+	c := &Coupon{
+		Code:      NormalizeCouponCode(code),
+		Type:      discountType,
+		Value:     value,
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the coupon's definition
+func (c *Coupon) Validate() error {
+	if len(c.Code) < 3 || len(c.Code) > 32 {
+		return fmt.Errorf("%w: code must be 3 to 32 characters", ErrInvalidCoupon)
+	}
+	for _, r := range c.Code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("%w: code may only contain letters, digits, - and _", ErrInvalidCoupon)
+		}
+	}
+
+	switch c.Type {
+	case DiscountPercent:
+		if c.Value < 1 || c.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 1 and 100", ErrInvalidCoupon)
+		}
+	case DiscountFixed:
+		if c.Value <= 0 {
+			return fmt.Errorf("%w: discount must be positive", ErrInvalidCoupon)
+		}
+	default:
+		return fmt.Errorf("%w: unknown discount type %q", ErrInvalidCoupon, c.Type)
+	}
+
+	if c.MaxDiscount < 0 || c.MaxRedemptions < 0 || c.PerUserLimit < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", ErrInvalidCoupon)
+	}
+	if !c.ValidFrom.IsZero() && !c.ValidUntil.IsZero() && !c.ValidUntil.After(c.ValidFrom) {
+		return fmt.Errorf("%w: validity must end after it starts", ErrInvalidCoupon)
+	}
+	return nil
+}
+
+// CheckApplicable reports why the coupon cannot discount a payment for
+// planID at now, if it cannot. planID is empty for payments not made for a
+// plan. Redemption limits are checked when the coupon is redeemed.
+func (c *Coupon) CheckApplicable(planID string, now time.Time) error {
+	if !c.Active {
+		return fmt.Errorf("%w: coupon is inactive", ErrCouponNotApplicable)
+	}
+	if !c.ValidFrom.IsZero() && now.Before(c.ValidFrom) {
+		return fmt.Errorf("%w: coupon is not valid yet", ErrCouponNotApplicable)
+	}
+	if !c.ValidUntil.IsZero() && !now.Before(c.ValidUntil) {
+		return fmt.Errorf("%w: coupon has expired", ErrCouponNotApplicable)
+	}
+	if c.MaxRedemptions > 0 && c.Redemptions >= c.MaxRedemptions {
+		return ErrCouponExhausted
+	}
+	if len(c.PlanIDs) == 0 {
+		return nil
+	}
+	for _, id := range c.PlanIDs {
+		if id == planID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: coupon is not valid for this plan", ErrCouponNotApplicable)
+}
+
+// Discount returns what the coupon takes off amount, in Rials. It is
+// rounded down to whole Tomans so gateways counting in Tomans can charge
+// the rest exactly, and never leaves less than MinDiscountedAmount.
+func (c *Coupon) Discount(amount int64) int64 {
+	discount := c.Value
+	if c.Type == DiscountPercent {
+		discount = amount * c.Value / 100
+		if c.MaxDiscount > 0 && discount > c.MaxDiscount {
+			discount = c.MaxDiscount
+		}
+	}
+	if limit := amount - MinDiscountedAmount; discount > limit {
+		discount = limit
+	}
+	discount -= discount % 10
+	if discount < 0 {
+		return 0
+	}
+	return discount
+}
+
+// RedemptionStatus is a state in a coupon redemption's lifecycle
+type RedemptionStatus string
+
+const (
+	// RedemptionReserved holds a use of the coupon for a payment in flight
+	RedemptionReserved RedemptionStatus = "reserved"
+	// RedemptionRedeemed belongs to a verified payment
+	RedemptionRedeemed RedemptionStatus = "redeemed"
+	// RedemptionReleased gave the use back because the payment never
+	// completed
+	RedemptionReleased RedemptionStatus = "released"
+)
+
+// CouponRedemption is a coupon's use by a payment
+type CouponRedemption struct {
+	ID        string
+	CouponID  string
+	UserID    string
+	PaymentID string
+	// Discount is the amount taken off the payment, in Rials
+	Discount  int64
+	Status    RedemptionStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
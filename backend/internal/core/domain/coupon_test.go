@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewCoupon(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		typ      DiscountType
+		value    int64
+		wantCode string
+		wantErr  error
+	}{
+		{name: "Percent", code: " nowruz-1403 ", typ: DiscountPercent, value: 20, wantCode: "NOWRUZ-1403"},
+		{name: "Fixed", code: "WELCOME", typ: DiscountFixed, value: 500_000, wantCode: "WELCOME"},
+		{name: "Percent Over 100", code: "BIG", typ: DiscountPercent, value: 101, wantErr: ErrInvalidCoupon},
+		{name: "Zero Fixed", code: "ZERO", typ: DiscountFixed, value: 0, wantErr: ErrInvalidCoupon},
+		{name: "Short Code", code: "AB", typ: DiscountFixed, value: 1000, wantErr: ErrInvalidCoupon},
+		{name: "Invalid Characters", code: "50% OFF", typ: DiscountPercent, value: 50, wantErr: ErrInvalidCoupon},
+		{name: "Unknown Type", code: "FREE", typ: "free", value: 1, wantErr: ErrInvalidCoupon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupon, err := NewCoupon(tt.code, tt.typ, tt.value, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewCoupon() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (coupon.Code != tt.wantCode || !coupon.Active) {
+				t.Errorf("NewCoupon() = %q active %v, want %q active", coupon.Code, coupon.Active, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCouponValidateWindow(t *testing.T) {
+	now := time.Now()
+	coupon := &Coupon{Code: "WINDOW", Type: DiscountFixed, Value: 1000, ValidFrom: now, ValidUntil: now}
+
+	if err := coupon.Validate(); !errors.Is(err, ErrInvalidCoupon) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidCoupon)
+	}
+}
+
+func TestCouponCheckApplicable(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		coupon  Coupon
+		planID  string
+		wantErr error
+	}{
+		{name: "Applicable", coupon: Coupon{Active: true}},
+		{name: "Inactive", coupon: Coupon{}, wantErr: ErrCouponNotApplicable},
+		{name: "Not Started", coupon: Coupon{Active: true, ValidFrom: now.Add(time.Hour)}, wantErr: ErrCouponNotApplicable},
+		{name: "Expired", coupon: Coupon{Active: true, ValidUntil: now}, wantErr: ErrCouponNotApplicable},
+		{name: "Within Window", coupon: Coupon{Active: true, ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour)}},
+		{name: "Exhausted", coupon: Coupon{Active: true, MaxRedemptions: 3, Redemptions: 3}, wantErr: ErrCouponExhausted},
+		{name: "Matching Plan", coupon: Coupon{Active: true, PlanIDs: []string{"basic", "pro"}}, planID: "pro"},
+		{name: "Other Plan", coupon: Coupon{Active: true, PlanIDs: []string{"pro"}}, planID: "basic", wantErr: ErrCouponNotApplicable},
+		{name: "Plan Coupon Without Plan", coupon: Coupon{Active: true, PlanIDs: []string{"pro"}}, wantErr: ErrCouponNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.coupon.CheckApplicable(tt.planID, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckApplicable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCouponDiscount(t *testing.T) {
+	tests := []struct {
+		name   string
+		coupon Coupon
+		amount int64
+		want   int64
+	}{
+		{name: "Percent", coupon: Coupon{Type: DiscountPercent, Value: 20}, amount: 1_000_000, want: 200_000},
+		{name: "Percent Rounded To Tomans", coupon: Coupon{Type: DiscountPercent, Value: 15}, amount: 123_450, want: 18_510},
+		{name: "Percent Capped", coupon: Coupon{Type: DiscountPercent, Value: 50, MaxDiscount: 100_000}, amount: 1_000_000, want: 100_000},
+		{name: "Fixed", coupon: Coupon{Type: DiscountFixed, Value: 300_000}, amount: 1_000_000, want: 300_000},
+		{name: "Fixed Above Amount", coupon: Coupon{Type: DiscountFixed, Value: 5_000_000}, amount: 1_000_000, want: 1_000_000 - MinDiscountedAmount},
+		{name: "Full Percent Keeps Minimum", coupon: Coupon{Type: DiscountPercent, Value: 100}, amount: 50_000, want: 50_000 - MinDiscountedAmount},
+		{name: "Amount Below Minimum", coupon: Coupon{Type: DiscountFixed, Value: 1_000}, amount: 5_000, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.coupon.Discount(tt.amount); got != tt.want {
+				t.Errorf("Discount(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
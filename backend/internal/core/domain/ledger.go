@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrUnbalancedEntry    = errors.New("journal entry does not balance")
+	ErrInsufficientFunds  = errors.New("insufficient wallet balance")
+	ErrLedgerConflict     = errors.New("ledger account was modified concurrently")
+	ErrDuplicateEntry     = errors.New("journal entry already recorded")
+	ErrAccountNotFound    = errors.New("ledger account not found")
+	ErrEntryNotFound      = errors.New("journal entry not found")
+	ErrPaymentNotToppable = errors.New("payment cannot top up the wallet")
+	ErrRefundExceedsSpend = errors.New("refund exceeds the credit spent")
+)
+
+// AccountType is the kind of a ledger account
+type AccountType string
+
+const (
+	// AccountUserWallet holds credit owed to a user (owner is the user ID)
+	AccountUserWallet AccountType = "user_wallet"
+	// AccountGatewayClearing holds money collected by a gateway (owner is
+	// the gateway name)
+	AccountGatewayClearing AccountType = "gateway_clearing"
+	// AccountRevenue holds money earned from spent credit
+	AccountRevenue AccountType = "revenue"
+	// AccountRefunds holds spent credit given back to users
+	AccountRefunds AccountType = "refunds"
+	// AccountPayouts holds wallet credit withdrawn by users until it is
+	// transferred to their bank accounts
+	AccountPayouts AccountType = "payouts"
+)
+
+// CreditNormal reports whether the account's balance grows with credits
+// (liabilities and income) rather than debits (assets and expenses)
+func (t AccountType) CreditNormal() bool {
+	return t == AccountUserWallet || t == AccountRevenue || t == AccountPayouts
+}
+
+// JournalKind is the business event a journal entry records
+type JournalKind string
+
+const (
+	JournalTopUp  JournalKind = "topup"
+	JournalSpend  JournalKind = "spend"
+	JournalRefund JournalKind = "refund"
+	// JournalPayout holds a payout's amount when it is requested, and
+	// JournalPayoutReversal returns it if the payout is not sent
+	JournalPayout         JournalKind = "payout"
+	JournalPayoutReversal JournalKind = "payout_reversal"
+	// JournalPayoutSettlement records that the bank transferred a payout
+	JournalPayoutSettlement JournalKind = "payout_settlement"
+	// JournalTopUpRefund takes a refunded top-up back out of the wallet when
+	// the refund is requested, and JournalTopUpRefundRelease returns it if
+	// the refund does not happen
+	JournalTopUpRefund        JournalKind = "topup_refund"
+	JournalTopUpRefundRelease JournalKind = "topup_refund_release"
+)
+
+// LedgerAccount is a balance kept by the ledger. Balance is a cached sum of
+// the account's postings; Version guards it against concurrent updates.
+type LedgerAccount struct {
+	ID      string
+	Type    AccountType
+	OwnerID string
+	// Balance is in Rials, positive on the account's normal side
+	Balance   int64
+	Version   int64
+	CreatedAt time.Time
+}
+
+// Apply adds a posting to the cached balance. Wallets may not go negative.
+func (a *LedgerAccount) Apply(p Posting) error {
+	delta := p.Debit - p.Credit
+	if a.Type.CreditNormal() {
+		delta = -delta
+	}
+	if a.Type == AccountUserWallet && a.Balance+delta < 0 {
+		return ErrInsufficientFunds
+	}
+	a.Balance += delta
+	return nil
+}
+
+// Posting is one side of a journal entry on a single account; exactly one of
+// Debit and Credit is set
+type Posting struct {
+	AccountID string
+	Debit     int64
+	Credit    int64
+}
+
+// JournalEntry is an immutable, balanced set of postings
+type JournalEntry struct {
+	ID   string
+	Kind JournalKind
+	// Reference identifies what the entry is for (payment, order, ...); it
+	// is unique per kind so the same event is never recorded twice
+	Reference   string
+	Description string
+	Postings    []Posting
+	CreatedAt   time.Time
+}
+
+// NewTransfer creates an entry moving amount from the debited account to the
+// credited one
+func NewTransfer(kind JournalKind, reference, description, debitAccountID, creditAccountID string, amount int64) (*JournalEntry, error) {
+	entry := &JournalEntry{
+		Kind:        kind,
+		Reference:   reference,
+		Description: description,
+		Postings: []Posting{
+			{AccountID: debitAccountID, Debit: amount},
+			{AccountID: creditAccountID, Credit: amount},
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Validate checks that every posting is one-sided and positive and that
+// debits equal credits
+func (e *JournalEntry) Validate() error {
+	if e.Reference == "" {
+		return fmt.Errorf("%w: reference is required", ErrUnbalancedEntry)
+	}
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: at least two postings are required", ErrUnbalancedEntry)
+	}
+
+	var debits, credits int64
+	for _, p := range e.Postings {
+		if p.AccountID == "" || p.Debit < 0 || p.Credit < 0 || (p.Debit == 0) == (p.Credit == 0) {
+			return fmt.Errorf("%w: each posting needs an account and exactly one positive side", ErrUnbalancedEntry)
+		}
+		debits += p.Debit
+		credits += p.Credit
+	}
+	if debits != credits {
+		return fmt.Errorf("%w: debits %d != credits %d", ErrUnbalancedEntry, debits, credits)
+	}
+	return nil
+}
+
+// LedgerStatement is a journal entry as seen from one account
+type LedgerStatement struct {
+	EntryID     string
+	Kind        JournalKind
+	Reference   string
+	Description string
+	Debit       int64
+	Credit      int64
+	CreatedAt   time.Time
+}
+
+// LedgerMismatch is an inconsistency found by re-summing the journal
+type LedgerMismatch struct {
+	// AccountID is set when a cached balance differs from its postings
+	AccountID string
+	// EntryID is set when an entry's debits and credits differ
+	EntryID  string
+	Expected int64
+	Actual   int64
+}
+
+func (m LedgerMismatch) String() string {
+	if m.EntryID != "" {
+		return fmt.Sprintf("entry %s: debits %d != credits %d", m.EntryID, m.Expected, m.Actual)
+	}
+	return fmt.Sprintf("account %s: postings sum to %d, cached balance %d", m.AccountID, m.Expected, m.Actual)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJournalEntryValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		postings []Posting
+		wantErr  bool
+	}{
+		{name: "Balanced Transfer", postings: []Posting{{AccountID: "a", Debit: 100}, {AccountID: "b", Credit: 100}}},
+		{name: "Balanced Split", postings: []Posting{{AccountID: "a", Debit: 100}, {AccountID: "b", Credit: 60}, {AccountID: "c", Credit: 40}}},
+		{name: "Unbalanced", postings: []Posting{{AccountID: "a", Debit: 100}, {AccountID: "b", Credit: 90}}, wantErr: true},
+		{name: "Single Posting", postings: []Posting{{AccountID: "a", Debit: 100}}, wantErr: true},
+		{name: "Two Sided Posting", postings: []Posting{{AccountID: "a", Debit: 100, Credit: 100}, {AccountID: "b", Debit: 0, Credit: 0}}, wantErr: true},
+		{name: "Negative Posting", postings: []Posting{{AccountID: "a", Debit: -100}, {AccountID: "b", Credit: -100}}, wantErr: true},
+		{name: "Missing Account", postings: []Posting{{Debit: 100}, {AccountID: "b", Credit: 100}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &JournalEntry{Kind: JournalSpend, Reference: "order-1", Postings: tt.postings}
+			err := e.Validate()
+			if tt.wantErr != errors.Is(err, ErrUnbalancedEntry) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLedgerAccountApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType AccountType
+		balance     int64
+		posting     Posting
+		wantBalance int64
+		wantErr     error
+	}{
+		{name: "Wallet Credit", accountType: AccountUserWallet, balance: 0, posting: Posting{Credit: 500}, wantBalance: 500},
+		{name: "Wallet Debit", accountType: AccountUserWallet, balance: 500, posting: Posting{Debit: 200}, wantBalance: 300},
+		{name: "Wallet Overdraft", accountType: AccountUserWallet, balance: 100, posting: Posting{Debit: 200}, wantBalance: 100, wantErr: ErrInsufficientFunds},
+		{name: "Clearing Debit", accountType: AccountGatewayClearing, balance: 0, posting: Posting{Debit: 500}, wantBalance: 500},
+		{name: "Revenue Credit", accountType: AccountRevenue, balance: 0, posting: Posting{Credit: 200}, wantBalance: 200},
+		{name: "Refunds Debit", accountType: AccountRefunds, balance: 0, posting: Posting{Debit: 50}, wantBalance: 50},
+		{name: "Payouts Credit", accountType: AccountPayouts, balance: 0, posting: Posting{Credit: 300}, wantBalance: 300},
+		{name: "Payouts Settled", accountType: AccountPayouts, balance: 300, posting: Posting{Debit: 300}, wantBalance: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &LedgerAccount{Type: tt.accountType, Balance: tt.balance}
+			err := a.Apply(tt.posting)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if a.Balance != tt.wantBalance {
+				t.Errorf("Apply() balance = %d, want %d", a.Balance, tt.wantBalance)
+			}
+		})
+	}
+}
+
+func TestNewTransfer(t *testing.T) {
+	if _, err := NewTransfer(JournalTopUp, "p1", "", "a", "b", 0); !errors.Is(err, ErrUnbalancedEntry) {
+		t.Errorf("NewTransfer() with zero amount error = %v, want ErrUnbalancedEntry", err)
+	}
+	e, err := NewTransfer(JournalTopUp, "p1", "", "a", "b", 1000)
+	if err != nil {
+		t.Fatalf("NewTransfer() error = %v", err)
+	}
+	if e.Postings[0].Debit != 1000 || e.Postings[1].Credit != 1000 {
+		t.Errorf("NewTransfer() postings = %+v", e.Postings)
+	}
+}
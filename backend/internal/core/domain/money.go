@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInexactAmount = errors.New("amount cannot be converted exactly")
+
+// CurrencyUnit is the unit an amount is expressed in
+type CurrencyUnit string
+
+const (
+	CurrencyRial CurrencyUnit = "IRR"
+	// CurrencyToman is ten Rials; it is not an ISO 4217 code but is what
+	// users and several gateways count in
+	CurrencyToman CurrencyUnit = "IRT"
+)
+
+// rialsPer is how many Rials one of each unit is worth
+var rialsPer = map[CurrencyUnit]int64{
+	CurrencyRial:  1,
+	CurrencyToman: 10,
+}
+
+// Money is an amount in an explicit unit. Amounts are stored in Rials and
+// converted only where a unit is chosen, e.g. at a gateway's API.
+type Money struct {
+	Amount int64
+	Unit   CurrencyUnit
+}
+
+// NewMoney creates a positive amount in a known unit; an empty unit means
+// Rials
+func NewMoney(amount int64, unit CurrencyUnit) (Money, error) {
+	if unit == "" {
+		unit = CurrencyRial
+	}
+	m := Money{Amount: amount, Unit: unit}
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// Rials returns amount in Rials without validating it
+func Rials(amount int64) Money {
+	return Money{Amount: amount, Unit: CurrencyRial}
+}
+
+// Tomans returns amount in Tomans without validating it
+func Tomans(amount int64) Money {
+	return Money{Amount: amount, Unit: CurrencyToman}
+}
+
+// Validate rejects zero and negative amounts and unknown units. Every
+// amount a user or gateway pays is checked here.
+func (m Money) Validate() error {
+	if _, ok := rialsPer[m.Unit]; !ok {
+		return fmt.Errorf("%w: unknown currency unit %q", ErrInvalidAmount, m.Unit)
+	}
+	if m.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// In converts m to unit. Converting down to Tomans fails with
+// ErrInexactAmount unless the amount is a whole number of Tomans.
+func (m Money) In(unit CurrencyUnit) (Money, error) {
+	from, ok := rialsPer[m.Unit]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: unknown currency unit %q", ErrInvalidAmount, m.Unit)
+	}
+	to, ok := rialsPer[unit]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: unknown currency unit %q", ErrInvalidAmount, unit)
+	}
+
+	rials := m.Amount * from
+	if rials%to != 0 {
+		return Money{}, fmt.Errorf("%w: %d Rials is not a whole number of %s", ErrInexactAmount, rials, unit)
+	}
+	return Money{Amount: rials / to, Unit: unit}, nil
+}
+
+// ToRials returns the amount in Rials; Rials are the smallest unit, so the
+// conversion is always exact
+func (m Money) ToRials() int64 {
+	return m.Amount * rialsPer[m.Unit]
+}
+
+// unitNames are the localized names of the units
+var unitNames = map[string]map[CurrencyUnit]string{
+	"en": {CurrencyRial: "Rials", CurrencyToman: "Tomans"},
+	"fa": {CurrencyRial: "ریال", CurrencyToman: "تومان"},
+}
+
+// Format renders m for display, e.g. "1,250,000 Rials" or, for "fa",
+// "۱٬۲۵۰٬۰۰۰ ریال". Unknown locales are formatted as "en".
+func (m Money) Format(locale string) string {
+	names, ok := unitNames[locale]
+	if !ok {
+		locale = "en"
+		names = unitNames[locale]
+	}
+	name, ok := names[m.Unit]
+	if !ok {
+		name = string(m.Unit)
+	}
+	return FormatNumber(m.Amount, locale) + " " + name
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d %s", m.Amount, m.Unit)
+}
+
+// FormatNumber groups the digits of n in thousands; "fa" uses Persian
+// digits and the Arabic thousands separator
+func FormatNumber(n int64, locale string) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	separator := ","
+	if locale == "fa" {
+		separator = "٬"
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		if locale == "fa" {
+			b.WriteRune('۰' + (d - '0'))
+		} else {
+			b.WriteRune(d)
+		}
+	}
+	return sign + b.String()
+}
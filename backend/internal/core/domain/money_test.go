@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		unit      CurrencyUnit
+		wantUnit  CurrencyUnit
+		wantRials int64
+		wantErr   bool
+	}{
+		{name: "Rials", amount: 1000, unit: CurrencyRial, wantUnit: CurrencyRial, wantRials: 1000},
+		{name: "Tomans", amount: 100, unit: CurrencyToman, wantUnit: CurrencyToman, wantRials: 1000},
+		{name: "Default Unit", amount: 1000, unit: "", wantUnit: CurrencyRial, wantRials: 1000},
+		{name: "Zero Amount", amount: 0, unit: CurrencyRial, wantErr: true},
+		{name: "Negative Amount", amount: -10, unit: CurrencyRial, wantErr: true},
+		{name: "Unknown Unit", amount: 1000, unit: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMoney(tt.amount, tt.unit)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Errorf("NewMoney() error = %v, want ErrInvalidAmount", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMoney() error = %v", err)
+			}
+			if m.Unit != tt.wantUnit || m.Amount != tt.amount {
+				t.Errorf("NewMoney() = %v, want %d %s", m, tt.amount, tt.wantUnit)
+			}
+			if got := m.ToRials(); got != tt.wantRials {
+				t.Errorf("ToRials() = %d, want %d", got, tt.wantRials)
+			}
+		})
+	}
+}
+
+func TestMoneyIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		unit    CurrencyUnit
+		want    Money
+		wantErr error
+	}{
+		{name: "Tomans To Rials", money: Tomans(150), unit: CurrencyRial, want: Rials(1500)},
+		{name: "Rials To Tomans", money: Rials(1500), unit: CurrencyToman, want: Tomans(150)},
+		{name: "Rials To Rials", money: Rials(1505), unit: CurrencyRial, want: Rials(1505)},
+		{name: "Inexact Tomans", money: Rials(1505), unit: CurrencyToman, wantErr: ErrInexactAmount},
+		{name: "Unknown Target", money: Rials(1500), unit: "USD", wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.money.In(tt.unit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("In() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("In() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("In() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		money  Money
+		locale string
+		want   string
+	}{
+		{name: "English Rials", money: Rials(1250000), locale: "en", want: "1,250,000 Rials"},
+		{name: "English Small", money: Tomans(500), locale: "en", want: "500 Tomans"},
+		{name: "Persian Rials", money: Rials(1250000), locale: "fa", want: "۱٬۲۵۰٬۰۰۰ ریال"},
+		{name: "Persian Tomans", money: Tomans(1000), locale: "fa", want: "۱٬۰۰۰ تومان"},
+		{name: "Unknown Locale", money: Rials(1000), locale: "de", want: "1,000 Rials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Format(tt.locale); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n      int64
+		locale string
+		want   string
+	}{
+		{n: 0, locale: "en", want: "0"},
+		{n: 999, locale: "en", want: "999"},
+		{n: 1000, locale: "en", want: "1,000"},
+		{n: -1234567, locale: "en", want: "-1,234,567"},
+		{n: 1234567, locale: "fa", want: "۱٬۲۳۴٬۵۶۷"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatNumber(tt.n, tt.locale); got != tt.want {
+				t.Errorf("FormatNumber(%d, %q) = %q, want %q", tt.n, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
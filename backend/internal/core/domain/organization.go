@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidOrganization = errors.New("invalid organization")
+	ErrInvalidSlug         = errors.New("invalid organization slug")
+	ErrNotMember           = errors.New("user is not a member of the organization")
+	ErrNoTenant            = errors.New("no tenant in context")
+	ErrInvalidInvitation   = errors.New("invitation needs an email or a phone number")
+	ErrInvitationExpired   = errors.New("invitation expired")
+	ErrInvitationAccepted  = errors.New("invitation already accepted")
+	ErrInvitationRecipient = errors.New("invitation was sent to someone else")
+	ErrInvalidOrgRole      = errors.New("role cannot be granted inside an organization")
+	ErrLastOrgOwner        = errors.New("organization must keep at least one owner")
+)
+
+// Tenant-scoped role names, granted with the organization ID as scope
+const (
+	RoleOrgOwner  = "org_owner"
+	RoleOrgMember = "org_member"
+)
+
+// IsOrgRole reports whether a role may be granted inside an organization.
+// Other roles carry app-wide permissions and are only granted by admins.
+func IsOrgRole(name string) bool {
+	return name == RoleOrgOwner || name == RoleOrgMember
+}
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{1,61}[a-z0-9])?$`)
+
+// Organization is a tenant that owns members and their scoped roles
+type Organization struct {
+	ID        string
+	Name      string
+	Slug      string
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewOrganization(name, slug, createdBy string) (*Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidOrganization
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugRegex.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	now := time.Now()
+	return &Organization{
+		Name:      name,
+		Slug:      slug,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Membership links a user to an organization
+type Membership struct {
+	OrganizationID string
+	UserID         string
+	Email          string
+	Phone          string
+	CreatedAt      time.Time
+}
+
+// Invitation asks someone, by email or SMS, to join an organization with a role
+type Invitation struct {
+	ID             string
+	OrganizationID string
+	Email          string
+	Phone          string
+	RoleID         string
+	TokenHash      string
+	InvitedBy      string
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+func NewInvitation(organizationID, email, phone, roleID, invitedBy, tokenHash string, ttl time.Duration) (*Invitation, error) {
+	if email == "" && phone == "" {
+		return nil, ErrInvalidInvitation
+	}
+	if phone != "" && !isValidPhone(phone) {
+		return nil, ErrInvalidPhone
+	}
+
+	now := time.Now()
+	return &Invitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Phone:          phone,
+		RoleID:         roleID,
+		TokenHash:      tokenHash,
+		InvitedBy:      invitedBy,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}, nil
+}
+
+// CanBeAcceptedBy checks that the invitation is still open and was addressed
+// to the given user
+func (i *Invitation) CanBeAcceptedBy(user *User, now time.Time) error {
+	if i.AcceptedAt != nil {
+		return ErrInvitationAccepted
+	}
+	if !now.Before(i.ExpiresAt) {
+		return ErrInvitationExpired
+	}
+	if (i.Email != "" && strings.EqualFold(i.Email, user.Email)) || (i.Phone != "" && i.Phone == user.Phone) {
+		return nil
+	}
+	return ErrInvitationRecipient
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying the current organization ID
+func WithTenant(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, organizationID)
+}
+
+// TenantFromContext returns the organization ID carried by ctx
+func TenantFromContext(ctx context.Context) (string, error) {
+	id, ok := ctx.Value(tenantContextKey{}).(string)
+	if !ok || id == "" {
+		return "", ErrNoTenant
+	}
+	return id, nil
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewOrganization(t *testing.T) {
+	tests := []struct {
+		name    string
+		orgName string
+		slug    string
+		wantErr error
+	}{
+		{name: "Valid", orgName: "Acme", slug: "acme-co", wantErr: nil},
+		{name: "Slug Is Lowercased", orgName: "Acme", slug: "Acme", wantErr: nil},
+		{name: "Empty Name", orgName: " ", slug: "acme", wantErr: ErrInvalidOrganization},
+		{name: "Slug With Spaces", orgName: "Acme", slug: "acme co", wantErr: ErrInvalidSlug},
+		{name: "Slug Ending In Dash", orgName: "Acme", slug: "acme-", wantErr: ErrInvalidSlug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOrganization(tt.orgName, tt.slug, "u1")
+			if err != tt.wantErr {
+				t.Errorf("NewOrganization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvitationCanBeAcceptedBy(t *testing.T) {
+	now := time.Now()
+	accepted := now.Add(-time.Minute)
+	user := &User{Email: "jane@example.com", Phone: "09123456789"}
+
+	tests := []struct {
+		name       string
+		invitation Invitation
+		wantErr    error
+	}{
+		{name: "Matching Email", invitation: Invitation{Email: "Jane@example.com", ExpiresAt: now.Add(time.Hour)}, wantErr: nil},
+		{name: "Matching Phone", invitation: Invitation{Phone: "09123456789", ExpiresAt: now.Add(time.Hour)}, wantErr: nil},
+		{name: "Other Recipient", invitation: Invitation{Email: "john@example.com", ExpiresAt: now.Add(time.Hour)}, wantErr: ErrInvitationRecipient},
+		{name: "Expired", invitation: Invitation{Email: "jane@example.com", ExpiresAt: now}, wantErr: ErrInvitationExpired},
+		{name: "Already Accepted", invitation: Invitation{Email: "jane@example.com", ExpiresAt: now.Add(time.Hour), AcceptedAt: &accepted}, wantErr: ErrInvitationAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.invitation.CanBeAcceptedBy(user, now); err != tt.wantErr {
+				t.Errorf("CanBeAcceptedBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTenantContext(t *testing.T) {
+	if _, err := TenantFromContext(context.Background()); err != ErrNoTenant {
+		t.Errorf("TenantFromContext() error = %v, want %v", err, ErrNoTenant)
+	}
+
+	id, err := TenantFromContext(WithTenant(context.Background(), "org-1"))
+	if err != nil || id != "org-1" {
+		t.Errorf("TenantFromContext() = %q, %v, want org-1", id, err)
+	}
+}
+
+func TestIsOrgRole(t *testing.T) {
+	tests := []struct {
+		role string
+		want bool
+	}{
+		{role: RoleOrgOwner, want: true},
+		{role: RoleOrgMember, want: true},
+		{role: RoleAdmin, want: false},
+		{role: "seller", want: false},
+		{role: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			if got := IsOrgRole(tt.role); got != tt.want {
+				t.Errorf("IsOrgRole(%q) = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidAmount           = errors.New("invalid amount")
+	ErrInvalidPaymentState     = errors.New("invalid payment state transition")
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrPaymentConcurrentUpdate = errors.New("payment was modified concurrently")
+	ErrGatewayUnavailable      = errors.New("payment gateway is not available")
+	ErrPaymentReferenceUsed    = errors.New("gateway reference already settled another payment")
+	ErrInvalidRefundAmount     = errors.New("refund amount exceeds the refundable amount")
+)
+
+// PaymentStatus is a state in the payment lifecycle
+type PaymentStatus string
+
+const (
+	// PaymentCreated is a stored payment that has not reached a gateway yet
+	PaymentCreated PaymentStatus = "created"
+	// PaymentRedirected is a payment the user was sent to the gateway for
+	PaymentRedirected PaymentStatus = "redirected"
+	// PaymentPending awaits an out-of-band decision, e.g. card-to-card review
+	PaymentPending   PaymentStatus = "pending"
+	PaymentVerified  PaymentStatus = "verified"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentCancelled PaymentStatus = "cancelled"
+	PaymentRefunded  PaymentStatus = "refunded"
+	// PaymentPartiallyRefunded had part of its amount returned
+	PaymentPartiallyRefunded PaymentStatus = "partially_refunded"
+	// PaymentExpired was never settled at the gateway within the allowed time
+	PaymentExpired PaymentStatus = "expired"
+	// PaymentOnHold was paid at the gateway but is held for risk review; it
+	// is released to verified or refunded
+	PaymentOnHold PaymentStatus = "on_hold"
+)
+
+// paymentTransitions lists the states reachable from each state
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentCreated:    {PaymentRedirected, PaymentPending, PaymentFailed, PaymentCancelled},
+	PaymentRedirected: {PaymentVerified, PaymentOnHold, PaymentFailed, PaymentCancelled, PaymentExpired},
+	PaymentPending:    {PaymentVerified, PaymentFailed, PaymentCancelled, PaymentExpired},
+	PaymentVerified:   {PaymentRefunded, PaymentPartiallyRefunded},
+	PaymentOnHold:     {PaymentVerified, PaymentRefunded, PaymentPartiallyRefunded},
+	// Further partial refunds keep the payment partially refunded
+	PaymentPartiallyRefunded: {PaymentPartiallyRefunded, PaymentRefunded},
+}
+
+// CanTransitionTo reports whether the state machine allows moving to next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no further transitions are possible
+func (s PaymentStatus) IsFinal() bool {
+	return len(paymentTransitions[s]) == 0
+}
+
+// Payment is a single attempt to collect money through a gateway
+type Payment struct {
+	ID     string
+	UserID string
+	// OrganizationID is the tenant the payment was started in, if any; its
+	// webhook endpoints are told about the payment
+	OrganizationID string
+	Gateway        string
+	// Amount is in Rials
+	Amount      int64
+	Description string
+	// OrderID and Metadata are the caller's references for the payment
+	OrderID  string
+	Metadata map[string]string
+	// Authority is the gateway's identifier for the attempt (authority,
+	// token or transaction ID depending on the gateway)
+	Authority string
+	// RefID is the gateway's reference number after verification
+	RefID string
+	// CardPan is the masked card number and CardHash the gateway's hash of
+	// it, as reported on verification
+	CardPan  string
+	CardHash string
+	// ClientIP is the address the payment was started from, when known
+	ClientIP string
+	// Risk is what the risk checks decided when the payment was started and
+	// verified
+	Risk RiskDecision
+	// CouponCode is the coupon the payment was discounted with and Discount
+	// what it took off, in Rials; Amount is what is left to pay
+	CouponCode string
+	Discount   int64
+	// RefundedAmount is the part of Amount returned so far, in Rials
+	RefundedAmount int64
+	Status         PaymentStatus
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PaymentPurposeKey is the metadata key recording what the application
+// started a payment for. Clients cannot set it.
+const PaymentPurposeKey = "purpose"
+
+// PurposeWalletTopUp marks payments started to top up the payer's wallet
+const PurposeWalletTopUp = "wallet_topup"
+
+// IsWalletTopUp reports whether the payment was started as a wallet top-up
+func (p *Payment) IsWalletTopUp() bool {
+	return p.Metadata[PaymentPurposeKey] == PurposeWalletTopUp
+}
+
+func NewPayment(userID, gateway string, amount int64, description string) (*Payment, error) {
+	if err := Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Payment{
+		UserID:      userID,
+		Gateway:     gateway,
+		Amount:      amount,
+		Description: description,
+		Status:      PaymentCreated,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// TransitionTo moves the payment to next, rejecting moves the state machine
+// does not allow
+func (p *Payment) TransitionTo(next PaymentStatus) error {
+	if !p.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidPaymentState, p.Status, next)
+	}
+	p.Status = next
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Refundable returns the amount that can still be refunded
+func (p *Payment) Refundable() int64 {
+	if p.Status != PaymentVerified && p.Status != PaymentPartiallyRefunded && p.Status != PaymentOnHold {
+		return 0
+	}
+	return p.Amount - p.RefundedAmount
+}
+
+// ApplyRefund records a completed refund of amount, moving the payment to
+// refunded once nothing is left
+func (p *Payment) ApplyRefund(amount int64) error {
+	if err := Rials(amount).Validate(); err != nil {
+		return err
+	}
+	if amount > p.Refundable() {
+		return ErrInvalidRefundAmount
+	}
+
+	next := PaymentPartiallyRefunded
+	if p.RefundedAmount+amount == p.Amount {
+		next = PaymentRefunded
+	}
+	if err := p.TransitionTo(next); err != nil {
+		return err
+	}
+	p.RefundedAmount += amount
+	return nil
+}
+
+// PaymentEvent records a status change together with the raw gateway
+// response that caused it
+type PaymentEvent struct {
+	ID          string
+	PaymentID   string
+	FromStatus  PaymentStatus
+	ToStatus    PaymentStatus
+	RawResponse []byte
+	Note        string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPaymentReceiptNotFound    = errors.New("receipt not found")
+	ErrPaymentReceiptExists      = errors.New("payment already has a receipt")
+	ErrPaymentReceiptUnavailable = errors.New("receipts are only issued for verified payments")
+)
+
+// PaymentReceipt is the official invoice issued for a verified payment.
+// Receipts are numbered without gaps within a series, one series per Jalali
+// year.
+type PaymentReceipt struct {
+	ID        string
+	PaymentID string
+	UserID    string
+	// Series is the Jalali year the receipt was issued in and Sequence its
+	// number within the series, assigned when the receipt is stored
+	Series   string
+	Sequence int64
+	// Amount is in Rials
+	Amount      int64
+	Description string
+	Gateway     string
+	RefID       string
+	CardPan     string
+	PaidAt      time.Time
+	// FileKey is where the rendered PDF is stored, empty until it is
+	FileKey  string
+	IssuedAt time.Time
+}
+
+// NewPaymentReceipt prepares the receipt of a verified payment in series.
+// Payments refunded in part still get one; fully refunded ones were never
+// settled from the accountants' point of view and get none.
+func NewPaymentReceipt(payment *Payment, series string, paidAt time.Time) (*PaymentReceipt, error) {
+	if payment.Status != PaymentVerified && payment.Status != PaymentPartiallyRefunded {
+		return nil, fmt.Errorf("%w: payment is %s", ErrPaymentReceiptUnavailable, payment.Status)
+	}
+	if series == "" {
+		return nil, errors.New("receipt series is required")
+	}
+
+	return &PaymentReceipt{
+		PaymentID:   payment.ID,
+		UserID:      payment.UserID,
+		Series:      series,
+		Amount:      payment.Amount,
+		Description: payment.Description,
+		Gateway:     payment.Gateway,
+		RefID:       payment.RefID,
+		CardPan:     payment.CardPan,
+		PaidAt:      paidAt,
+		IssuedAt:    time.Now(),
+	}, nil
+}
+
+// Number is the receipt's printed number, e.g. 1403-000042
+func (r *PaymentReceipt) Number() string {
+	return fmt.Sprintf("%s-%06d", r.Series, r.Sequence)
+}
+
+// FileName is the name the PDF is downloaded as
+func (r *PaymentReceipt) FileName() string {
+	return "receipt-" + r.Number() + ".pdf"
+}
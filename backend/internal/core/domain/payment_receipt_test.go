@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPaymentReceipt(t *testing.T) {
+	paidAt := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		status  PaymentStatus
+		wantErr error
+	}{
+		{name: "Verified", status: PaymentVerified},
+		{name: "Partially Refunded", status: PaymentPartiallyRefunded},
+		{name: "Refunded", status: PaymentRefunded, wantErr: ErrPaymentReceiptUnavailable},
+		{name: "On Hold", status: PaymentOnHold, wantErr: ErrPaymentReceiptUnavailable},
+		{name: "Redirected", status: PaymentRedirected, wantErr: ErrPaymentReceiptUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{ID: "p1", UserID: "u1", Gateway: "zarinpal", Amount: 1_250_000, RefID: "201", Status: tt.status}
+
+			receipt, err := NewPaymentReceipt(payment, "1403", paidAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPaymentReceipt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (receipt.Amount != payment.Amount || receipt.RefID != "201" || !receipt.PaidAt.Equal(paidAt)) {
+				t.Errorf("NewPaymentReceipt() = %+v, want the payment's details", receipt)
+			}
+		})
+	}
+}
+
+func TestPaymentReceiptNumber(t *testing.T) {
+	receipt := &PaymentReceipt{Series: "1403", Sequence: 42}
+
+	if got := receipt.Number(); got != "1403-000042" {
+		t.Errorf("Number() = %q, want %q", got, "1403-000042")
+	}
+	if got := receipt.FileName(); got != "receipt-1403-000042.pdf" {
+		t.Errorf("FileName() = %q, want %q", got, "receipt-1403-000042.pdf")
+	}
+}
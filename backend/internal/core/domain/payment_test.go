@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		wantErr error
+	}{
+		{name: "Valid Amount", amount: 10000, wantErr: nil},
+		{name: "Zero Amount", amount: 0, wantErr: ErrInvalidAmount},
+		{name: "Negative Amount", amount: -5, wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPayment("u1", "zarinpal", tt.amount, "")
+			if err != tt.wantErr {
+				t.Errorf("NewPayment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p.Status != PaymentCreated {
+				t.Errorf("NewPayment() status = %v, want %v", p.Status, PaymentCreated)
+			}
+		})
+	}
+}
+
+func TestPaymentTransitionTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    PaymentStatus
+		to      PaymentStatus
+		wantErr bool
+	}{
+		{name: "Created To Redirected", from: PaymentCreated, to: PaymentRedirected},
+		{name: "Created To Pending", from: PaymentCreated, to: PaymentPending},
+		{name: "Redirected To Verified", from: PaymentRedirected, to: PaymentVerified},
+		{name: "Redirected To Cancelled", from: PaymentRedirected, to: PaymentCancelled},
+		{name: "Pending To Failed", from: PaymentPending, to: PaymentFailed},
+		{name: "Verified To Refunded", from: PaymentVerified, to: PaymentRefunded},
+		{name: "Redirected To Expired", from: PaymentRedirected, to: PaymentExpired},
+		{name: "Redirected To On Hold", from: PaymentRedirected, to: PaymentOnHold},
+		{name: "On Hold To Verified", from: PaymentOnHold, to: PaymentVerified},
+		{name: "On Hold To Failed", from: PaymentOnHold, to: PaymentFailed, wantErr: true},
+		{name: "Pending To On Hold", from: PaymentPending, to: PaymentOnHold, wantErr: true},
+		{name: "Expired To Verified", from: PaymentExpired, to: PaymentVerified, wantErr: true},
+		{name: "Created To Verified", from: PaymentCreated, to: PaymentVerified, wantErr: true},
+		{name: "Failed To Verified", from: PaymentFailed, to: PaymentVerified, wantErr: true},
+		{name: "Verified To Failed", from: PaymentVerified, to: PaymentFailed, wantErr: true},
+		{name: "Refunded To Verified", from: PaymentRefunded, to: PaymentVerified, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Payment{Status: tt.from}
+			err := p.TransitionTo(tt.to)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPaymentState) {
+					t.Errorf("TransitionTo() error = %v, want ErrInvalidPaymentState", err)
+				}
+				if p.Status != tt.from {
+					t.Errorf("status changed to %v on rejected transition", p.Status)
+				}
+				return
+			}
+			if err != nil || p.Status != tt.to {
+				t.Errorf("TransitionTo() = %v, status %v, want %v", err, p.Status, tt.to)
+			}
+		})
+	}
+}
+
+func TestPaymentApplyRefund(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       PaymentStatus
+		refunded     int64
+		amount       int64
+		wantStatus   PaymentStatus
+		wantRefunded int64
+		wantErr      error
+	}{
+		{name: "Partial Refund", status: PaymentVerified, amount: 4000, wantStatus: PaymentPartiallyRefunded, wantRefunded: 4000},
+		{name: "Full Refund", status: PaymentVerified, amount: 10000, wantStatus: PaymentRefunded, wantRefunded: 10000},
+		{name: "Remaining Refund", status: PaymentPartiallyRefunded, refunded: 4000, amount: 6000, wantStatus: PaymentRefunded, wantRefunded: 10000},
+		{name: "Second Partial Refund", status: PaymentPartiallyRefunded, refunded: 4000, amount: 1000, wantStatus: PaymentPartiallyRefunded, wantRefunded: 5000},
+		{name: "Exceeds Amount", status: PaymentPartiallyRefunded, refunded: 4000, amount: 7000, wantErr: ErrInvalidRefundAmount},
+		{name: "Zero Amount", status: PaymentVerified, amount: 0, wantErr: ErrInvalidAmount},
+		{name: "Held Payment Refund", status: PaymentOnHold, amount: 10000, wantStatus: PaymentRefunded, wantRefunded: 10000},
+		{name: "Not Verified", status: PaymentRedirected, amount: 1000, wantErr: ErrInvalidRefundAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Payment{Amount: 10000, RefundedAmount: tt.refunded, Status: tt.status}
+			err := p.ApplyRefund(tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyRefund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if p.Status != tt.status || p.RefundedAmount != tt.refunded {
+					t.Errorf("payment changed on rejected refund: %v %d", p.Status, p.RefundedAmount)
+				}
+				return
+			}
+			if p.Status != tt.wantStatus || p.RefundedAmount != tt.wantRefunded {
+				t.Errorf("ApplyRefund() = %v %d, want %v %d", p.Status, p.RefundedAmount, tt.wantStatus, tt.wantRefunded)
+			}
+		})
+	}
+}
+
+func TestPaymentIsWalletTopUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{name: "Top-Up", metadata: map[string]string{PaymentPurposeKey: PurposeWalletTopUp}, want: true},
+		{name: "Other Purpose", metadata: map[string]string{PaymentPurposeKey: "donation"}},
+		{name: "No Metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Payment{Metadata: tt.metadata}
+			if got := p.IsWalletTopUp(); got != tt.want {
+				t.Errorf("IsWalletTopUp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPayoutNotFound     = errors.New("payout not found")
+	ErrInvalidPayout      = errors.New("invalid payout")
+	ErrInvalidPayoutState = errors.New("invalid payout state transition")
+	ErrPayoutConflict     = errors.New("payout was modified concurrently")
+	// ErrPayoutSelfApproval keeps a reviewer from approving their own payout
+	ErrPayoutSelfApproval = errors.New("payouts cannot be approved by their requester")
+)
+
+// MinPayoutAmount is the smallest payout accepted, in Rials
+const MinPayoutAmount int64 = 100_000
+
+// PayoutStatus is a state in the payout lifecycle
+type PayoutStatus string
+
+const (
+	// PayoutRequested waits for approval; the amount is held in the ledger
+	PayoutRequested PayoutStatus = "requested"
+	// PayoutApproved waits to be sent to the bank
+	PayoutApproved PayoutStatus = "approved"
+	// PayoutProcessing was sent to a payout gateway or exported in a bank
+	// batch file and waits for the transfer to complete
+	PayoutProcessing PayoutStatus = "processing"
+	PayoutPaid       PayoutStatus = "paid"
+	// PayoutFailed, PayoutRejected and PayoutCancelled return the held
+	// amount to the wallet
+	PayoutFailed    PayoutStatus = "failed"
+	PayoutRejected  PayoutStatus = "rejected"
+	PayoutCancelled PayoutStatus = "cancelled"
+)
+
+// IsFinal reports whether no transition leaves the status
+func (s PayoutStatus) IsFinal() bool {
+	return s == PayoutPaid || s == PayoutFailed || s == PayoutRejected || s == PayoutCancelled
+}
+
+// payoutTransitions lists the statuses each payout status may move to
+var payoutTransitions = map[PayoutStatus][]PayoutStatus{
+	PayoutRequested:  {PayoutApproved, PayoutRejected, PayoutCancelled},
+	PayoutApproved:   {PayoutProcessing, PayoutRejected},
+	PayoutProcessing: {PayoutPaid, PayoutFailed},
+}
+
+// Payout sends money from a user's wallet to their bank account
+type Payout struct {
+	ID     string
+	UserID string
+	// Amount is in Rials
+	Amount int64
+	// Sheba is the destination IBAN, normalized; BankCode is derived from it
+	Sheba       string
+	BankCode    string
+	OwnerName   string
+	Description string
+	Status      PayoutStatus
+	// Gateway is the payout gateway that sent the payout and BatchID the
+	// dispatch it was sent in
+	Gateway string
+	BatchID string
+	// Reference is the gateway's transaction ID or the bank's tracking
+	// number of the transfer
+	Reference  string
+	ApprovedBy string
+	// ProcessedBy is the reviewer who rejected the payout or confirmed its
+	// outcome by hand
+	ProcessedBy string
+	// Note explains a rejection or failure
+	Note      string
+	PaidAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewPayout(userID string, amount int64, sheba, ownerName, description string) (*Payout, error) {
+	if err := Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+	if amount < MinPayoutAmount {
+		return nil, fmt.Errorf("%w: amount must be at least %d Rials", ErrInvalidPayout, MinPayoutAmount)
+	}
+
+	sheba = NormalizeSheba(sheba)
+	if err := ValidateSheba(sheba); err != nil {
+		return nil, err
+	}
+	ownerName = strings.TrimSpace(ownerName)
+	if ownerName == "" {
+		return nil, fmt.Errorf("%w: account owner name is required", ErrInvalidPayout)
+	}
+	bank, _ := BankFromSheba(sheba)
+
+	now := time.Now()
+	return &Payout{
+		UserID:      userID,
+		Amount:      amount,
+		Sheba:       sheba,
+		BankCode:    bank.Code,
+		OwnerName:   ownerName,
+		Description: strings.TrimSpace(description),
+		Status:      PayoutRequested,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Bank returns the bank the payout is sent to
+func (p *Payout) Bank() Bank {
+	bank, _ := BankFromSheba(p.Sheba)
+	return bank
+}
+
+// Approve lets a requested payout be sent. Reviewers cannot approve their
+// own payouts.
+func (p *Payout) Approve(reviewerID string) error {
+	if reviewerID == p.UserID {
+		return ErrPayoutSelfApproval
+	}
+	if err := p.transition(PayoutApproved); err != nil {
+		return err
+	}
+	p.ApprovedBy = reviewerID
+	return nil
+}
+
+// Reject declines a payout that was not sent yet
+func (p *Payout) Reject(reviewerID, note string) error {
+	if err := p.transition(PayoutRejected); err != nil {
+		return err
+	}
+	p.ProcessedBy = reviewerID
+	p.Note = note
+	return nil
+}
+
+// Cancel withdraws a payout its requester no longer wants
+func (p *Payout) Cancel() error {
+	return p.transition(PayoutCancelled)
+}
+
+// StartProcessing records that an approved payout is being sent through
+// gateway in batch batchID
+func (p *Payout) StartProcessing(gateway, batchID string) error {
+	if err := p.transition(PayoutProcessing); err != nil {
+		return err
+	}
+	p.Gateway = gateway
+	p.BatchID = batchID
+	return nil
+}
+
+// MarkPaid records a completed transfer. processedBy is empty when the
+// gateway reported it.
+func (p *Payout) MarkPaid(reference, processedBy string) error {
+	if err := p.transition(PayoutPaid); err != nil {
+		return err
+	}
+	if reference != "" {
+		p.Reference = reference
+	}
+	p.ProcessedBy = processedBy
+	p.PaidAt = &p.UpdatedAt
+	return nil
+}
+
+// MarkFailed records a transfer the bank or gateway refused
+func (p *Payout) MarkFailed(note, processedBy string) error {
+	if err := p.transition(PayoutFailed); err != nil {
+		return err
+	}
+	p.ProcessedBy = processedBy
+	p.Note = note
+	return nil
+}
+
+func (p *Payout) transition(to PayoutStatus) error {
+	for _, allowed := range payoutTransitions[p.Status] {
+		if allowed == to {
+			p.Status = to
+			p.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidPayoutState, p.Status, to)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewPayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		sheba     string
+		ownerName string
+		wantBank  string
+		wantErr   error
+	}{
+		{name: "Valid", amount: 500_000, sheba: "ir82 0540 1026 8002 0817 9090 02", ownerName: " Ali Rezaei ", wantBank: "054"},
+		{name: "Minimum", amount: MinPayoutAmount, sheba: "IR590170000000100326276001", ownerName: "Sara", wantBank: "017"},
+		{name: "Below Minimum", amount: MinPayoutAmount - 1, sheba: "IR590170000000100326276001", ownerName: "Sara", wantErr: ErrInvalidPayout},
+		{name: "Zero Amount", amount: 0, sheba: "IR590170000000100326276001", ownerName: "Sara", wantErr: ErrInvalidAmount},
+		{name: "Bad Checksum", amount: 500_000, sheba: "IR830540102680020817909002", ownerName: "Sara", wantErr: ErrInvalidSheba},
+		{name: "No Owner", amount: 500_000, sheba: "IR590170000000100326276001", ownerName: " ", wantErr: ErrInvalidPayout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPayout("u1", tt.amount, tt.sheba, tt.ownerName, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Status != PayoutRequested || p.BankCode != tt.wantBank {
+				t.Errorf("NewPayout() status = %v, bank = %q, want %v, %q", p.Status, p.BankCode, PayoutRequested, tt.wantBank)
+			}
+			if p.Sheba != NormalizeSheba(tt.sheba) || p.OwnerName != strings.TrimSpace(tt.ownerName) {
+				t.Errorf("NewPayout() sheba = %q, owner = %q, want them normalized", p.Sheba, p.OwnerName)
+			}
+		})
+	}
+}
+
+func TestPayoutTransitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       PayoutStatus
+		apply      func(p *Payout) error
+		wantStatus PayoutStatus
+		wantErr    error
+	}{
+		{name: "Approve", from: PayoutRequested, apply: func(p *Payout) error { return p.Approve("admin") }, wantStatus: PayoutApproved},
+		{name: "Approve Own", from: PayoutRequested, apply: func(p *Payout) error { return p.Approve("u1") }, wantErr: ErrPayoutSelfApproval},
+		{name: "Approve Twice", from: PayoutApproved, apply: func(p *Payout) error { return p.Approve("admin") }, wantErr: ErrInvalidPayoutState},
+		{name: "Reject Requested", from: PayoutRequested, apply: func(p *Payout) error { return p.Reject("admin", "no") }, wantStatus: PayoutRejected},
+		{name: "Reject Approved", from: PayoutApproved, apply: func(p *Payout) error { return p.Reject("admin", "no") }, wantStatus: PayoutRejected},
+		{name: "Reject Processing", from: PayoutProcessing, apply: func(p *Payout) error { return p.Reject("admin", "no") }, wantErr: ErrInvalidPayoutState},
+		{name: "Cancel Requested", from: PayoutRequested, apply: func(p *Payout) error { return p.Cancel() }, wantStatus: PayoutCancelled},
+		{name: "Cancel Approved", from: PayoutApproved, apply: func(p *Payout) error { return p.Cancel() }, wantErr: ErrInvalidPayoutState},
+		{name: "Process Approved", from: PayoutApproved, apply: func(p *Payout) error { return p.StartProcessing("manual", "b1") }, wantStatus: PayoutProcessing},
+		{name: "Process Requested", from: PayoutRequested, apply: func(p *Payout) error { return p.StartProcessing("manual", "b1") }, wantErr: ErrInvalidPayoutState},
+		{name: "Pay Processing", from: PayoutProcessing, apply: func(p *Payout) error { return p.MarkPaid("ref", "") }, wantStatus: PayoutPaid},
+		{name: "Pay Approved", from: PayoutApproved, apply: func(p *Payout) error { return p.MarkPaid("ref", "admin") }, wantErr: ErrInvalidPayoutState},
+		{name: "Fail Processing", from: PayoutProcessing, apply: func(p *Payout) error { return p.MarkFailed("returned", "admin") }, wantStatus: PayoutFailed},
+		{name: "Fail Paid", from: PayoutPaid, apply: func(p *Payout) error { return p.MarkFailed("returned", "admin") }, wantErr: ErrInvalidPayoutState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Payout{UserID: "u1", Status: tt.from}
+			err := tt.apply(p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if p.Status != tt.from {
+					t.Errorf("status = %v after failed transition, want %v", p.Status, tt.from)
+				}
+				return
+			}
+			if p.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", p.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPayoutMarkPaid(t *testing.T) {
+	p := &Payout{Status: PayoutProcessing, Reference: "settlement-1"}
+	if err := p.MarkPaid("", ""); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if p.Reference != "settlement-1" {
+		t.Errorf("Reference = %q, want the gateway's kept", p.Reference)
+	}
+	if p.PaidAt == nil || !p.PaidAt.Equal(p.UpdatedAt) {
+		t.Errorf("PaidAt = %v, want %v", p.PaidAt, p.UpdatedAt)
+	}
+}
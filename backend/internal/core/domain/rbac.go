@@ -24,6 +24,41 @@ type Permission struct {
 	CreatedAt   time.Time
 }
 
+// ScopeGlobal is the scope of a role grant made outside any organization
+const ScopeGlobal = ""
+
+// UserRole represents the assignment of a role to a user, optionally limited
+// to a scope (tenant or project ID) and a validity window
+type UserRole struct {
+	UserID    string
+	RoleID    string
+	RoleName  string
+	Scope     string
+	GrantedBy string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired reports whether the grant has expired at the given time
+func (ur *UserRole) IsExpired(now time.Time) bool {
+	return ur.ExpiresAt != nil && !ur.ExpiresAt.After(now)
+}
+
+// IsGlobal reports whether the grant is made outside any scope. Global
+// grants only apply to global checks, not inside a scope.
+func (ur *UserRole) IsGlobal() bool {
+	return ur.Scope == ScopeGlobal
+}
+
+// RoleGrantFilter narrows down a listing of role grants
+type RoleGrantFilter struct {
+	UserID         string
+	RoleID         string
+	GrantedBy      string
+	Scope          *string
+	IncludeExpired bool
+}
+
 // Common role names
 const (
 	RoleAdmin     = "admin"
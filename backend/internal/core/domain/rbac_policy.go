@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidPolicy = errors.New("invalid rbac policy")
+)
+
+// PermissionWildcard grants every declared permission to a role
+const PermissionWildcard = "*"
+
+// RBACPolicy is the declarative description of roles, permissions and
+// their mappings that the database is reconciled against
+type RBACPolicy struct {
+	Permissions []PolicyPermission
+	Roles       []PolicyRole
+}
+
+// PolicyPermission declares a single permission
+type PolicyPermission struct {
+	Name        string
+	Description string
+	Resource    string
+	Action      string
+}
+
+// PolicyRole declares a role and the permissions mapped to it
+type PolicyRole struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// Validate checks the policy for duplicates and dangling references and
+// fills in resource/action from "resource:action" permission names
+func (p *RBACPolicy) Validate() error {
+	perms := make(map[string]bool, len(p.Permissions))
+	for i := range p.Permissions {
+		perm := &p.Permissions[i]
+		if perm.Name == "" {
+			return fmt.Errorf("%w: permission without a name", ErrInvalidPolicy)
+		}
+		if perms[perm.Name] {
+			return fmt.Errorf("%w: duplicate permission %q", ErrInvalidPolicy, perm.Name)
+		}
+		perms[perm.Name] = true
+
+		if perm.Resource == "" || perm.Action == "" {
+			resource, action, ok := strings.Cut(perm.Name, ":")
+			if !ok {
+				return fmt.Errorf("%w: permission %q needs resource and action", ErrInvalidPolicy, perm.Name)
+			}
+			if perm.Resource == "" {
+				perm.Resource = resource
+			}
+			if perm.Action == "" {
+				perm.Action = action
+			}
+		}
+	}
+
+	roles := make(map[string]bool, len(p.Roles))
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("%w: role without a name", ErrInvalidPolicy)
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("%w: duplicate role %q", ErrInvalidPolicy, role.Name)
+		}
+		roles[role.Name] = true
+
+		for _, name := range role.Permissions {
+			if name != PermissionWildcard && !perms[name] {
+				return fmt.Errorf("%w: role %q references undeclared permission %q", ErrInvalidPolicy, role.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RolePermissionNames returns the expanded permission names of a role
+func (p *RBACPolicy) RolePermissionNames(role PolicyRole) []string {
+	for _, name := range role.Permissions {
+		if name == PermissionWildcard {
+			all := make([]string, 0, len(p.Permissions))
+			for _, perm := range p.Permissions {
+				all = append(all, perm.Name)
+			}
+			return all
+		}
+	}
+	return role.Permissions
+}
+
+// RBACChangeKind identifies a single reconciliation step
+type RBACChangeKind string
+
+const (
+	ChangeCreatePermission RBACChangeKind = "create_permission"
+	ChangeUpdatePermission RBACChangeKind = "update_permission"
+	ChangeCreateRole       RBACChangeKind = "create_role"
+	ChangeUpdateRole       RBACChangeKind = "update_role"
+	ChangeGrantPermission  RBACChangeKind = "grant_permission"
+	ChangeRevokePermission RBACChangeKind = "revoke_permission"
+	ChangeDeleteRole       RBACChangeKind = "delete_role"
+	ChangeDeletePermission RBACChangeKind = "delete_permission"
+)
+
+// changeOrder is the order in which changes must be applied
+var changeOrder = map[RBACChangeKind]int{
+	ChangeCreatePermission: 0,
+	ChangeUpdatePermission: 1,
+	ChangeCreateRole:       2,
+	ChangeUpdateRole:       3,
+	ChangeGrantPermission:  4,
+	ChangeRevokePermission: 5,
+	ChangeDeleteRole:       6,
+	ChangeDeletePermission: 7,
+}
+
+// RBACChange is one step needed to bring the database in line with a policy
+type RBACChange struct {
+	Kind        RBACChangeKind
+	Role        string
+	Permission  string
+	Description string
+	Resource    string
+	Action      string
+	// References counts the roles outside the policy holding a permission,
+	// or the users holding a role, that is about to be deleted
+	References int
+}
+
+// Blocked reports whether the change deletes something still in use
+func (c RBACChange) Blocked() bool {
+	return (c.Kind == ChangeDeleteRole || c.Kind == ChangeDeletePermission) && c.References > 0
+}
+
+func (c RBACChange) String() string {
+	var line string
+	switch c.Kind {
+	case ChangeCreatePermission:
+		line = fmt.Sprintf("+ permission %s", c.Permission)
+	case ChangeUpdatePermission:
+		line = fmt.Sprintf("~ permission %s", c.Permission)
+	case ChangeDeletePermission:
+		line = fmt.Sprintf("- permission %s", c.Permission)
+	case ChangeCreateRole:
+		line = fmt.Sprintf("+ role %s", c.Role)
+	case ChangeUpdateRole:
+		line = fmt.Sprintf("~ role %s", c.Role)
+	case ChangeDeleteRole:
+		line = fmt.Sprintf("- role %s", c.Role)
+	case ChangeGrantPermission:
+		line = fmt.Sprintf("+ %s -> %s", c.Role, c.Permission)
+	case ChangeRevokePermission:
+		line = fmt.Sprintf("- %s -> %s", c.Role, c.Permission)
+	}
+	if c.Blocked() {
+		line += fmt.Sprintf(" (blocked: %d references)", c.References)
+	}
+	return line
+}
+
+// RBACPolicyDiff is the ordered list of changes produced by DiffRBACPolicy
+type RBACPolicyDiff struct {
+	Changes []RBACChange
+}
+
+// IsEmpty reports whether the database already matches the policy
+func (d *RBACPolicyDiff) IsEmpty() bool {
+	return len(d.Changes) == 0
+}
+
+// Blocked returns the changes that require force to apply
+func (d *RBACPolicyDiff) Blocked() []RBACChange {
+	var blocked []RBACChange
+	for _, c := range d.Changes {
+		if c.Blocked() {
+			blocked = append(blocked, c)
+		}
+	}
+	return blocked
+}
+
+func (d *RBACPolicyDiff) String() string {
+	if d.IsEmpty() {
+		return "no changes"
+	}
+	lines := make([]string, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffRBACPolicy computes the changes needed to turn the current state into
+// the declared policy. Roles must carry their permissions, and roleUsers maps
+// role names to the number of users holding them.
+func DiffRBACPolicy(policy *RBACPolicy, roles []Role, permissions []Permission, roleUsers map[string]int) *RBACPolicyDiff {
+	diff := &RBACPolicyDiff{}
+
+	currentPerms := make(map[string]Permission, len(permissions))
+	for _, perm := range permissions {
+		currentPerms[perm.Name] = perm
+	}
+	currentRoles := make(map[string]Role, len(roles))
+	for _, role := range roles {
+		currentRoles[role.Name] = role
+	}
+
+	declaredRoles := make(map[string]bool, len(policy.Roles))
+	for _, role := range policy.Roles {
+		declaredRoles[role.Name] = true
+	}
+	// Declared roles lose undeclared permissions in the same sync, so only
+	// roles outside the policy keep a deleted permission in use
+	permRoles := make(map[string]int)
+	for _, role := range roles {
+		if declaredRoles[role.Name] {
+			continue
+		}
+		for _, perm := range role.Permissions {
+			permRoles[perm.Name]++
+		}
+	}
+
+	declaredPerms := make(map[string]bool, len(policy.Permissions))
+	for _, perm := range policy.Permissions {
+		declaredPerms[perm.Name] = true
+		change := RBACChange{
+			Permission:  perm.Name,
+			Description: perm.Description,
+			Resource:    perm.Resource,
+			Action:      perm.Action,
+		}
+		current, ok := currentPerms[perm.Name]
+		switch {
+		case !ok:
+			change.Kind = ChangeCreatePermission
+		case current.Description != perm.Description || current.Resource != perm.Resource || current.Action != perm.Action:
+			change.Kind = ChangeUpdatePermission
+		default:
+			continue
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+	for _, perm := range permissions {
+		if !declaredPerms[perm.Name] {
+			diff.Changes = append(diff.Changes, RBACChange{
+				Kind:       ChangeDeletePermission,
+				Permission: perm.Name,
+				References: permRoles[perm.Name],
+			})
+		}
+	}
+
+	for _, role := range policy.Roles {
+		current, ok := currentRoles[role.Name]
+		if !ok {
+			diff.Changes = append(diff.Changes, RBACChange{Kind: ChangeCreateRole, Role: role.Name, Description: role.Description})
+		} else if current.Description != role.Description {
+			diff.Changes = append(diff.Changes, RBACChange{Kind: ChangeUpdateRole, Role: role.Name, Description: role.Description})
+		}
+
+		wanted := make(map[string]bool)
+		for _, name := range policy.RolePermissionNames(role) {
+			wanted[name] = true
+			if !current.HasPermission(name) {
+				diff.Changes = append(diff.Changes, RBACChange{Kind: ChangeGrantPermission, Role: role.Name, Permission: name})
+			}
+		}
+		for _, perm := range current.Permissions {
+			if !wanted[perm.Name] {
+				diff.Changes = append(diff.Changes, RBACChange{Kind: ChangeRevokePermission, Role: role.Name, Permission: perm.Name})
+			}
+		}
+	}
+	for _, role := range roles {
+		if !declaredRoles[role.Name] {
+			diff.Changes = append(diff.Changes, RBACChange{
+				Kind:       ChangeDeleteRole,
+				Role:       role.Name,
+				References: roleUsers[role.Name],
+			})
+		}
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return changeOrder[diff.Changes[i].Kind] < changeOrder[diff.Changes[j].Kind]
+	})
+
+	return diff
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRBACPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RBACPolicy
+		wantErr error
+	}{
+		{
+			name: "Valid Policy",
+			policy: RBACPolicy{
+				Permissions: []PolicyPermission{{Name: "users:read"}},
+				Roles:       []PolicyRole{{Name: "admin", Permissions: []string{PermissionWildcard}}},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Duplicate Permission",
+			policy: RBACPolicy{
+				Permissions: []PolicyPermission{{Name: "users:read"}, {Name: "users:read"}},
+			},
+			wantErr: ErrInvalidPolicy,
+		},
+		{
+			name: "Permission Without Resource",
+			policy: RBACPolicy{
+				Permissions: []PolicyPermission{{Name: "everything"}},
+			},
+			wantErr: ErrInvalidPolicy,
+		},
+		{
+			name: "Undeclared Permission",
+			policy: RBACPolicy{
+				Roles: []PolicyRole{{Name: "user", Permissions: []string{"files:read"}}},
+			},
+			wantErr: ErrInvalidPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiffRBACPolicy(t *testing.T) {
+	policy := &RBACPolicy{
+		Permissions: []PolicyPermission{
+			{Name: "users:read", Description: "Read users"},
+			{Name: "payments:refund", Description: "Refund payments"},
+		},
+		Roles: []PolicyRole{
+			{Name: "admin", Description: "Administrator", Permissions: []string{PermissionWildcard}},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	roles := []Role{
+		{Name: "admin", Description: "Administrator", Permissions: []Permission{{Name: "users:read"}, {Name: "files:read"}, {Name: "reports:read"}}},
+		{Name: "legacy", Description: "Old role", Permissions: []Permission{{Name: "files:read"}}},
+	}
+	permissions := []Permission{
+		{Name: "users:read", Description: "Read users", Resource: "users", Action: "read"},
+		{Name: "files:read", Description: "Read files", Resource: "files", Action: "read"},
+		{Name: "reports:read", Description: "Read reports", Resource: "reports", Action: "read"},
+	}
+
+	diff := DiffRBACPolicy(policy, roles, permissions, map[string]int{"legacy": 2})
+
+	want := []RBACChange{
+		{Kind: ChangeCreatePermission, Permission: "payments:refund", Description: "Refund payments", Resource: "payments", Action: "refund"},
+		{Kind: ChangeGrantPermission, Role: "admin", Permission: "payments:refund"},
+		{Kind: ChangeRevokePermission, Role: "admin", Permission: "files:read"},
+		{Kind: ChangeRevokePermission, Role: "admin", Permission: "reports:read"},
+		{Kind: ChangeDeleteRole, Role: "legacy", References: 2},
+		// admin is revoked in the same sync; only legacy keeps files:read
+		{Kind: ChangeDeletePermission, Permission: "files:read", References: 1},
+		{Kind: ChangeDeletePermission, Permission: "reports:read"},
+	}
+	if len(diff.Changes) != len(want) {
+		t.Fatalf("DiffRBACPolicy() = %v, want %v", diff.Changes, want)
+	}
+	for i := range want {
+		if diff.Changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, diff.Changes[i], want[i])
+		}
+	}
+
+	if got := len(diff.Blocked()); got != 2 {
+		t.Errorf("Blocked() returned %d changes, want 2", got)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserRoleIsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{name: "Permanent Grant", expiresAt: nil, want: false},
+		{name: "Expired Grant", expiresAt: &past, want: true},
+		{name: "Expires Exactly Now", expiresAt: &now, want: true},
+		{name: "Active Grant", expiresAt: &future, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &UserRole{ExpiresAt: tt.expiresAt}
+			if got := ur.IsExpired(now); got != tt.want {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrRefundNotFound     = errors.New("refund not found")
+	ErrRefundUnsupported  = errors.New("gateway does not support refunds")
+	ErrInvalidRefundState = errors.New("invalid refund state transition")
+	ErrRefundConflict     = errors.New("refund was modified concurrently")
+)
+
+// RefundStatus is a state in the refund lifecycle
+type RefundStatus string
+
+const (
+	// RefundRequested is being sent to the gateway
+	RefundRequested RefundStatus = "requested"
+	// RefundPendingManual waits for finance to return the money by hand
+	RefundPendingManual RefundStatus = "pending_manual"
+	RefundCompleted     RefundStatus = "completed"
+	RefundFailed        RefundStatus = "failed"
+	RefundRejected      RefundStatus = "rejected"
+)
+
+// Refund methods
+const (
+	RefundMethodGateway = "gateway"
+	RefundMethodManual  = "manual"
+)
+
+// Refund returns part or all of a verified payment to the payer
+type Refund struct {
+	ID        string
+	PaymentID string
+	// Amount is in Rials
+	Amount int64
+	Reason string
+	Method string
+	Status RefundStatus
+	// Reference is the gateway's refund ID or the bank reference of a
+	// manual transfer
+	Reference   string
+	RequestedBy string
+	ProcessedBy string
+	// Note explains a failure or rejection
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewRefund(paymentID string, amount int64, reason, method, requestedBy string) (*Refund, error) {
+	if err := Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+
+	status := RefundRequested
+	if method == RefundMethodManual {
+		status = RefundPendingManual
+	}
+
+	now := time.Now()
+	return &Refund{
+		PaymentID:   paymentID,
+		Amount:      amount,
+		Reason:      reason,
+		Method:      method,
+		Status:      status,
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// IsOpen reports whether the refund may still complete
+func (r *Refund) IsOpen() bool {
+	return r.Status == RefundRequested || r.Status == RefundPendingManual
+}
+
+// FallBackToManual hands a gateway refund over to finance, e.g. when the
+// gateway cannot refund it or its outcome is unknown
+func (r *Refund) FallBackToManual(note string) error {
+	if r.Status != RefundRequested {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidRefundState, r.Status, RefundPendingManual)
+	}
+	r.Method = RefundMethodManual
+	r.Status = RefundPendingManual
+	r.Note = note
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// Complete marks an open refund as done
+func (r *Refund) Complete(reference, processedBy string) error {
+	return r.close(RefundCompleted, reference, processedBy, "")
+}
+
+// Fail marks a gateway refund the gateway rejected
+func (r *Refund) Fail(note string) error {
+	return r.close(RefundFailed, "", "", note)
+}
+
+// Reject marks a manual refund finance declined
+func (r *Refund) Reject(processedBy, note string) error {
+	return r.close(RefundRejected, "", processedBy, note)
+}
+
+func (r *Refund) close(status RefundStatus, reference, processedBy, note string) error {
+	if !r.IsOpen() {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidRefundState, r.Status, status)
+	}
+	r.Status = status
+	r.Reference = reference
+	r.ProcessedBy = processedBy
+	r.Note = note
+	r.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64
+		method     string
+		wantStatus RefundStatus
+		wantErr    error
+	}{
+		{name: "Gateway Refund", amount: 1000, method: RefundMethodGateway, wantStatus: RefundRequested},
+		{name: "Manual Refund", amount: 1000, method: RefundMethodManual, wantStatus: RefundPendingManual},
+		{name: "Zero Amount", amount: 0, method: RefundMethodGateway, wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRefund("p1", tt.amount, "reason", tt.method, "admin")
+			if err != tt.wantErr {
+				t.Fatalf("NewRefund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && r.Status != tt.wantStatus {
+				t.Errorf("NewRefund() status = %v, want %v", r.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRefundTransitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       RefundStatus
+		apply      func(r *Refund) error
+		wantStatus RefundStatus
+		wantErr    bool
+	}{
+		{name: "Complete Requested", from: RefundRequested, apply: func(r *Refund) error { return r.Complete("ref", "admin") }, wantStatus: RefundCompleted},
+		{name: "Complete Manual", from: RefundPendingManual, apply: func(r *Refund) error { return r.Complete("ref", "admin") }, wantStatus: RefundCompleted},
+		{name: "Fail Requested", from: RefundRequested, apply: func(r *Refund) error { return r.Fail("rejected") }, wantStatus: RefundFailed},
+		{name: "Reject Manual", from: RefundPendingManual, apply: func(r *Refund) error { return r.Reject("admin", "no") }, wantStatus: RefundRejected},
+		{name: "Fall Back To Manual", from: RefundRequested, apply: func(r *Refund) error { return r.FallBackToManual("unsupported") }, wantStatus: RefundPendingManual},
+		{name: "Fall Back From Manual", from: RefundPendingManual, apply: func(r *Refund) error { return r.FallBackToManual("again") }, wantErr: true},
+		{name: "Complete Completed", from: RefundCompleted, apply: func(r *Refund) error { return r.Complete("ref", "admin") }, wantErr: true},
+		{name: "Reject Failed", from: RefundFailed, apply: func(r *Refund) error { return r.Reject("admin", "no") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Refund{Status: tt.from, Method: RefundMethodGateway}
+			err := tt.apply(r)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRefundState) {
+					t.Errorf("error = %v, want ErrInvalidRefundState", err)
+				}
+				if r.Status != tt.from {
+					t.Errorf("status changed to %v on rejected transition", r.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", r.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPaymentBlocked         = errors.New("payment was declined by risk checks")
+	ErrInvalidBlocklistEntry  = errors.New("invalid blocklist entry")
+	ErrBlocklistEntryNotFound = errors.New("blocklist entry not found")
+	ErrBlocklistEntryExists   = errors.New("value is already blocked")
+)
+
+// RiskAction is what risk checks decided for a payment
+type RiskAction string
+
+const (
+	RiskAllow RiskAction = "allow"
+	// RiskReview lets the payment through but holds it for manual review
+	// once it is paid
+	RiskReview RiskAction = "review"
+	// RiskBlock declines the payment before it reaches a gateway
+	RiskBlock RiskAction = "block"
+)
+
+func (a RiskAction) severity() int {
+	switch a {
+	case RiskReview:
+		return 1
+	case RiskBlock:
+		return 2
+	}
+	return 0
+}
+
+// RiskDecision is the outcome of risk checks and the rules that fired
+type RiskDecision struct {
+	Action  RiskAction
+	Reasons []string
+}
+
+// Flag records a rule that fired, keeping the most severe action
+func (d *RiskDecision) Flag(action RiskAction, reason string) {
+	if action.severity() > d.Action.severity() {
+		d.Action = action
+	}
+	d.Reasons = append(d.Reasons, reason)
+}
+
+// Merge folds the rules that fired in other into d
+func (d *RiskDecision) Merge(other RiskDecision) {
+	for _, reason := range other.Reasons {
+		d.Flag(other.Action, reason)
+	}
+}
+
+// NeedsReview reports whether the payment must be held once paid
+func (d RiskDecision) NeedsReview() bool {
+	return d.Action == RiskReview
+}
+
+// RiskRules configures the payment risk checks. A zero limit disables its
+// rule.
+type RiskRules struct {
+	// VelocityWindow is the period payment attempts are counted over
+	VelocityWindow time.Duration
+	// MaxPaymentsPerUser and MaxPaymentsPerIP attempts within the window are
+	// allowed; further ones are blocked
+	MaxPaymentsPerUser int
+	MaxPaymentsPerIP   int
+	// MaxDailyAmount in Rials a user may pay within 24 hours before payments
+	// are held for review
+	MaxDailyAmount int64
+	// Accounts younger than NewAccountPeriod have payments above
+	// NewAccountMaxAmount Rials held for review
+	NewAccountPeriod    time.Duration
+	NewAccountMaxAmount int64
+}
+
+func DefaultRiskRules() RiskRules {
+	return RiskRules{
+		VelocityWindow:      10 * time.Minute,
+		MaxPaymentsPerUser:  5,
+		MaxPaymentsPerIP:    10,
+		MaxDailyAmount:      500_000_000,
+		NewAccountPeriod:    24 * time.Hour,
+		NewAccountMaxAmount: 20_000_000,
+	}
+}
+
+// RiskSignals are the facts about a new payment the rules are evaluated on
+type RiskSignals struct {
+	// Amount of the new payment in Rials
+	Amount     int64
+	AccountAge time.Duration
+	// UserPayments and IPPayments are the earlier attempts within the
+	// velocity window; IPPayments is ignored when ClientIP is empty
+	UserPayments int
+	IPPayments   int
+	ClientIP     string
+	// DailyAmount is what the user paid or is paying within 24 hours, in
+	// Rials, excluding the new payment
+	DailyAmount   int64
+	MobileBlocked bool
+}
+
+// Evaluate applies the rules to a new payment
+func (r RiskRules) Evaluate(s RiskSignals) RiskDecision {
+	decision := RiskDecision{Action: RiskAllow}
+	if s.MobileBlocked {
+		decision.Flag(RiskBlock, "mobile is blocklisted")
+	}
+	if r.MaxPaymentsPerUser > 0 && s.UserPayments >= r.MaxPaymentsPerUser {
+		decision.Flag(RiskBlock, fmt.Sprintf("%d payments by the user within %s", s.UserPayments, r.VelocityWindow))
+	}
+	if r.MaxPaymentsPerIP > 0 && s.ClientIP != "" && s.IPPayments >= r.MaxPaymentsPerIP {
+		decision.Flag(RiskBlock, fmt.Sprintf("%d payments from %s within %s", s.IPPayments, s.ClientIP, r.VelocityWindow))
+	}
+	if r.MaxDailyAmount > 0 && s.DailyAmount+s.Amount > r.MaxDailyAmount {
+		decision.Flag(RiskReview, fmt.Sprintf("daily amount %d exceeds %d", s.DailyAmount+s.Amount, r.MaxDailyAmount))
+	}
+	if r.NewAccountPeriod > 0 && s.AccountAge < r.NewAccountPeriod && s.Amount > r.NewAccountMaxAmount {
+		decision.Flag(RiskReview, fmt.Sprintf("account younger than %s paying %d", r.NewAccountPeriod, s.Amount))
+	}
+	return decision
+}
+
+// RiskActivity is a user's and an IP's recent payments, as counted by the
+// repository
+type RiskActivity struct {
+	UserPayments int
+	IPPayments   int
+	DailyAmount  int64
+}
+
+// BlocklistKind is what a blocklist entry matches
+type BlocklistKind string
+
+const (
+	BlockMobile BlocklistKind = "mobile"
+	// BlockCardHash matches the card hash gateways report on verification
+	BlockCardHash BlocklistKind = "card_hash"
+)
+
+// BlocklistEntry blocks payments by a mobile number or card
+type BlocklistEntry struct {
+	ID        string
+	Kind      BlocklistKind
+	Value     string
+	Reason    string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+func NewBlocklistEntry(kind BlocklistKind, value, reason, createdBy string) (*BlocklistEntry, error) {
+	if kind != BlockMobile && kind != BlockCardHash {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidBlocklistEntry, kind)
+	}
+	value = NormalizeBlocklistValue(kind, value)
+	if value == "" {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalidBlocklistEntry)
+	}
+	if kind == BlockMobile && !isValidPhone(value) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlocklistEntry, ErrInvalidPhone)
+	}
+
+	return &BlocklistEntry{
+		Kind:      kind,
+		Value:     value,
+		Reason:    strings.TrimSpace(reason),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NormalizeBlocklistValue brings a value to the form entries are stored
+// in: mobiles as 09xxxxxxxxx and card hashes in lower case
+func NormalizeBlocklistValue(kind BlocklistKind, value string) string {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case BlockMobile:
+		value = strings.NewReplacer(" ", "", "-", "").Replace(value)
+		switch {
+		case strings.HasPrefix(value, "+98"):
+			value = "0" + value[3:]
+		case strings.HasPrefix(value, "0098"):
+			value = "0" + value[4:]
+		case strings.HasPrefix(value, "98") && len(value) == 12:
+			value = "0" + value[2:]
+		}
+	case BlockCardHash:
+		value = strings.ToLower(value)
+	}
+	return value
+}
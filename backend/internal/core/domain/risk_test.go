@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRiskRulesEvaluate(t *testing.T) {
+	rules := RiskRules{
+		VelocityWindow:      10 * time.Minute,
+		MaxPaymentsPerUser:  5,
+		MaxPaymentsPerIP:    10,
+		MaxDailyAmount:      1_000_000,
+		NewAccountPeriod:    24 * time.Hour,
+		NewAccountMaxAmount: 100_000,
+	}
+	established := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		rules       RiskRules
+		signals     RiskSignals
+		wantAction  RiskAction
+		wantReasons int
+	}{
+		{name: "Allowed", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, UserPayments: 4, IPPayments: 9, ClientIP: "10.0.0.1"}, wantAction: RiskAllow},
+		{name: "User Velocity", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, UserPayments: 5}, wantAction: RiskBlock, wantReasons: 1},
+		{name: "IP Velocity", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, IPPayments: 10, ClientIP: "10.0.0.1"}, wantAction: RiskBlock, wantReasons: 1},
+		{name: "IP Unknown", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, IPPayments: 10}, wantAction: RiskAllow},
+		{name: "Blocked Mobile", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, MobileBlocked: true}, wantAction: RiskBlock, wantReasons: 1},
+		{name: "Daily Amount", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, DailyAmount: 960_000}, wantAction: RiskReview, wantReasons: 1},
+		{name: "Daily Amount Reached Exactly", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: established, DailyAmount: 950_000}, wantAction: RiskAllow},
+		{name: "New Account Large Payment", rules: rules, signals: RiskSignals{Amount: 200_000, AccountAge: time.Hour}, wantAction: RiskReview, wantReasons: 1},
+		{name: "New Account Small Payment", rules: rules, signals: RiskSignals{Amount: 50_000, AccountAge: time.Hour}, wantAction: RiskAllow},
+		{name: "Block Outranks Review", rules: rules, signals: RiskSignals{Amount: 200_000, AccountAge: time.Hour, UserPayments: 6}, wantAction: RiskBlock, wantReasons: 2},
+		{name: "Rules Disabled", rules: RiskRules{}, signals: RiskSignals{Amount: 200_000, UserPayments: 100, IPPayments: 100, ClientIP: "10.0.0.1", DailyAmount: 1 << 40}, wantAction: RiskAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.rules.Evaluate(tt.signals)
+			if decision.Action != tt.wantAction || len(decision.Reasons) != tt.wantReasons {
+				t.Errorf("Evaluate() = %v %q, want %v with %d reasons", decision.Action, decision.Reasons, tt.wantAction, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestRiskDecisionMerge(t *testing.T) {
+	decision := RiskDecision{Action: RiskAllow}
+	decision.Merge(RiskDecision{Action: RiskReview, Reasons: []string{"card is blocklisted"}})
+	decision.Merge(RiskDecision{Action: RiskAllow})
+
+	if !decision.NeedsReview() || len(decision.Reasons) != 1 {
+		t.Errorf("Merge() = %v %q, want review with one reason", decision.Action, decision.Reasons)
+	}
+}
+
+func TestNewBlocklistEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      BlocklistKind
+		value     string
+		wantValue string
+		wantErr   error
+	}{
+		{name: "Mobile", kind: BlockMobile, value: "09121234567", wantValue: "09121234567"},
+		{name: "International Mobile", kind: BlockMobile, value: "+98 912 123 4567", wantValue: "09121234567"},
+		{name: "Mobile Without Zero", kind: BlockMobile, value: "989121234567", wantValue: "09121234567"},
+		{name: "Invalid Mobile", kind: BlockMobile, value: "12345", wantErr: ErrInvalidBlocklistEntry},
+		{name: "Card Hash", kind: BlockCardHash, value: " 9F86D081884C7D65 ", wantValue: "9f86d081884c7d65"},
+		{name: "Empty Value", kind: BlockCardHash, value: " ", wantErr: ErrInvalidBlocklistEntry},
+		{name: "Unknown Kind", kind: "email", value: "a@b.c", wantErr: ErrInvalidBlocklistEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := NewBlocklistEntry(tt.kind, tt.value, "card testing", "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewBlocklistEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && entry.Value != tt.wantValue {
+				t.Errorf("Value = %q, want %q", entry.Value, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeSheba(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheba string
+		want  string
+	}{
+		{name: "Normalized", sheba: "IR820540102680020817909002", want: "IR820540102680020817909002"},
+		{name: "Lower Case", sheba: "ir820540102680020817909002", want: "IR820540102680020817909002"},
+		{name: "Grouped", sheba: " IR82 0540 1026 8002 0817 9090 02 ", want: "IR820540102680020817909002"},
+		{name: "Dashes", sheba: "IR82-0540-1026-8002-0817-9090-02", want: "IR820540102680020817909002"},
+		{name: "Digits Only", sheba: "820540102680020817909002", want: "IR820540102680020817909002"},
+		{name: "Persian Digits", sheba: "IR۸۲۰۵۴۰۱۰۲۶۸۰۰۲۰۸۱۷۹۰۹۰۰۲", want: "IR820540102680020817909002"},
+		{name: "Arabic Digits", sheba: "IR٨٢٠٥٤٠١٠٢٦٨٠٠٢٠٨١٧٩٠٩٠٠٢", want: "IR820540102680020817909002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeSheba(tt.sheba); got != tt.want {
+				t.Errorf("NormalizeSheba(%q) = %q, want %q", tt.sheba, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSheba(t *testing.T) {
+	tests := []struct {
+		name    string
+		sheba   string
+		wantErr bool
+	}{
+		{name: "Parsian", sheba: "IR820540102680020817909002"},
+		{name: "Melli", sheba: "IR590170000000100326276001"},
+		{name: "Saman", sheba: "IR640560000000000123456789"},
+		{name: "Wrong Check Digits", sheba: "IR830540102680020817909002", wantErr: true},
+		{name: "Swapped Digits", sheba: "IR820540102680020817909020", wantErr: true},
+		{name: "Unknown Bank", sheba: "IR180990000000000123456789", wantErr: true},
+		{name: "Too Short", sheba: "IR82054010268002081790900", wantErr: true},
+		{name: "Foreign", sheba: "DE89370400440532013000", wantErr: true},
+		{name: "Letters", sheba: "IR82054010268002081790900A", wantErr: true},
+		{name: "Empty", sheba: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSheba(tt.sheba)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateSheba(%q) error = %v, wantErr %v", tt.sheba, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSheba) {
+				t.Errorf("ValidateSheba(%q) error = %v, want ErrInvalidSheba", tt.sheba, err)
+			}
+		})
+	}
+}
+
+func TestBankFromSheba(t *testing.T) {
+	tests := []struct {
+		name     string
+		sheba    string
+		wantCode string
+		wantOK   bool
+	}{
+		{name: "Parsian", sheba: "IR820540102680020817909002", wantCode: "054", wantOK: true},
+		{name: "Melli", sheba: "IR590170000000100326276001", wantCode: "017", wantOK: true},
+		{name: "Ayandeh", sheba: "IR820620000000000000000001", wantCode: "062", wantOK: true},
+		{name: "Unknown", sheba: "IR180990000000000123456789"},
+		{name: "Malformed", sheba: "IR82"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank, ok := BankFromSheba(tt.sheba)
+			if ok != tt.wantOK || bank.Code != tt.wantCode {
+				t.Errorf("BankFromSheba(%q) = %q, %v, want %q, %v", tt.sheba, bank.Code, ok, tt.wantCode, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrInvalidPlan              = errors.New("invalid plan")
+	ErrPlanNotFound             = errors.New("plan not found")
+	ErrPlanCodeTaken            = errors.New("plan code is already taken")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	ErrAlreadySubscribed        = errors.New("user already has a subscription")
+	ErrInvalidSubscriptionState = errors.New("invalid subscription state")
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+	ErrInvoiceExists            = errors.New("invoice already issued for this period")
+	ErrInvoiceNotPayable        = errors.New("invoice is not payable")
+)
+
+// Plan is a subscription offering billed once per period
+type Plan struct {
+	ID   string
+	Code string
+	Name string
+	// Price is in Rials per period; free plans cost 0
+	Price      int64
+	PeriodDays int
+	// GraceDays is how long a subscription stays usable after its period
+	// ends while the renewal invoice is unpaid
+	GraceDays int
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewPlan(code, name string, price int64, periodDays, graceDays int) (*Plan, error) {
+	if code == "" || name == "" {
+		return nil, fmt.Errorf("%w: code and name are required", ErrInvalidPlan)
+	}
+	if price < 0 || periodDays <= 0 || graceDays < 0 {
+		return nil, fmt.Errorf("%w: price, period and grace days must not be negative", ErrInvalidPlan)
+	}
+
+	now := time.Now()
+	return &Plan{
+		Code:       code,
+		Name:       name,
+		Price:      price,
+		PeriodDays: periodDays,
+		GraceDays:  graceDays,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+func (p *Plan) Period() time.Duration {
+	return time.Duration(p.PeriodDays) * 24 * time.Hour
+}
+
+func (p *Plan) GracePeriod() time.Duration {
+	return time.Duration(p.GraceDays) * 24 * time.Hour
+}
+
+// SubscriptionStatus is a state in the subscription lifecycle
+type SubscriptionStatus string
+
+const (
+	// SubscriptionPending waits for its first invoice to be paid
+	SubscriptionPending SubscriptionStatus = "pending"
+	SubscriptionActive  SubscriptionStatus = "active"
+	// SubscriptionPastDue has ended its period with the renewal unpaid and
+	// is within the plan's grace period
+	SubscriptionPastDue   SubscriptionStatus = "past_due"
+	SubscriptionExpired   SubscriptionStatus = "expired"
+	SubscriptionCancelled SubscriptionStatus = "cancelled"
+)
+
+// Subscription gives a user a plan's features for the current period.
+// Gateways cannot charge cards on file, so each period is paid through an
+// invoice.
+type Subscription struct {
+	ID     string
+	UserID string
+	PlanID string
+	// PendingPlanID is a downgrade that takes effect at the next renewal
+	PendingPlanID      string
+	Status             SubscriptionStatus
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	CancelAtPeriodEnd  bool
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// NewSubscription creates a pending subscription, or an active one for free
+// plans, which need no invoice
+func NewSubscription(userID string, plan *Plan) *Subscription {
+	now := time.Now()
+	s := &Subscription{
+		UserID:    userID,
+		PlanID:    plan.ID,
+		Status:    SubscriptionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if plan.Price == 0 {
+		s.Activate(plan, now)
+	}
+	return s
+}
+
+// IsCurrent reports whether the subscription still blocks a new one
+func (s *Subscription) IsCurrent() bool {
+	return s.Status == SubscriptionPending || s.Status == SubscriptionActive || s.Status == SubscriptionPastDue
+}
+
+// Entitled reports whether the plan's features are usable at now, which
+// includes the grace period after an unpaid renewal
+func (s *Subscription) Entitled(now time.Time, grace time.Duration) bool {
+	if s.Status != SubscriptionActive && s.Status != SubscriptionPastDue {
+		return false
+	}
+	return now.Before(s.CurrentPeriodEnd.Add(grace))
+}
+
+// Activate starts the first period at now
+func (s *Subscription) Activate(plan *Plan, now time.Time) {
+	s.PlanID = plan.ID
+	s.Status = SubscriptionActive
+	s.CurrentPeriodStart = now
+	s.CurrentPeriodEnd = now.Add(plan.Period())
+	s.UpdatedAt = now
+}
+
+// Renew starts the next period on plan right after the current one, so a
+// renewal paid during the grace period does not extend the subscription
+func (s *Subscription) Renew(plan *Plan) error {
+	if s.Status != SubscriptionActive && s.Status != SubscriptionPastDue {
+		return fmt.Errorf("%w: cannot renew a %s subscription", ErrInvalidSubscriptionState, s.Status)
+	}
+	s.PlanID = plan.ID
+	s.PendingPlanID = ""
+	s.Status = SubscriptionActive
+	s.CurrentPeriodStart = s.CurrentPeriodEnd
+	s.CurrentPeriodEnd = s.CurrentPeriodEnd.Add(plan.Period())
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Lapse settles a subscription whose period ended at or before now: it is
+// cancelled if requested, past due within grace, and expired after it
+func (s *Subscription) Lapse(now time.Time, grace time.Duration) {
+	if now.Before(s.CurrentPeriodEnd) || (s.Status != SubscriptionActive && s.Status != SubscriptionPastDue) {
+		return
+	}
+	switch {
+	case s.CancelAtPeriodEnd:
+		s.Status = SubscriptionCancelled
+	case now.Before(s.CurrentPeriodEnd.Add(grace)):
+		s.Status = SubscriptionPastDue
+	default:
+		s.Status = SubscriptionExpired
+	}
+	s.UpdatedAt = now
+}
+
+// Cancel stops the subscription at the end of its period; unpaid pending
+// subscriptions are cancelled at once
+func (s *Subscription) Cancel() error {
+	switch s.Status {
+	case SubscriptionPending:
+		s.Status = SubscriptionCancelled
+	case SubscriptionActive, SubscriptionPastDue:
+		s.CancelAtPeriodEnd = true
+	default:
+		return fmt.Errorf("%w: cannot cancel a %s subscription", ErrInvalidSubscriptionState, s.Status)
+	}
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Prorate returns what switching from current to next costs for the rest of
+// the period at now: the price difference scaled by the remaining time and
+// rounded to 10 Rials, so gateways counting in Tomans can charge it.
+// Downgrades return zero; they take effect at renewal without a credit.
+func Prorate(current, next *Plan, periodStart, periodEnd, now time.Time) int64 {
+	total := periodEnd.Sub(periodStart)
+	remaining := periodEnd.Sub(now)
+	if next.Price <= current.Price || total <= 0 || remaining <= 0 {
+		return 0
+	}
+	if remaining > total {
+		remaining = total
+	}
+	return int64(math.Round(float64(next.Price-current.Price)*float64(remaining)/float64(total)/10)) * 10
+}
+
+// InvoiceKind is what an invoice charges for
+type InvoiceKind string
+
+const (
+	// InvoiceInitial pays the first period of a new subscription
+	InvoiceInitial InvoiceKind = "initial"
+	// InvoiceRenewal pays the period after the current one
+	InvoiceRenewal InvoiceKind = "renewal"
+	// InvoiceProration pays an upgrade for the rest of the current period
+	InvoiceProration InvoiceKind = "proration"
+)
+
+// InvoiceStatus is a state in the invoice lifecycle
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	// InvoiceVoid was withdrawn, e.g. because its subscription ended
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+// Invoice is an amount a user owes for a subscription period, paid through
+// any payment gateway
+type Invoice struct {
+	ID             string
+	SubscriptionID string
+	UserID         string
+	// PlanID is the plan the subscription is on once the invoice is paid
+	PlanID string
+	Kind   InvoiceKind
+	// Amount is in Rials
+	Amount      int64
+	Status      InvoiceStatus
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	DueAt       time.Time
+	PaymentID   string
+	RemindedAt  *time.Time
+	PaidAt      *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func NewInvoice(sub *Subscription, planID string, kind InvoiceKind, amount int64, periodStart, periodEnd, dueAt time.Time) (*Invoice, error) {
+	if err := Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Invoice{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		PlanID:         planID,
+		Kind:           kind,
+		Amount:         amount,
+		Status:         InvoiceOpen,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		DueAt:          dueAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// MarkPaid settles an open invoice with a verified payment
+func (i *Invoice) MarkPaid(paymentID string, now time.Time) error {
+	if i.Status != InvoiceOpen {
+		return fmt.Errorf("%w: invoice is %s", ErrInvoiceNotPayable, i.Status)
+	}
+	i.Status = InvoicePaid
+	i.PaymentID = paymentID
+	i.PaidAt = &now
+	i.UpdatedAt = now
+	return nil
+}
+
+// Apply moves sub to the state paying the invoice entitles it to
+func (i *Invoice) Apply(sub *Subscription, plan *Plan, now time.Time) error {
+	switch i.Kind {
+	case InvoiceInitial:
+		if sub.Status != SubscriptionPending {
+			return fmt.Errorf("%w: cannot activate a %s subscription", ErrInvalidSubscriptionState, sub.Status)
+		}
+		sub.Activate(plan, now)
+		return nil
+	case InvoiceRenewal:
+		return sub.Renew(plan)
+	case InvoiceProration:
+		if sub.Status != SubscriptionActive && sub.Status != SubscriptionPastDue {
+			return fmt.Errorf("%w: cannot upgrade a %s subscription", ErrInvalidSubscriptionState, sub.Status)
+		}
+		sub.PlanID = plan.ID
+		sub.PendingPlanID = ""
+		sub.UpdatedAt = now
+		return nil
+	}
+	return fmt.Errorf("%w: unknown invoice kind %s", ErrInvoiceNotPayable, i.Kind)
+}
@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		price      int64
+		periodDays int
+		graceDays  int
+		wantErr    bool
+	}{
+		{name: "Valid Plan", code: "pro", price: 1000000, periodDays: 30, graceDays: 3},
+		{name: "Free Plan", code: "free", price: 0, periodDays: 30},
+		{name: "Missing Code", code: "", price: 1000, periodDays: 30, wantErr: true},
+		{name: "Negative Price", code: "pro", price: -1, periodDays: 30, wantErr: true},
+		{name: "Zero Period", code: "pro", price: 1000, periodDays: 0, wantErr: true},
+		{name: "Negative Grace", code: "pro", price: 1000, periodDays: 30, graceDays: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPlan(tt.code, "Plan", tt.price, tt.periodDays, tt.graceDays)
+			if tt.wantErr != errors.Is(err, ErrInvalidPlan) {
+				t.Errorf("NewPlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSubscription(t *testing.T) {
+	paid := &Plan{ID: "pro", Price: 1000, PeriodDays: 30}
+	free := &Plan{ID: "free", Price: 0, PeriodDays: 30}
+
+	if s := NewSubscription("u1", paid); s.Status != SubscriptionPending {
+		t.Errorf("paid plan status = %v, want %v", s.Status, SubscriptionPending)
+	}
+	s := NewSubscription("u1", free)
+	if s.Status != SubscriptionActive {
+		t.Errorf("free plan status = %v, want %v", s.Status, SubscriptionActive)
+	}
+	if got := s.CurrentPeriodEnd.Sub(s.CurrentPeriodStart); got != 30*24*time.Hour {
+		t.Errorf("free plan period = %v, want 30 days", got)
+	}
+}
+
+func TestSubscriptionEntitled(t *testing.T) {
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	grace := 3 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		status SubscriptionStatus
+		now    time.Time
+		want   bool
+	}{
+		{name: "Active In Period", status: SubscriptionActive, now: end.Add(-time.Hour), want: true},
+		{name: "Past Due In Grace", status: SubscriptionPastDue, now: end.Add(24 * time.Hour), want: true},
+		{name: "Past Due After Grace", status: SubscriptionPastDue, now: end.Add(grace), want: false},
+		{name: "Pending", status: SubscriptionPending, now: end.Add(-time.Hour), want: false},
+		{name: "Cancelled", status: SubscriptionCancelled, now: end.Add(-time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Subscription{Status: tt.status, CurrentPeriodEnd: end}
+			if got := s.Entitled(tt.now, grace); got != tt.want {
+				t.Errorf("Entitled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionLapse(t *testing.T) {
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	grace := 3 * 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		status     SubscriptionStatus
+		cancel     bool
+		now        time.Time
+		wantStatus SubscriptionStatus
+	}{
+		{name: "Before End", status: SubscriptionActive, now: end.Add(-time.Hour), wantStatus: SubscriptionActive},
+		{name: "In Grace", status: SubscriptionActive, now: end.Add(time.Hour), wantStatus: SubscriptionPastDue},
+		{name: "After Grace", status: SubscriptionPastDue, now: end.Add(grace), wantStatus: SubscriptionExpired},
+		{name: "Cancel At Period End", status: SubscriptionActive, cancel: true, now: end, wantStatus: SubscriptionCancelled},
+		{name: "Already Expired", status: SubscriptionExpired, now: end.Add(grace), wantStatus: SubscriptionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Subscription{Status: tt.status, CurrentPeriodEnd: end, CancelAtPeriodEnd: tt.cancel}
+			s.Lapse(tt.now, grace)
+			if s.Status != tt.wantStatus {
+				t.Errorf("Lapse() status = %v, want %v", s.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSubscriptionRenew(t *testing.T) {
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	plan := &Plan{ID: "basic", PeriodDays: 30}
+
+	s := &Subscription{PlanID: "pro", PendingPlanID: "basic", Status: SubscriptionPastDue, CurrentPeriodEnd: end}
+	if err := s.Renew(plan); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+	if s.Status != SubscriptionActive || s.PlanID != "basic" || s.PendingPlanID != "" {
+		t.Errorf("Renew() = %+v, want active on basic", s)
+	}
+	if !s.CurrentPeriodStart.Equal(end) || !s.CurrentPeriodEnd.Equal(end.Add(30*24*time.Hour)) {
+		t.Errorf("Renew() period = %v - %v, want to start at %v", s.CurrentPeriodStart, s.CurrentPeriodEnd, end)
+	}
+
+	expired := &Subscription{Status: SubscriptionExpired}
+	if err := expired.Renew(plan); !errors.Is(err, ErrInvalidSubscriptionState) {
+		t.Errorf("Renew() on expired error = %v, want ErrInvalidSubscriptionState", err)
+	}
+}
+
+func TestProrate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+	basic := &Plan{Price: 300000}
+	pro := &Plan{Price: 900000}
+
+	tests := []struct {
+		name    string
+		current *Plan
+		next    *Plan
+		now     time.Time
+		want    int64
+	}{
+		{name: "Upgrade At Start", current: basic, next: pro, now: start, want: 600000},
+		{name: "Upgrade Halfway", current: basic, next: pro, now: start.Add(15 * 24 * time.Hour), want: 300000},
+		// 833.33 Rials is not a whole number of Tomans
+		{name: "Upgrade An Hour Before End", current: basic, next: pro, now: end.Add(-time.Hour), want: 830},
+		{name: "Upgrade After End", current: basic, next: pro, now: end, want: 0},
+		{name: "Downgrade", current: pro, next: basic, now: start, want: 0},
+		{name: "Same Price", current: basic, next: basic, now: start, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Prorate(tt.current, tt.next, start, end, tt.now)
+			if got != tt.want {
+				t.Errorf("Prorate() = %d, want %d", got, tt.want)
+			}
+			if _, err := Rials(got).In(CurrencyToman); got > 0 && err != nil {
+				t.Errorf("Prorate() = %d is not payable in Tomans: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestInvoiceMarkPaid(t *testing.T) {
+	sub := &Subscription{ID: "s1", UserID: "u1"}
+	now := time.Now()
+
+	if _, err := NewInvoice(sub, "pro", InvoiceRenewal, 0, now, now, now); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("NewInvoice() with zero amount error = %v, want ErrInvalidAmount", err)
+	}
+
+	inv, err := NewInvoice(sub, "pro", InvoiceRenewal, 1000, now, now, now)
+	if err != nil {
+		t.Fatalf("NewInvoice() error = %v", err)
+	}
+	if err := inv.MarkPaid("p1", now); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if inv.Status != InvoicePaid || inv.PaymentID != "p1" {
+		t.Errorf("MarkPaid() = %+v, want paid by p1", inv)
+	}
+	if err := inv.MarkPaid("p2", now); !errors.Is(err, ErrInvoiceNotPayable) {
+		t.Errorf("MarkPaid() twice error = %v, want ErrInvoiceNotPayable", err)
+	}
+}
+
+func TestInvoiceApply(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pro := &Plan{ID: "pro", PeriodDays: 30}
+
+	tests := []struct {
+		name       string
+		kind       InvoiceKind
+		status     SubscriptionStatus
+		wantStatus SubscriptionStatus
+		wantErr    bool
+	}{
+		{name: "Initial Activates", kind: InvoiceInitial, status: SubscriptionPending, wantStatus: SubscriptionActive},
+		{name: "Initial On Active", kind: InvoiceInitial, status: SubscriptionActive, wantErr: true},
+		{name: "Renewal Of Past Due", kind: InvoiceRenewal, status: SubscriptionPastDue, wantStatus: SubscriptionActive},
+		{name: "Renewal Of Expired", kind: InvoiceRenewal, status: SubscriptionExpired, wantErr: true},
+		{name: "Proration Upgrades", kind: InvoiceProration, status: SubscriptionActive, wantStatus: SubscriptionActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{PlanID: "basic", Status: tt.status, CurrentPeriodEnd: now}
+			inv := &Invoice{Kind: tt.kind, PlanID: pro.ID}
+			err := inv.Apply(sub, pro, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSubscriptionState) {
+					t.Errorf("Apply() error = %v, want ErrInvalidSubscriptionState", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if sub.Status != tt.wantStatus || sub.PlanID != pro.ID {
+				t.Errorf("Apply() = %+v, want %v on pro", sub, tt.wantStatus)
+			}
+		})
+	}
+}
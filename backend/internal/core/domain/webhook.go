@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEndpoint  = errors.New("invalid webhook endpoint")
+	ErrInvalidWebhookState     = errors.New("invalid webhook delivery state")
+)
+
+// Webhook event types
+const (
+	WebhookPaymentVerified = "payment.verified"
+	// WebhookPaymentFailed covers failed, cancelled and expired payments
+	WebhookPaymentFailed = "payment.failed"
+	// WebhookPaymentRefunded is sent for every refund completed on a
+	// payment, partial or full
+	WebhookPaymentRefunded = "payment.refunded"
+	WebhookPaymentHeld     = "payment.held"
+	WebhookRefundCreated   = "refund.created"
+	WebhookRefundCompleted = "refund.completed"
+	// WebhookRefundFailed covers refunds the gateway refused and manual
+	// refunds finance rejected
+	WebhookRefundFailed = "refund.failed"
+)
+
+// WebhookEventTypes lists the events endpoints can subscribe to
+var WebhookEventTypes = []string{
+	WebhookPaymentVerified,
+	WebhookPaymentFailed,
+	WebhookPaymentRefunded,
+	WebhookPaymentHeld,
+	WebhookRefundCreated,
+	WebhookRefundCompleted,
+	WebhookRefundFailed,
+}
+
+// WebhookMaxAttempts is how many times a delivery is tried before it fails
+const WebhookMaxAttempts = 10
+
+const (
+	webhookFirstRetry = 30 * time.Second
+	webhookMaxRetry   = 6 * time.Hour
+)
+
+// WebhookEndpoint is a merchant URL payment and refund events are posted to
+type WebhookEndpoint struct {
+	ID string
+	// OrganizationID scopes the endpoint to the payments of a tenant; empty
+	// means every payment of the app
+	OrganizationID string
+	URL            string
+	Description    string
+	// Events are the event types the endpoint receives; empty means all
+	Events []string
+	// Secret signs the payloads sent to the endpoint
+	Secret    string
+	Active    bool
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewWebhookEndpoint(organizationID, rawURL, description string, events []string, secret, createdBy string) (*WebhookEndpoint, error) {
+	now := time.Now()
+	e := &WebhookEndpoint{
+		OrganizationID: organizationID,
+		URL:            strings.TrimSpace(rawURL),
+		Description:    description,
+		Events:         events,
+		Secret:         secret,
+		Active:         true,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Validate checks the endpoint's URL and subscriptions
+func (e *WebhookEndpoint) Validate() error {
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: URL must be an absolute http or https URL", ErrInvalidWebhookEndpoint)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: URL must not contain credentials", ErrInvalidWebhookEndpoint)
+	}
+	for _, event := range e.Events {
+		if !isWebhookEventType(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhookEndpoint, event)
+		}
+	}
+	if e.Secret == "" {
+		return fmt.Errorf("%w: secret is required", ErrInvalidWebhookEndpoint)
+	}
+	return nil
+}
+
+// Subscribes reports whether the endpoint receives events of eventType
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if !e.Active {
+		return false
+	}
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, event := range e.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, known := range WebhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is a state in the delivery lifecycle
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookPending waits for its next attempt
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookFailed ran out of attempts; it is only sent again when replayed
+	WebhookFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event queued for an endpoint
+type WebhookDelivery struct {
+	ID         string
+	EndpointID string
+	// EventID identifies the event; it is the same for every endpoint and
+	// every attempt so receivers can ignore repeats
+	EventID   string
+	EventType string
+	Payload   []byte
+	Status    WebhookDeliveryStatus
+	Attempts  int
+	// NextAttemptAt is when a pending delivery is due
+	NextAttemptAt time.Time
+	// LastStatusCode and LastError describe the latest attempt; the status
+	// code is zero when no response was received
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewWebhookDelivery(endpointID, eventID, eventType string, payload []byte) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        WebhookPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// WebhookAttempt is an entry of the delivery log: one request sent to an
+// endpoint and its answer
+type WebhookAttempt struct {
+	ID         string
+	DeliveryID string
+	// Attempt numbers the delivery's attempts from 1
+	Attempt    int
+	StatusCode int
+	// Error is why the attempt failed, empty when it succeeded
+	Error string
+	// ResponseBody is the start of the endpoint's answer
+	ResponseBody string
+	Duration     time.Duration
+	CreatedAt    time.Time
+}
+
+// RecordAttempt counts an attempt at now that got statusCode, or failed to
+// get a response with sendErr. A 2xx answer delivers the event; anything
+// else is retried with exponential backoff until WebhookMaxAttempts is
+// reached. It returns the log entry for the attempt.
+func (d *WebhookDelivery) RecordAttempt(statusCode int, sendErr error, now time.Time) (*WebhookAttempt, error) {
+	if d.Status != WebhookPending {
+		return nil, fmt.Errorf("%w: %s delivery cannot be attempted", ErrInvalidWebhookState, d.Status)
+	}
+
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	switch {
+	case sendErr != nil:
+		d.LastError = sendErr.Error()
+	case statusCode < 200 || statusCode > 299:
+		d.LastError = fmt.Sprintf("endpoint answered %d", statusCode)
+	}
+	d.UpdatedAt = now
+
+	switch {
+	case d.LastError == "":
+		d.Status = WebhookSucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= WebhookMaxAttempts:
+		d.Status = WebhookFailed
+	default:
+		d.NextAttemptAt = now.Add(WebhookRetryDelay(d.Attempts))
+	}
+
+	return &WebhookAttempt{
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts,
+		StatusCode: statusCode,
+		Error:      d.LastError,
+		CreatedAt:  now,
+	}, nil
+}
+
+// WebhookRetryDelay is how long to wait after the given number of failed
+// attempts: 30 seconds doubling each time, up to 6 hours
+func WebhookRetryDelay(attempts int) time.Duration {
+	delay := webhookFirstRetry
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxRetry {
+			return webhookMaxRetry
+		}
+	}
+	return delay
+}
+
+// Replay queues a finished delivery to be sent again at now. A replayed
+// delivery that fails is not retried further once it is past
+// WebhookMaxAttempts.
+func (d *WebhookDelivery) Replay(now time.Time) error {
+	if d.Status == WebhookPending {
+		return fmt.Errorf("%w: delivery is already queued", ErrInvalidWebhookState)
+	}
+	d.Status = WebhookPending
+	d.NextAttemptAt = now
+	d.UpdatedAt = now
+	return nil
+}
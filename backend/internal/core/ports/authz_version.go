@@ -0,0 +1,18 @@
+package ports
+
+import "context"
+
+// PermissionVersionStore tracks a monotonically increasing permission-set
+// version per user. Tokens embedding permissions carry the version they were
+// issued with and become stale as soon as it changes.
+type PermissionVersionStore interface {
+	// UserVersion returns the current permission version of a user
+	UserVersion(ctx context.Context, userID string) (int64, error)
+
+	// BumpUser invalidates embedded permissions of a single user
+	BumpUser(ctx context.Context, userID string) error
+
+	// BumpAll invalidates embedded permissions of every user, e.g. after a
+	// role's permissions change
+	BumpAll(ctx context.Context) error
+}
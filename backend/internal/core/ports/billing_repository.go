@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// BillingRepository defines the interface for plan, subscription and invoice
+// data access
+type BillingRepository interface {
+	// CreatePlan fails with domain.ErrPlanCodeTaken for duplicate codes
+	CreatePlan(ctx context.Context, plan *domain.Plan) error
+	UpdatePlan(ctx context.Context, plan *domain.Plan) error
+	GetPlan(ctx context.Context, id string) (*domain.Plan, error)
+	GetPlanByCode(ctx context.Context, code string) (*domain.Plan, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]domain.Plan, error)
+
+	// CreateSubscription fails with domain.ErrAlreadySubscribed when the
+	// user has a current subscription
+	CreateSubscription(ctx context.Context, sub *domain.Subscription) error
+	GetSubscription(ctx context.Context, id string) (*domain.Subscription, error)
+	// GetCurrentSubscription returns the user's pending, active or past due
+	// subscription
+	GetCurrentSubscription(ctx context.Context, userID string) (*domain.Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *domain.Subscription) error
+	// ListRenewable returns subscriptions that keep running past their
+	// period, ending before before, without a renewal invoice yet
+	ListRenewable(ctx context.Context, before time.Time, limit int) ([]domain.Subscription, error)
+	// ListLapsed returns active subscriptions whose period ended and past due
+	// ones whose grace period ended by now
+	ListLapsed(ctx context.Context, now time.Time, limit int) ([]domain.Subscription, error)
+
+	// CreateInvoice fails with domain.ErrInvoiceExists when the period is
+	// already invoiced
+	CreateInvoice(ctx context.Context, inv *domain.Invoice) error
+	GetInvoice(ctx context.Context, id string) (*domain.Invoice, error)
+	ListInvoices(ctx context.Context, userID string, limit, offset int) ([]domain.Invoice, error)
+	// ListUnreminded returns open invoices not reminded about since
+	// remindedBefore
+	ListUnreminded(ctx context.Context, remindedBefore time.Time, limit int) ([]domain.Invoice, error)
+	MarkReminded(ctx context.Context, invoiceID string, at time.Time) error
+	// VoidOpenInvoices withdraws the subscription's open invoices of kind,
+	// or of every kind when kind is empty
+	VoidOpenInvoices(ctx context.Context, subscriptionID string, kind domain.InvoiceKind) error
+
+	// PayInvoice saves a paid invoice and the subscription it advanced in
+	// one transaction, failing with domain.ErrInvoiceNotPayable if the
+	// invoice was settled or voided meanwhile
+	PayInvoice(ctx context.Context, inv *domain.Invoice, sub *domain.Subscription) error
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// CardReceiptRepository defines the interface for card-to-card receipt data access
+type CardReceiptRepository interface {
+	Create(ctx context.Context, receipt *domain.CardReceipt) error
+	GetByID(ctx context.Context, id string) (*domain.CardReceipt, error)
+	List(ctx context.Context, filter domain.CardReceiptFilter) ([]domain.CardReceipt, error)
+
+	// FindPossibleDuplicates returns receipts sharing the tracking number or
+	// image key, or of the same amount paid within window of paidAt
+	FindPossibleDuplicates(ctx context.Context, trackingNumber, imageKey string, amount int64, paidAt time.Time, window time.Duration) ([]domain.CardReceipt, error)
+
+	// SaveReview persists a review of a pending receipt, failing with
+	// domain.ErrReceiptAlreadyReviewed if it was reviewed meanwhile
+	SaveReview(ctx context.Context, receipt *domain.CardReceipt) error
+}
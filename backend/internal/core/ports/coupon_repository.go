@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// CouponRepository defines the interface for coupon and redemption data
+// access
+type CouponRepository interface {
+	// Create and Update fail with domain.ErrCouponCodeTaken for duplicate
+	// codes
+	Create(ctx context.Context, coupon *domain.Coupon) error
+	Update(ctx context.Context, coupon *domain.Coupon) error
+	GetByID(ctx context.Context, id string) (*domain.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*domain.Coupon, error)
+	// List returns coupons, newest first
+	List(ctx context.Context, limit, offset int) ([]domain.Coupon, error)
+	// Delete removes a coupon that was never redeemed, failing with
+	// domain.ErrCouponInUse otherwise
+	Delete(ctx context.Context, id string) error
+
+	// Redeem reserves a use of the coupon for a payment. The coupon's count
+	// is raised only while it is active and under its redemption limit, and
+	// the user's uses are checked against the per-user limit in the same
+	// transaction, so concurrent checkouts cannot exceed either. It fails
+	// with domain.ErrCouponExhausted or domain.ErrCouponUserLimit.
+	Redeem(ctx context.Context, redemption *domain.CouponRedemption) error
+	// SettleRedemption moves the payment's reserved redemption to status;
+	// releasing it gives the use back to the coupon. Redemptions that are
+	// no longer reserved, and payments without one, are left alone.
+	SettleRedemption(ctx context.Context, paymentID string, status domain.RedemptionStatus) error
+	// ListRedemptions returns a coupon's redemptions, newest first
+	ListRedemptions(ctx context.Context, couponID string, limit, offset int) ([]domain.CouponRedemption, error)
+}
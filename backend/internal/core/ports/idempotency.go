@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyLost is returned when a request completes or releases a
+// key it no longer owns, e.g. after its reservation expired and a retry
+// took the key over
+var ErrIdempotencyKeyLost = errors.New("idempotency key is no longer owned by this request")
+
+// IdempotencyRecord is the state stored for an idempotency key
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string
+	// Completed is false while the first request is still being handled
+	Completed bool
+	// Owner identifies the request handling an uncompleted key
+	Owner       string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore keeps idempotency keys and the responses they produced
+type IdempotencyStore interface {
+	// Begin reserves key for the request owner with the given fingerprint
+	// for up to lockTTL. It returns nil when owner now owns the key, or the
+	// existing record when the key was already used.
+	Begin(ctx context.Context, key, fingerprint, owner string, lockTTL time.Duration) (*IdempotencyRecord, error)
+
+	// Complete stores the response for a key reserved by owner for ttl,
+	// failing with ErrIdempotencyKeyLost if owner no longer holds it
+	Complete(ctx context.Context, key, owner string, record *IdempotencyRecord, ttl time.Duration) error
+
+	// Release frees a key reserved by owner so the request can be retried,
+	// failing with ErrIdempotencyKeyLost if owner no longer holds it
+	Release(ctx context.Context, key, owner string) error
+}
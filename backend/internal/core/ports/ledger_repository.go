@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// LedgerRepository defines the interface for double-entry ledger data access
+type LedgerRepository interface {
+	// GetOrCreateAccount returns the account of the given type and owner,
+	// creating it with a zero balance on first use
+	GetOrCreateAccount(ctx context.Context, accountType domain.AccountType, ownerID string) (*domain.LedgerAccount, error)
+
+	// Post records an entry together with the new balances of its accounts.
+	// Each account is only updated if it still has the version it was read
+	// at, failing with domain.ErrLedgerConflict otherwise. Entries whose kind
+	// and reference were recorded before fail with domain.ErrDuplicateEntry.
+	// Accounts are updated in the order given.
+	Post(ctx context.Context, entry *domain.JournalEntry, accounts []*domain.LedgerAccount) error
+
+	// GetEntry returns the entry of kind recorded for reference with its
+	// postings, failing with domain.ErrEntryNotFound if there is none
+	GetEntry(ctx context.Context, kind domain.JournalKind, reference string) (*domain.JournalEntry, error)
+
+	// ListStatements returns an account's postings, newest first
+	ListStatements(ctx context.Context, accountID string, limit, offset int) ([]domain.LedgerStatement, error)
+
+	// CheckConsistency re-sums the journal and reports unbalanced entries and
+	// accounts whose cached balance differs from their postings
+	CheckConsistency(ctx context.Context) ([]domain.LedgerMismatch, error)
+}
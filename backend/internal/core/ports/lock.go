@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// DistributedLock provides mutual exclusion between replicas
+type DistributedLock interface {
+	// TryLock acquires name for at most ttl without waiting. acquired is
+	// false when another holder has the lock. unlock releases the lock if it
+	// is still held by the caller.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(context.Context) error, acquired bool, err error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// UserNotifier pushes real-time events to a user's open connections
+type UserNotifier interface {
+	// NotifyUser delivers an event of the given type to every connection of
+	// the user. Users without open connections are silently skipped.
+	NotifyUser(ctx context.Context, userID string, eventType string, payload interface{}) error
+}
@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// OrganizationRepository defines the interface for tenant data access
+type OrganizationRepository interface {
+	// Organization operations
+	Create(ctx context.Context, org *domain.Organization) error
+	GetByID(ctx context.Context, id string) (*domain.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Organization, error)
+	ListForUser(ctx context.Context, userID string) ([]domain.Organization, error)
+
+	// Membership operations
+	AddMember(ctx context.Context, organizationID, userID string) error
+	// RemoveMember deletes a membership and the roles granted with it,
+	// failing with domain.ErrLastOrgOwner if userID is the only owner left
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	IsMember(ctx context.Context, organizationID, userID string) (bool, error)
+	// ListMembers lists the members of the tenant carried by ctx
+	ListMembers(ctx context.Context) ([]domain.Membership, error)
+
+	// Invitation operations
+	CreateInvitation(ctx context.Context, invitation *domain.Invitation) error
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, error)
+	MarkInvitationAccepted(ctx context.Context, id string) error
+}
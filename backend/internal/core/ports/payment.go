@@ -1,16 +1,130 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// GatewayError is a rejection reported by a payment gateway. It carries the
+// raw response so failed attempts can be audited like successful ones.
+type GatewayError struct {
+	Gateway string
+	Code    int
+	Message string
+	Raw     []byte
+}
+
+func (e *GatewayError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s error code %d: %s", e.Gateway, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s error code %d", e.Gateway, e.Code)
+}
+
+// PaymentRequestResult is what a gateway returns when a payment is initiated
+type PaymentRequestResult struct {
+	PaymentURL string
+	// Form, when set, holds fields that must be POSTed to PaymentURL; the
+	// user is sent there with an auto-submitting form instead of a redirect
+	Form      map[string]string
+	Authority string
+	// Raw is the gateway's response body, kept for auditing
+	Raw []byte
+}
+
+// PaymentVerifyResult is what a gateway returns when a payment is verified
+type PaymentVerifyResult struct {
+	RefID string
+	// AlreadyVerified is set when the gateway reports an earlier successful
+	// verification of the same payment
+	AlreadyVerified bool
+	CardPan         string
+	CardHash        string
+	// Raw is the gateway's response body, kept for auditing
+	Raw []byte
+}
+
+// PaymentIntent describes a payment to request from a gateway
+type PaymentIntent struct {
+	Amount      domain.Money
+	CallbackURL string
+	Description string
+	PayerMobile string
+	PayerEmail  string
+	// OrderID is the caller's reference, passed to gateways that accept one
+	OrderID  string
+	Metadata map[string]string
+	// ClientIP is the payer's address, used by the risk checks
+	ClientIP string
+	// CouponCode discounts the payment; PlanID is the plan it pays for, if
+	// any, checked against the coupon's plan restrictions
+	CouponCode string
+	PlanID     string
+}
 
 type PaymentGateway interface {
+	// Unit is the currency unit the gateway's API counts in; adapters
+	// convert amounts to it before sending them
+	Unit() domain.CurrencyUnit
+
 	// RequestPayment initiates a payment and returns the payment URL and Authority/ID
-	RequestPayment(ctx context.Context, amount int64, callbackURL string, description string, email string) (paymentURL string, authority string, err error)
+	RequestPayment(ctx context.Context, intent PaymentIntent) (*PaymentRequestResult, error)
 
 	// VerifyPayment verifies a payment after the user returns from the gateway
-	VerifyPayment(ctx context.Context, authority string, amount int64) (refID string, err error)
+	VerifyPayment(ctx context.Context, authority string, amount domain.Money) (*PaymentVerifyResult, error)
+}
+
+// GatewayConfigChecker is implemented by gateways that can tell whether
+// they are configured well enough to accept payments
+type GatewayConfigChecker interface {
+	CheckConfig() error
+}
+
+// RefundResult is what a gateway returns when a refund is accepted
+type RefundResult struct {
+	Reference string
+	// Raw is the gateway's response body, kept for auditing
+	Raw []byte
+}
+
+// RefundGateway is implemented by gateways that can return money to the
+// payer. Refund fails with domain.ErrRefundUnsupported when the gateway
+// cannot refund this payment or amount, in which case it is refunded
+// manually.
+type RefundGateway interface {
+	Refund(ctx context.Context, payment *domain.Payment, amount domain.Money, reason string) (*RefundResult, error)
+}
+
+// UnverifiedLister is implemented by gateways that can list payments the
+// user completed but the merchant never verified
+type UnverifiedLister interface {
+	// ListUnverified returns the authorities of unverified payments
+	ListUnverified(ctx context.Context) ([]string, error)
+}
+
+// PaymentCallback is what a gateway reports when it sends the user back
+type PaymentCallback struct {
+	Authority string
+	// Paid is false when the user cancelled or the gateway rejected the
+	// payment; such payments are not verified
+	Paid bool
+	// Reference is the gateway's transaction reference, set by gateways that
+	// verify with it rather than with the authority alone
+	Reference string
+}
+
+// CallbackParser is implemented by gateways that redirect the user back to
+// a callback URL with the outcome in query or form parameters
+type CallbackParser interface {
+	ParseCallback(params url.Values) PaymentCallback
 }
 
-type CardToCardGateway interface {
-	// SubmitReceipt allows a user to submit a transaction receipt for manual approval
-	SubmitReceipt(ctx context.Context, userID string, amount int64, receiptImageURL string, description string) (transactionID string, err error)
+// ReferenceVerifier is implemented by gateways, such as bank PSPs, that
+// verify a payment with the reference from its callback. Their
+// VerifyPayment fails with a *GatewayError since it has no reference.
+type ReferenceVerifier interface {
+	VerifyReference(ctx context.Context, authority, reference string, amount domain.Money) (*PaymentVerifyResult, error)
 }
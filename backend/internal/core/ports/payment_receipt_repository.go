@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// PaymentReceiptRepository defines the interface for payment receipt data
+// access
+type PaymentReceiptRepository interface {
+	// Create assigns the receipt the next number in its series and stores
+	// it. It fails with domain.ErrPaymentReceiptExists if the payment
+	// already has a receipt, without using up a number.
+	Create(ctx context.Context, receipt *domain.PaymentReceipt) error
+	GetByPaymentID(ctx context.Context, paymentID string) (*domain.PaymentReceipt, error)
+	// SetFile records where the receipt's PDF is stored
+	SetFile(ctx context.Context, id, fileKey string) error
+}
+
+// ReceiptRenderer renders a payment receipt as a PDF
+type ReceiptRenderer interface {
+	RenderReceipt(receipt *domain.PaymentReceipt, payer *domain.User) ([]byte, error)
+}
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// PaymentRepository defines the interface for payment data access
+type PaymentRepository interface {
+	// Create stores a new payment and its initial event
+	Create(ctx context.Context, payment *domain.Payment, event *domain.PaymentEvent) error
+	GetByID(ctx context.Context, id string) (*domain.Payment, error)
+	GetByAuthority(ctx context.Context, gateway, authority string) (*domain.Payment, error)
+
+	// Save persists a payment that was in status from, together with the
+	// event describing the change. It fails with
+	// domain.ErrPaymentConcurrentUpdate if the stored status is no longer from.
+	Save(ctx context.Context, payment *domain.Payment, from domain.PaymentStatus, event *domain.PaymentEvent) error
+	ListEvents(ctx context.Context, paymentID string) ([]domain.PaymentEvent, error)
+
+	// ListStale returns up to limit payments in one of statuses that have
+	// not changed since updatedBefore, those never reconciled first and then
+	// those reconciled longest ago
+	ListStale(ctx context.Context, statuses []domain.PaymentStatus, updatedBefore time.Time, limit int) ([]*domain.Payment, error)
+	// MarkReconciled records that the reconciler checked a payment at at
+	MarkReconciled(ctx context.Context, id string, at time.Time) error
+}
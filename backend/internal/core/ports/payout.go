@@ -0,0 +1,54 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// PayoutResult is a payout gateway's answer for one payout
+type PayoutResult struct {
+	// Reference is the gateway's transaction ID; empty when the gateway
+	// does not send payouts itself
+	Reference string
+	// Status is PayoutProcessing while the transfer is under way, or
+	// PayoutPaid or PayoutFailed once the gateway knows its outcome
+	Status domain.PayoutStatus
+	// Note explains a failure
+	Note string
+	// Err is set when the payout could not be submitted; a *GatewayError
+	// means the gateway refused it, anything else leaves its outcome unknown
+	Err error
+	// Raw is the gateway's response body, kept for auditing
+	Raw []byte
+}
+
+// PayoutBatch is what a payout gateway returns for a dispatch
+type PayoutBatch struct {
+	// Results holds one result per payout, in the order they were given
+	Results []PayoutResult
+	// File is a bank batch file for finance to upload, when the gateway
+	// produces one, and FileName its suggested name
+	File     []byte
+	FileName string
+}
+
+// PayoutGateway sends approved payouts to bank accounts
+type PayoutGateway interface {
+	// SendPayouts submits payouts, all of which belong to batchID. Payouts
+	// are identified by their ID so a resubmission is not paid twice.
+	SendPayouts(ctx context.Context, batchID string, payouts []domain.Payout) (*PayoutBatch, error)
+}
+
+// PayoutStatusChecker is implemented by payout gateways that can report how
+// a submitted payout went
+type PayoutStatusChecker interface {
+	PayoutStatus(ctx context.Context, payout *domain.Payout) (*PayoutResult, error)
+}
+
+// PayoutExporter is implemented by payout gateways that hand payouts to
+// finance as a bank batch file instead of sending them
+type PayoutExporter interface {
+	// ExportPayouts renders the batch file of a dispatch and its name
+	ExportPayouts(batchID string, payouts []domain.Payout) ([]byte, string, error)
+}
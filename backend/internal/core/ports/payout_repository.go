@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// PayoutRepository defines the interface for payout data access
+type PayoutRepository interface {
+	Create(ctx context.Context, payout *domain.Payout) error
+	GetByID(ctx context.Context, id string) (*domain.Payout, error)
+	// ListByUser returns a user's payouts, newest first
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payout, error)
+	// ListByStatus returns payouts in status, oldest first
+	ListByStatus(ctx context.Context, status domain.PayoutStatus, limit, offset int) ([]domain.Payout, error)
+	// ListByBatch returns the payouts of a dispatch in the order they were
+	// sent
+	ListByBatch(ctx context.Context, batchID string) ([]domain.Payout, error)
+
+	// Update persists a payout's status, gateway, batch, reference,
+	// reviewers, note and payment time if it is still in status from,
+	// failing with domain.ErrPayoutConflict otherwise
+	Update(ctx context.Context, payout *domain.Payout, from domain.PayoutStatus) error
+
+	// ListUnsettled returns final payouts whose ledger entry is missing:
+	// paid payouts not yet settled and others whose amount was not yet
+	// returned to the wallet
+	ListUnsettled(ctx context.Context, limit int) ([]domain.Payout, error)
+}
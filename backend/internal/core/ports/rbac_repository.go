@@ -21,6 +21,7 @@ type RBACRepository interface {
 	GetPermissionByName(ctx context.Context, name string) (*domain.Permission, error)
 	GetAllPermissions(ctx context.Context) ([]domain.Permission, error)
 	CreatePermission(ctx context.Context, permission *domain.Permission) error
+	UpdatePermission(ctx context.Context, permission *domain.Permission) error
 	DeletePermission(ctx context.Context, id string) error
 
 	// Role-Permission associations
@@ -35,4 +36,14 @@ type RBACRepository interface {
 	GetUserPermissions(ctx context.Context, userID string) ([]domain.Permission, error)
 	UserHasPermission(ctx context.Context, userID, permissionName string) (bool, error)
 	UserHasRole(ctx context.Context, userID, roleName string) (bool, error)
+
+	// Role grants (time-bound and scoped assignments)
+	GrantRole(ctx context.Context, grant *domain.UserRole) error
+	RevokeRoleGrant(ctx context.Context, userID, roleID, scope string) error
+	ListRoleGrants(ctx context.Context, filter domain.RoleGrantFilter) ([]domain.UserRole, error)
+	// UserHasPermissionInScope and UserHasRoleInScope only count grants made
+	// in scope; global grants do not apply inside an organization
+	UserHasPermissionInScope(ctx context.Context, userID, permissionName, scope string) (bool, error)
+	UserHasRoleInScope(ctx context.Context, userID, roleName, scope string) (bool, error)
+	DeleteExpiredRoleGrants(ctx context.Context) (userIDs []string, err error)
 }
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// RefundRepository defines the interface for refund data access
+type RefundRepository interface {
+	Create(ctx context.Context, refund *domain.Refund) error
+	GetByID(ctx context.Context, id string) (*domain.Refund, error)
+	ListByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error)
+	// ListByStatus returns refunds in status, oldest first
+	ListByStatus(ctx context.Context, status domain.RefundStatus, limit, offset int) ([]domain.Refund, error)
+	// Update persists a refund's method, status, reference, processor and
+	// note if it is still in status from, failing with
+	// domain.ErrRefundConflict otherwise
+	Update(ctx context.Context, refund *domain.Refund, from domain.RefundStatus) error
+
+	// OpenAmount sums the refunds of a payment that may still complete
+	OpenAmount(ctx context.Context, paymentID string) (int64, error)
+}
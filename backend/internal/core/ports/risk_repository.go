@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// RiskRepository defines the data access the payment risk checks need
+type RiskRepository interface {
+	// Activity counts the user's payments and, when clientIP is set, the
+	// IP's payments created since velocitySince, and sums the amounts of the
+	// user's payments since daySince that were not failed, cancelled or
+	// expired
+	Activity(ctx context.Context, userID, clientIP string, velocitySince, daySince time.Time) (*domain.RiskActivity, error)
+
+	IsBlocked(ctx context.Context, kind domain.BlocklistKind, value string) (bool, error)
+	// ListBlocked lists blocklist entries, of every kind when kind is empty
+	ListBlocked(ctx context.Context, kind domain.BlocklistKind, limit, offset int) ([]domain.BlocklistEntry, error)
+	// Block stores an entry, failing with domain.ErrBlocklistEntryExists if
+	// the value is already blocked
+	Block(ctx context.Context, entry *domain.BlocklistEntry) error
+	// Unblock removes an entry, failing with domain.ErrBlocklistEntryNotFound
+	Unblock(ctx context.Context, id string) error
+}
@@ -5,4 +5,7 @@ import "context"
 type SMSGateway interface {
 	// SendOTP sends a one-time password to the given phone number
 	SendOTP(ctx context.Context, phoneNumber string, code string) error
+
+	// SendMessage sends a free-form notification to the given phone number
+	SendMessage(ctx context.Context, phoneNumber string, message string) error
 }
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// FileStorage stores uploaded files in an object storage bucket
+type FileStorage interface {
+	UploadFile(ctx context.Context, key string, file io.Reader) (string, error)
+	GeneratePresignedURL(ctx context.Context, key string, lifetimeSecs int64) (string, error)
+	// GenerateDownloadURL returns a link that downloads the object under key
+	// as fileName until it expires
+	GenerateDownloadURL(ctx context.Context, key, fileName string, lifetimeSecs int64) (string, error)
+
+	// StatObject describes the object stored under key, failing with
+	// ErrObjectNotFound when there is none
+	StatObject(ctx context.Context, key string) (*ObjectInfo, error)
+}
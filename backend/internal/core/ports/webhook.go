@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// WebhookResponse is what an endpoint answered to a delivery
+type WebhookResponse struct {
+	StatusCode int
+	// Body is the start of the response body, kept for the delivery log
+	Body string
+}
+
+// WebhookSender posts signed deliveries to merchant endpoints
+type WebhookSender interface {
+	// Deliver sends a delivery's payload to endpoint. An error means no
+	// response was received; any response, successful or not, is returned
+	// without one.
+	Deliver(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (*WebhookResponse, error)
+}
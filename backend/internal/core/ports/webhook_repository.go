@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// WebhookRepository defines the interface for webhook endpoint, delivery
+// queue and delivery log data access. Endpoints are scoped by organization
+// ID; an empty ID is the app-wide scope.
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	// ListEndpoints returns the endpoints of a scope, oldest first
+	ListEndpoints(ctx context.Context, organizationID string) ([]domain.WebhookEndpoint, error)
+	// ListActiveEndpoints returns the active endpoints told about payments
+	// of organizationID: its own and the app-wide ones
+	ListActiveEndpoints(ctx context.Context, organizationID string) ([]domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	// Enqueue queues a delivery unless the endpoint already has one for the
+	// event, in which case it does nothing and reports false
+	Enqueue(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error)
+	// ClaimDue takes the pending delivery due the longest to an active
+	// endpoint and postpones it by lease, so other replicas skip it while it
+	// is being sent. It fails with domain.ErrWebhookDeliveryNotFound when
+	// nothing is due.
+	ClaimDue(ctx context.Context, lease time.Duration) (*domain.WebhookDelivery, error)
+	// RecordAttempt persists a delivery after an attempt together with the
+	// attempt's log entry
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error
+	// UpdateDelivery persists a delivery's status and schedule
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	// ListDeliveries returns an endpoint's deliveries, newest first,
+	// optionally only those in status
+	ListDeliveries(ctx context.Context, endpointID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]domain.WebhookDelivery, error)
+	// ListAttempts returns the delivery log of a delivery, oldest first
+	ListAttempts(ctx context.Context, deliveryID string) ([]domain.WebhookAttempt, error)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// AuthzExplainer explains why a permission check passes or fails
+type AuthzExplainer struct {
+	rbacRepo ports.RBACRepository
+}
+
+// NewAuthzExplainer creates a new authorization explainer
+func NewAuthzExplainer(rbacRepo ports.RBACRepository) *AuthzExplainer {
+	return &AuthzExplainer{rbacRepo: rbacRepo}
+}
+
+// Explain evaluates all of a user's grants, including expired ones, against
+// a permission in the given scope
+func (e *AuthzExplainer) Explain(ctx context.Context, userID, permission, scope string) (*domain.AuthzDecision, error) {
+	grants, err := e.rbacRepo.ListRoleGrants(ctx, domain.RoleGrantFilter{UserID: userID, IncludeExpired: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role grants: %w", err)
+	}
+
+	rolePermissions := make(map[string][]domain.Permission)
+	for _, grant := range grants {
+		if _, ok := rolePermissions[grant.RoleID]; ok {
+			continue
+		}
+		perms, err := e.rbacRepo.GetRolePermissions(ctx, grant.RoleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load permissions of role %s: %w", grant.RoleName, err)
+		}
+		rolePermissions[grant.RoleID] = perms
+	}
+
+	return domain.ExplainPermission(userID, permission, scope, grants, rolePermissions, time.Now()), nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const billingLockName = "billing:renewals"
+
+// BillingSchedulerConfig controls the billing scheduler
+type BillingSchedulerConfig struct {
+	Interval time.Duration
+	// RenewalLead is how long before a period ends its renewal invoice is
+	// issued
+	RenewalLead time.Duration
+	// RemindEvery is how often users are reminded of an open invoice
+	RemindEvery time.Duration
+	BatchSize   int
+}
+
+// BillingScheduler issues renewal invoices ahead of period ends, reminds
+// users of open invoices and lapses subscriptions that were not renewed
+type BillingScheduler struct {
+	billing *BillingService
+	lock    ports.DistributedLock
+	cfg     BillingSchedulerConfig
+}
+
+// NewBillingScheduler creates a scheduler; only one replica runs it at a
+// time through lock
+func NewBillingScheduler(billing *BillingService, lock ports.DistributedLock, cfg BillingSchedulerConfig) *BillingScheduler {
+	return &BillingScheduler{billing: billing, lock: lock, cfg: cfg}
+}
+
+// Run schedules billing until the context is cancelled
+func (s *BillingScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Tick(ctx)
+		}
+	}
+}
+
+// Tick runs one pass unless another replica holds the lock
+func (s *BillingScheduler) Tick(ctx context.Context) {
+	unlock, acquired, err := s.lock.TryLock(ctx, billingLockName, s.cfg.Interval)
+	if err != nil {
+		logger.Log.Error("Failed to acquire billing lock", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if err := unlock(ctx); err != nil {
+			logger.Log.Warn("Failed to release billing lock", zap.Error(err))
+		}
+	}()
+
+	now := time.Now()
+	s.issueRenewals(ctx, now)
+	s.sendReminders(ctx, now)
+	s.lapse(ctx, now)
+}
+
+// issueRenewals invoices the next period of subscriptions ending within the
+// renewal lead, on the plan they are switching to if any. Free plans renew
+// without an invoice.
+func (s *BillingScheduler) issueRenewals(ctx context.Context, now time.Time) {
+	repo := s.billing.repo
+
+	subs, err := repo.ListRenewable(ctx, now.Add(s.cfg.RenewalLead), s.cfg.BatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to list renewable subscriptions", zap.Error(err))
+		return
+	}
+
+	for i := range subs {
+		sub := &subs[i]
+		fields := []zap.Field{zap.String("subscription_id", sub.ID)}
+
+		planID := sub.PlanID
+		if sub.PendingPlanID != "" {
+			planID = sub.PendingPlanID
+		}
+		plan, err := repo.GetPlan(ctx, planID)
+		if err != nil {
+			logger.Log.Error("Failed to load renewal plan", append(fields, zap.Error(err))...)
+			continue
+		}
+
+		if plan.Price == 0 {
+			if err := sub.Renew(plan); err != nil {
+				logger.Log.Error("Failed to renew free subscription", append(fields, zap.Error(err))...)
+				continue
+			}
+			if err := repo.UpdateSubscription(ctx, sub); err != nil {
+				logger.Log.Error("Failed to renew free subscription", append(fields, zap.Error(err))...)
+			}
+			continue
+		}
+
+		start := sub.CurrentPeriodEnd
+		inv, err := domain.NewInvoice(sub, plan.ID, domain.InvoiceRenewal, plan.Price, start, start.Add(plan.Period()), start)
+		if err != nil {
+			logger.Log.Error("Failed to create renewal invoice", append(fields, zap.Error(err))...)
+			continue
+		}
+		if err := repo.CreateInvoice(ctx, inv); err != nil {
+			if !errors.Is(err, domain.ErrInvoiceExists) {
+				logger.Log.Error("Failed to create renewal invoice", append(fields, zap.Error(err))...)
+			}
+			continue
+		}
+		logger.Log.Info("Issued renewal invoice", append(fields, zap.String("invoice_id", inv.ID))...)
+		s.remind(ctx, inv, now)
+	}
+}
+
+// sendReminders reminds users of invoices still open since the last reminder
+func (s *BillingScheduler) sendReminders(ctx context.Context, now time.Time) {
+	invoices, err := s.billing.repo.ListUnreminded(ctx, now.Add(-s.cfg.RemindEvery), s.cfg.BatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to list invoices to remind", zap.Error(err))
+		return
+	}
+	for i := range invoices {
+		s.remind(ctx, &invoices[i], now)
+	}
+}
+
+func (s *BillingScheduler) remind(ctx context.Context, inv *domain.Invoice, now time.Time) {
+	s.billing.remind(ctx, inv)
+	if err := s.billing.repo.MarkReminded(ctx, inv.ID, now); err != nil {
+		logger.Log.Error("Failed to record invoice reminder", zap.String("invoice_id", inv.ID), zap.Error(err))
+	}
+}
+
+// lapse moves subscriptions whose period ended to past due, and those whose
+// grace period ended or that were cancelled to their final state
+func (s *BillingScheduler) lapse(ctx context.Context, now time.Time) {
+	repo := s.billing.repo
+
+	subs, err := repo.ListLapsed(ctx, now, s.cfg.BatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to list lapsed subscriptions", zap.Error(err))
+		return
+	}
+
+	for i := range subs {
+		sub := &subs[i]
+		fields := []zap.Field{zap.String("subscription_id", sub.ID)}
+
+		plan, err := repo.GetPlan(ctx, sub.PlanID)
+		if err != nil {
+			logger.Log.Error("Failed to load subscription plan", append(fields, zap.Error(err))...)
+			continue
+		}
+		sub.Lapse(now, plan.GracePeriod())
+		if err := repo.UpdateSubscription(ctx, sub); err != nil {
+			logger.Log.Error("Failed to lapse subscription", append(fields, zap.Error(err))...)
+			continue
+		}
+		if sub.Status == domain.SubscriptionExpired || sub.Status == domain.SubscriptionCancelled {
+			if err := repo.VoidOpenInvoices(ctx, sub.ID, ""); err != nil {
+				logger.Log.Error("Failed to void invoices of ended subscription", append(fields, zap.Error(err))...)
+			}
+		}
+		logger.Log.Info("Lapsed subscription", append(fields, zap.String("status", string(sub.Status)))...)
+	}
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// InvoiceMetadataKey links a payment to the invoice it pays
+const InvoiceMetadataKey = "invoice_id"
+
+// ErrInvalidPayLink is returned for pay links with a wrong signature
+var ErrInvalidPayLink = errors.New("invalid pay link")
+
+// BillingConfig controls invoice pay links
+type BillingConfig struct {
+	// PayLinkBaseURL is the public URL of the invoices API; reminders link
+	// to PayLinkBaseURL/{invoice}/pay
+	PayLinkBaseURL string
+	// LinkSecret signs pay links so they work without logging in
+	LinkSecret []byte
+}
+
+// BillingService runs subscriptions on renewal invoices, since gateways
+// cannot charge a card on file. Invoices are paid through any gateway and
+// settled when the payment is verified.
+type BillingService struct {
+	repo     ports.BillingRepository
+	payments *PaymentService
+	userRepo ports.UserRepository
+	email    ports.EmailService
+	sms      ports.SMSGateway
+	cfg      BillingConfig
+}
+
+// NewBillingService creates a billing service and subscribes it to payment
+// status changes
+func NewBillingService(repo ports.BillingRepository, payments *PaymentService, userRepo ports.UserRepository, email ports.EmailService, sms ports.SMSGateway, cfg BillingConfig) *BillingService {
+	s := &BillingService{repo: repo, payments: payments, userRepo: userRepo, email: email, sms: sms, cfg: cfg}
+	payments.OnStatusChange(s.HandlePayment)
+	return s
+}
+
+// Plans returns the plans users can subscribe to
+func (s *BillingService) Plans(ctx context.Context) ([]domain.Plan, error) {
+	return s.repo.ListPlans(ctx, true)
+}
+
+// Current returns the user's current subscription and its plan
+func (s *BillingService) Current(ctx context.Context, userID string) (*domain.Subscription, *domain.Plan, error) {
+	sub, err := s.repo.GetCurrentSubscription(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := s.repo.GetPlan(ctx, sub.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sub, plan, nil
+}
+
+// Invoices returns the user's invoices, newest first
+func (s *BillingService) Invoices(ctx context.Context, userID string, limit, offset int) ([]domain.Invoice, error) {
+	return s.repo.ListInvoices(ctx, userID, limit, offset)
+}
+
+// activePlan returns a plan users may subscribe or switch to
+func (s *BillingService) activePlan(ctx context.Context, code string) (*domain.Plan, error) {
+	plan, err := s.repo.GetPlanByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, domain.ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+// Subscribe starts a subscription to the plan. Paid plans start pending with
+// an initial invoice; an unpaid pending subscription is replaced.
+func (s *BillingService) Subscribe(ctx context.Context, userID, planCode string) (*domain.Subscription, *domain.Invoice, error) {
+	plan, err := s.activePlan(ctx, planCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := s.repo.GetCurrentSubscription(ctx, userID)
+	switch {
+	case err == nil && current.Status != domain.SubscriptionPending:
+		return nil, nil, domain.ErrAlreadySubscribed
+	case err == nil:
+		if err := s.end(ctx, current); err != nil {
+			return nil, nil, err
+		}
+	case !errors.Is(err, domain.ErrSubscriptionNotFound):
+		return nil, nil, err
+	}
+
+	sub := domain.NewSubscription(userID, plan)
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, nil, err
+	}
+	if sub.Status != domain.SubscriptionPending {
+		return sub, nil, nil
+	}
+
+	now := time.Now()
+	inv, err := domain.NewInvoice(sub, plan.ID, domain.InvoiceInitial, plan.Price, now, now.Add(plan.Period()), now)
+	if err != nil {
+		return sub, nil, err
+	}
+	if err := s.repo.CreateInvoice(ctx, inv); err != nil {
+		return sub, nil, err
+	}
+	return sub, inv, nil
+}
+
+// ChangePlan moves the user to another plan. Upgrades are charged the
+// prorated difference for the rest of the period and take effect when that
+// invoice is paid; downgrades take effect at the next renewal.
+func (s *BillingService) ChangePlan(ctx context.Context, userID, planCode string) (*domain.Subscription, *domain.Invoice, error) {
+	sub, current, err := s.Current(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sub.Status != domain.SubscriptionActive && sub.Status != domain.SubscriptionPastDue {
+		return nil, nil, fmt.Errorf("%w: cannot change the plan of a %s subscription", domain.ErrInvalidSubscriptionState, sub.Status)
+	}
+	next, err := s.activePlan(ctx, planCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if next.ID == current.ID {
+		return nil, nil, fmt.Errorf("%w: already on plan %s", domain.ErrInvalidPlan, next.Code)
+	}
+
+	// A newer change replaces any earlier one
+	if err := s.repo.VoidOpenInvoices(ctx, sub.ID, domain.InvoiceProration); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if amount := domain.Prorate(current, next, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, now); amount > 0 {
+		inv, err := domain.NewInvoice(sub, next.ID, domain.InvoiceProration, amount, now, sub.CurrentPeriodEnd, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.repo.CreateInvoice(ctx, inv); err != nil {
+			return nil, nil, err
+		}
+		return sub, inv, nil
+	}
+
+	sub.PendingPlanID = next.ID
+	sub.UpdatedAt = now
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, nil, err
+	}
+	// A renewal already issued at the old price is reissued for the new plan
+	if err := s.repo.VoidOpenInvoices(ctx, sub.ID, domain.InvoiceRenewal); err != nil {
+		return nil, nil, err
+	}
+	return sub, nil, nil
+}
+
+// Cancel stops the user's subscription at the end of its period
+func (s *BillingService) Cancel(ctx context.Context, userID string) (*domain.Subscription, error) {
+	sub, err := s.repo.GetCurrentSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == domain.SubscriptionPending {
+		return sub, s.end(ctx, sub)
+	}
+	if err := sub.Cancel(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, s.repo.VoidOpenInvoices(ctx, sub.ID, domain.InvoiceRenewal)
+}
+
+// end cancels a pending subscription and voids its invoices
+func (s *BillingService) end(ctx context.Context, sub *domain.Subscription) error {
+	if err := sub.Cancel(); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+	return s.repo.VoidOpenInvoices(ctx, sub.ID, "")
+}
+
+// PayInvoice starts a payment of one of the user's open invoices,
+// discounted with couponCode when it is not empty
+func (s *BillingService) PayInvoice(ctx context.Context, userID, invoiceID, gatewayName, callbackURL, couponCode string) (*domain.Payment, *ports.PaymentRequestResult, error) {
+	inv, err := s.repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if inv.UserID != userID {
+		return nil, nil, domain.ErrInvoiceNotFound
+	}
+	return s.pay(ctx, inv, gatewayName, callbackURL, couponCode)
+}
+
+// PayInvoiceWithLink starts a payment from a reminder's signed pay link
+func (s *BillingService) PayInvoiceWithLink(ctx context.Context, invoiceID, token, gatewayName, callbackURL string) (*domain.Payment, *ports.PaymentRequestResult, error) {
+	if !hmac.Equal([]byte(token), []byte(s.payLinkToken(invoiceID))) {
+		return nil, nil, ErrInvalidPayLink
+	}
+	inv, err := s.repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.pay(ctx, inv, gatewayName, callbackURL, "")
+}
+
+func (s *BillingService) pay(ctx context.Context, inv *domain.Invoice, gatewayName, callbackURL, couponCode string) (*domain.Payment, *ports.PaymentRequestResult, error) {
+	if inv.Status != domain.InvoiceOpen {
+		return nil, nil, fmt.Errorf("%w: invoice is %s", domain.ErrInvoiceNotPayable, inv.Status)
+	}
+	user, err := s.userRepo.GetByID(ctx, inv.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := s.repo.GetPlan(ctx, inv.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	intent := ports.PaymentIntent{
+		Amount:      domain.Rials(inv.Amount),
+		CallbackURL: callbackURL,
+		Description: fmt.Sprintf("%s subscription (%s)", plan.Name, inv.Kind),
+		PayerMobile: user.Phone,
+		PayerEmail:  user.Email,
+		OrderID:     inv.ID,
+		Metadata:    map[string]string{InvoiceMetadataKey: inv.ID},
+		CouponCode:  couponCode,
+		PlanID:      inv.PlanID,
+	}
+	return s.payments.Start(ctx, inv.UserID, gatewayName, intent)
+}
+
+// payLinkToken signs an invoice ID for its pay link
+func (s *BillingService) payLinkToken(invoiceID string) string {
+	mac := hmac.New(sha256.New, s.cfg.LinkSecret)
+	mac.Write([]byte(invoiceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PayLink returns the URL reminders send users to for paying an invoice
+func (s *BillingService) PayLink(invoiceID string) string {
+	return fmt.Sprintf("%s/%s/pay?token=%s", s.cfg.PayLinkBaseURL, invoiceID, s.payLinkToken(invoiceID))
+}
+
+// HandlePayment settles the invoice a verified payment was made for. It is
+// registered as a payment listener.
+func (s *BillingService) HandlePayment(ctx context.Context, payment *domain.Payment) {
+	invoiceID := payment.Metadata[InvoiceMetadataKey]
+	if invoiceID == "" || payment.Status != domain.PaymentVerified {
+		return
+	}
+
+	if err := s.settle(ctx, invoiceID, payment); err != nil {
+		// The money arrived but bought nothing, e.g. because the invoice was
+		// voided meanwhile; finance refunds it
+		logger.Log.Error("Failed to settle invoice payment",
+			zap.String("invoice_id", invoiceID), zap.String("payment_id", payment.ID), zap.Error(err))
+	}
+}
+
+func (s *BillingService) settle(ctx context.Context, invoiceID string, payment *domain.Payment) error {
+	inv, err := s.repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if inv.Status == domain.InvoicePaid && inv.PaymentID == payment.ID {
+		return nil
+	}
+	// Coupons make up the part of the invoice the payment did not cover
+	if inv.UserID != payment.UserID || payment.Amount+payment.Discount < inv.Amount {
+		return fmt.Errorf("%w: payment does not match the invoice", domain.ErrInvoiceNotPayable)
+	}
+
+	sub, err := s.repo.GetSubscription(ctx, inv.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	plan, err := s.repo.GetPlan(ctx, inv.PlanID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := inv.MarkPaid(payment.ID, now); err != nil {
+		return err
+	}
+	if err := inv.Apply(sub, plan, now); err != nil {
+		return err
+	}
+	if err := s.repo.PayInvoice(ctx, inv, sub); err != nil {
+		return err
+	}
+
+	if inv.Kind == domain.InvoiceProration {
+		// A renewal already issued at the old price is reissued for the
+		// new plan
+		return s.repo.VoidOpenInvoices(ctx, sub.ID, domain.InvoiceRenewal)
+	}
+	return nil
+}
+
+// remind sends the invoice's pay link by SMS and email; delivery is best
+// effort
+func (s *BillingService) remind(ctx context.Context, inv *domain.Invoice) {
+	fields := []zap.Field{zap.String("invoice_id", inv.ID)}
+
+	user, err := s.userRepo.GetByID(ctx, inv.UserID)
+	if err != nil {
+		logger.Log.Warn("Failed to load user for invoice reminder", append(fields, zap.Error(err))...)
+		return
+	}
+	plan, err := s.repo.GetPlan(ctx, inv.PlanID)
+	if err != nil {
+		logger.Log.Warn("Failed to load plan for invoice reminder", append(fields, zap.Error(err))...)
+		return
+	}
+
+	message := fmt.Sprintf("Your %s subscription invoice of %s is due %s. Pay here: %s",
+		plan.Name, domain.Rials(inv.Amount).Format("en"), inv.DueAt.Format("2006-01-02"), s.PayLink(inv.ID))
+	if user.Phone != "" {
+		if err := s.sms.SendMessage(ctx, user.Phone, message); err != nil {
+			logger.Log.Warn("Failed to send invoice reminder SMS", append(fields, zap.Error(err))...)
+		}
+	}
+	if user.Email != "" {
+		if err := s.email.SendEmail(ctx, []string{user.Email}, "Invoice for "+plan.Name, message); err != nil {
+			logger.Log.Warn("Failed to send invoice reminder email", append(fields, zap.Error(err))...)
+		}
+	}
+}
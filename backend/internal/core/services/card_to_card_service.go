@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxReceiptImageSize bounds receipt uploads
+const maxReceiptImageSize = 10 << 20
+
+// receiptUploadTTL is how long a receipt upload URL stays valid, in seconds
+const receiptUploadTTL = 300
+
+// CardReceiptKeyPrefix is where receipt images are stored, one folder per
+// user; other uploads must not be written under it
+const CardReceiptKeyPrefix = "card-receipts/"
+
+var receiptImageTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// CardToCardService handles card-to-card transfers: users submit a receipt,
+// which creates a pending payment, and admins approve or reject it
+type CardToCardService struct {
+	payments    *PaymentService
+	receiptRepo ports.CardReceiptRepository
+	userRepo    ports.UserRepository
+	storage     ports.FileStorage
+	sms         ports.SMSGateway
+}
+
+// NewCardToCardService creates a card-to-card service; storage may be nil
+// when no bucket is configured, in which case receipts are refused
+func NewCardToCardService(payments *PaymentService, receiptRepo ports.CardReceiptRepository, userRepo ports.UserRepository, storage ports.FileStorage, sms ports.SMSGateway) *CardToCardService {
+	return &CardToCardService{
+		payments:    payments,
+		receiptRepo: receiptRepo,
+		userRepo:    userRepo,
+		storage:     storage,
+		sms:         sms,
+	}
+}
+
+// Submit stores a receipt and its pending payment. Receipts that look like
+// duplicates are accepted but flagged for the reviewer.
+func (s *CardToCardService) Submit(ctx context.Context, userID string, amount int64, trackingNumber, imageKey string, paidAt time.Time, description string) (*domain.CardReceipt, *domain.Payment, error) {
+	receipt, err := domain.NewCardReceipt(userID, amount, trackingNumber, imageKey, paidAt, description)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path.Clean(imageKey) != imageKey || !strings.HasPrefix(imageKey, receiptKeyPrefix(userID)) {
+		return nil, nil, fmt.Errorf("%w: receipt image was not uploaded by this user", domain.ErrInvalidReceipt)
+	}
+	if err := s.checkImage(ctx, imageKey); err != nil {
+		return nil, nil, err
+	}
+
+	others, err := s.receiptRepo.FindPossibleDuplicates(ctx, receipt.TrackingNumber, imageKey, amount, paidAt, domain.DuplicateReceiptWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipt.CheckDuplicates(others)
+
+	payment, err := domain.NewPayment(userID, GatewayCardToCard, amount, description)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.payments.create(ctx, payment); err != nil {
+		return nil, nil, err
+	}
+
+	receipt.PaymentID = payment.ID
+	if err := s.receiptRepo.Create(ctx, receipt); err != nil {
+		if tErr := s.payments.Transition(ctx, payment, domain.PaymentFailed, nil, "storing receipt failed"); tErr != nil {
+			return nil, nil, errors.Join(err, tErr)
+		}
+		return nil, nil, err
+	}
+
+	// The receipt ID identifies the payment, like a gateway authority
+	payment.Authority = receipt.ID
+	note := "receipt " + receipt.TrackingNumber
+	if receipt.IsFlagged() {
+		note = joinNotes(note, "flagged: "+strings.Join(receipt.Flags, "; "))
+	}
+	if err := s.payments.Transition(ctx, payment, domain.PaymentPending, nil, note); err != nil {
+		return receipt, payment, err
+	}
+	return receipt, payment, nil
+}
+
+// ReceiptUploadURL issues a key for a user's receipt image and a short-lived
+// URL to upload it to; Submit only accepts keys issued to the same user
+func (s *CardToCardService) ReceiptUploadURL(ctx context.Context, userID string) (url, key string, err error) {
+	if s.storage == nil {
+		return "", "", errors.New("storage is not configured")
+	}
+	key = receiptKeyPrefix(userID) + uuid.NewString()
+	url, err = s.storage.GeneratePresignedURL(ctx, key, receiptUploadTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return url, key, nil
+}
+
+func receiptKeyPrefix(userID string) string {
+	return CardReceiptKeyPrefix + userID + "/"
+}
+
+// IsCardReceiptKey reports whether key would be stored among receipt images
+func IsCardReceiptKey(key string) bool {
+	return strings.HasPrefix(path.Clean("/"+key), "/"+CardReceiptKeyPrefix)
+}
+
+// checkImage verifies the receipt image was uploaded to our bucket
+func (s *CardToCardService) checkImage(ctx context.Context, key string) error {
+	if s.storage == nil {
+		return errors.New("storage is not configured")
+	}
+
+	info, err := s.storage.StatObject(ctx, key)
+	if errors.Is(err, ports.ErrObjectNotFound) {
+		return fmt.Errorf("%w: receipt image was not uploaded", domain.ErrInvalidReceipt)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size == 0 || info.Size > maxReceiptImageSize {
+		return fmt.Errorf("%w: receipt image must be between 1 byte and %d MB", domain.ErrInvalidReceipt, maxReceiptImageSize>>20)
+	}
+	if info.ContentType != "" && !receiptImageTypes[strings.ToLower(info.ContentType)] {
+		return fmt.Errorf("%w: unsupported receipt image type %s", domain.ErrInvalidReceipt, info.ContentType)
+	}
+	return nil
+}
+
+// Approve accepts a receipt and verifies its payment
+func (s *CardToCardService) Approve(ctx context.Context, receiptID, reviewerID, reason string) (*domain.CardReceipt, error) {
+	return s.review(ctx, receiptID, reviewerID, reason, true)
+}
+
+// Reject declines a receipt and fails its payment
+func (s *CardToCardService) Reject(ctx context.Context, receiptID, reviewerID, reason string) (*domain.CardReceipt, error) {
+	return s.review(ctx, receiptID, reviewerID, reason, false)
+}
+
+func (s *CardToCardService) review(ctx context.Context, receiptID, reviewerID, reason string, approve bool) (*domain.CardReceipt, error) {
+	receipt, err := s.receiptRepo.GetByID(ctx, receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := domain.PaymentVerified
+	if approve {
+		err = receipt.Approve(reviewerID, reason)
+	} else {
+		next = domain.PaymentFailed
+		err = receipt.Reject(reviewerID, reason)
+	}
+	if err != nil {
+		return receipt, err
+	}
+	if err := s.receiptRepo.SaveReview(ctx, receipt); err != nil {
+		return receipt, err
+	}
+
+	payment, err := s.payments.paymentRepo.GetByID(ctx, receipt.PaymentID)
+	if err != nil {
+		return receipt, err
+	}
+	if approve {
+		payment.RefID = receipt.TrackingNumber
+	}
+	note := fmt.Sprintf("card-to-card %s by %s", receipt.Status, reviewerID)
+	if reason != "" {
+		note = joinNotes(note, reason)
+	}
+	if err := s.payments.Transition(ctx, payment, next, nil, note); err != nil {
+		return receipt, err
+	}
+
+	s.payments.notify(ctx, payment)
+	s.notifySMS(ctx, receipt)
+	return receipt, nil
+}
+
+// notifySMS tells the user about the review outcome; delivery is best effort
+func (s *CardToCardService) notifySMS(ctx context.Context, receipt *domain.CardReceipt) {
+	user, err := s.userRepo.GetByID(ctx, receipt.UserID)
+	if err != nil || user.Phone == "" {
+		return
+	}
+
+	message := fmt.Sprintf("Your card-to-card payment %s was approved.", receipt.TrackingNumber)
+	if receipt.Status == domain.ReceiptRejected {
+		message = fmt.Sprintf("Your card-to-card payment %s was rejected: %s", receipt.TrackingNumber, receipt.ReviewReason)
+	}
+	if err := s.sms.SendMessage(ctx, user.Phone, message); err != nil {
+		logger.Log.Warn("Failed to send card-to-card review SMS", zap.String("receipt_id", receipt.ID), zap.Error(err))
+	}
+}
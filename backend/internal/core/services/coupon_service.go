@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// CouponService applies promo codes to payments. A use of the coupon is
+// reserved when the payment is created, confirmed once it is verified and
+// given back if it never completes.
+type CouponService struct {
+	repo ports.CouponRepository
+}
+
+func NewCouponService(repo ports.CouponRepository) *CouponService {
+	return &CouponService{repo: repo}
+}
+
+// Quote checks that code can discount a payment of amount Rials for planID,
+// which is empty for payments not made for a plan, and returns the coupon
+// and its discount. Redemption limits are enforced again by Redeem.
+func (s *CouponService) Quote(ctx context.Context, code, planID string, amount int64) (*domain.Coupon, int64, error) {
+	coupon, err := s.repo.GetByCode(ctx, domain.NormalizeCouponCode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := coupon.CheckApplicable(planID, time.Now()); err != nil {
+		return nil, 0, err
+	}
+
+	discount := coupon.Discount(amount)
+	if discount <= 0 {
+		return nil, 0, fmt.Errorf("%w: amount is too small to discount", domain.ErrCouponNotApplicable)
+	}
+	return coupon, discount, nil
+}
+
+// Redeem reserves a use of coupon for a stored payment discounted with it
+func (s *CouponService) Redeem(ctx context.Context, coupon *domain.Coupon, payment *domain.Payment) error {
+	now := time.Now()
+	return s.repo.Redeem(ctx, &domain.CouponRedemption{
+		CouponID:  coupon.ID,
+		UserID:    payment.UserID,
+		PaymentID: payment.ID,
+		Discount:  payment.Discount,
+		Status:    domain.RedemptionReserved,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// HandlePayment confirms the coupon use of a verified payment and releases
+// that of a payment that failed, was cancelled or expired, or was refunded
+// while held for review. It is registered as a payment listener.
+func (s *CouponService) HandlePayment(ctx context.Context, payment *domain.Payment) {
+	if payment.CouponCode == "" {
+		return
+	}
+
+	var status domain.RedemptionStatus
+	switch payment.Status {
+	case domain.PaymentVerified:
+		status = domain.RedemptionRedeemed
+	case domain.PaymentFailed, domain.PaymentCancelled, domain.PaymentExpired, domain.PaymentRefunded:
+		// Refunds of verified payments find the use already confirmed
+		status = domain.RedemptionReleased
+	default:
+		return
+	}
+
+	if err := s.repo.SettleRedemption(ctx, payment.ID, status); err != nil {
+		logger.Log.Error("Failed to settle coupon redemption",
+			zap.String("payment_id", payment.ID), zap.String("coupon", payment.CouponCode), zap.Error(err))
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// Services log failures they recover from
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// memoryPaymentRepo serves payments from memory; the embedded interface
+// panics on methods the tests do not use
+type memoryPaymentRepo struct {
+	ports.PaymentRepository
+	payments map[string]*domain.Payment
+}
+
+func newMemoryPaymentRepo(payments ...*domain.Payment) *memoryPaymentRepo {
+	r := &memoryPaymentRepo{payments: make(map[string]*domain.Payment)}
+	for _, p := range payments {
+		r.payments[p.ID] = p
+	}
+	return r
+}
+
+func (r *memoryPaymentRepo) GetByID(_ context.Context, id string) (*domain.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+// memoryLedgerRepo is an in-memory ledger
+type memoryLedgerRepo struct {
+	ports.LedgerRepository
+	accounts map[string]*domain.LedgerAccount
+	entries  []*domain.JournalEntry
+}
+
+func newMemoryLedgerRepo() *memoryLedgerRepo {
+	return &memoryLedgerRepo{accounts: make(map[string]*domain.LedgerAccount)}
+}
+
+func (r *memoryLedgerRepo) GetOrCreateAccount(_ context.Context, accountType domain.AccountType, ownerID string) (*domain.LedgerAccount, error) {
+	id := string(accountType) + ":" + ownerID
+	account, ok := r.accounts[id]
+	if !ok {
+		account = &domain.LedgerAccount{ID: id, Type: accountType, OwnerID: ownerID}
+		r.accounts[id] = account
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *memoryLedgerRepo) Post(_ context.Context, entry *domain.JournalEntry, accounts []*domain.LedgerAccount) error {
+	if _, err := r.GetEntry(context.Background(), entry.Kind, entry.Reference); err == nil {
+		return domain.ErrDuplicateEntry
+	}
+	for _, a := range accounts {
+		copied := *a
+		r.accounts[a.ID] = &copied
+	}
+	entry.ID = fmt.Sprintf("e%d", len(r.entries)+1)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryLedgerRepo) GetEntry(_ context.Context, kind domain.JournalKind, reference string) (*domain.JournalEntry, error) {
+	for _, e := range r.entries {
+		if e.Kind == kind && e.Reference == reference {
+			return e, nil
+		}
+	}
+	return nil, domain.ErrEntryNotFound
+}
+
+// balance returns the cached balance of an account, zero if it was never used
+func (r *memoryLedgerRepo) balance(accountType domain.AccountType, ownerID string) int64 {
+	if account, ok := r.accounts[string(accountType)+":"+ownerID]; ok {
+		return account.Balance
+	}
+	return 0
+}
+
+// memoryRefundRepo is an in-memory refund store
+type memoryRefundRepo struct {
+	ports.RefundRepository
+	refunds map[string]*domain.Refund
+}
+
+func newMemoryRefundRepo() *memoryRefundRepo {
+	return &memoryRefundRepo{refunds: make(map[string]*domain.Refund)}
+}
+
+func (r *memoryRefundRepo) Create(_ context.Context, refund *domain.Refund) error {
+	refund.ID = fmt.Sprintf("r%d", len(r.refunds)+1)
+	copied := *refund
+	r.refunds[refund.ID] = &copied
+	return nil
+}
+
+func (r *memoryRefundRepo) GetByID(_ context.Context, id string) (*domain.Refund, error) {
+	refund, ok := r.refunds[id]
+	if !ok {
+		return nil, domain.ErrRefundNotFound
+	}
+	copied := *refund
+	return &copied, nil
+}
+
+func (r *memoryRefundRepo) Update(_ context.Context, refund *domain.Refund, from domain.RefundStatus) error {
+	if r.refunds[refund.ID].Status != from {
+		return domain.ErrRefundConflict
+	}
+	copied := *refund
+	r.refunds[refund.ID] = &copied
+	return nil
+}
+
+func (r *memoryRefundRepo) OpenAmount(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+// memoryPayoutRepo accepts payouts without keeping them
+type memoryPayoutRepo struct {
+	ports.PayoutRepository
+}
+
+func (memoryPayoutRepo) Create(context.Context, *domain.Payout) error {
+	return nil
+}
+
+// freeLock is a ports.DistributedLock that is always acquired
+type freeLock struct{}
+
+func (freeLock) TryLock(context.Context, string, time.Duration) (func(context.Context) error, bool, error) {
+	return func(context.Context) error { return nil }, true, nil
+}
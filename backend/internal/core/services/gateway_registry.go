@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// GatewayInfo describes a registered payment gateway to clients
+type GatewayInfo struct {
+	Name        string
+	DisplayName string
+}
+
+type registeredGateway struct {
+	info    GatewayInfo
+	gateway ports.PaymentGateway
+}
+
+// GatewayRegistry holds the payment gateways that can accept payments,
+// keyed by the gateway name stored on payments
+type GatewayRegistry struct {
+	enabled  map[string]bool
+	gateways map[string]registeredGateway
+}
+
+// NewGatewayRegistry creates a registry that accepts only the enabled
+// gateway names, or every gateway when enabled is empty
+func NewGatewayRegistry(enabled []string) *GatewayRegistry {
+	r := &GatewayRegistry{gateways: make(map[string]registeredGateway)}
+	for _, name := range enabled {
+		if name = strings.TrimSpace(name); name != "" {
+			if r.enabled == nil {
+				r.enabled = make(map[string]bool)
+			}
+			r.enabled[name] = true
+		}
+	}
+	return r
+}
+
+// Register adds a gateway. Disabled gateways and gateways that report a
+// configuration problem are logged and left out, so clients never see them.
+func (r *GatewayRegistry) Register(info GatewayInfo, gateway ports.PaymentGateway) {
+	if r.enabled != nil && !r.enabled[info.Name] {
+		logger.Log.Info("Payment gateway disabled", zap.String("gateway", info.Name))
+		return
+	}
+	if checker, ok := gateway.(ports.GatewayConfigChecker); ok {
+		if err := checker.CheckConfig(); err != nil {
+			logger.Log.Warn("Payment gateway misconfigured, skipping", zap.String("gateway", info.Name), zap.Error(err))
+			return
+		}
+	}
+	r.gateways[info.Name] = registeredGateway{info: info, gateway: gateway}
+}
+
+// Get returns the gateway registered under name
+func (r *GatewayRegistry) Get(name string) (ports.PaymentGateway, error) {
+	registered, ok := r.gateways[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrGatewayUnavailable, name)
+	}
+	return registered.gateway, nil
+}
+
+// List returns the available gateways sorted by name
+func (r *GatewayRegistry) List() []GatewayInfo {
+	infos := make([]GatewayInfo, 0, len(r.gateways))
+	for _, registered := range r.gateways {
+		infos = append(infos, registered.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ledgerRetries bounds retries after optimistic locking conflicts
+const ledgerRetries = 3
+
+// LedgerService keeps user wallets in a double-entry ledger. Every change
+// is a balanced journal entry; balances are never updated on their own.
+type LedgerService struct {
+	ledgerRepo  ports.LedgerRepository
+	paymentRepo ports.PaymentRepository
+	payments    *PaymentService
+}
+
+// NewLedgerService creates a new ledger service. Wallets are topped up with
+// payments started through payments.
+func NewLedgerService(ledgerRepo ports.LedgerRepository, paymentRepo ports.PaymentRepository, payments *PaymentService) *LedgerService {
+	return &LedgerService{ledgerRepo: ledgerRepo, paymentRepo: paymentRepo, payments: payments}
+}
+
+// Balance returns a user's wallet account
+func (s *LedgerService) Balance(ctx context.Context, userID string) (*domain.LedgerAccount, error) {
+	return s.ledgerRepo.GetOrCreateAccount(ctx, domain.AccountUserWallet, userID)
+}
+
+// History returns a user's wallet statements, newest first
+func (s *LedgerService) History(ctx context.Context, userID string, limit, offset int) ([]domain.LedgerStatement, error) {
+	wallet, err := s.Balance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledgerRepo.ListStatements(ctx, wallet.ID, limit, offset)
+}
+
+// StartTopUp starts a payment that credits the user's wallet once it is
+// verified. Top-ups pay for nothing else, so they carry no order, coupon or
+// metadata of the caller.
+func (s *LedgerService) StartTopUp(ctx context.Context, userID, gatewayName string, intent ports.PaymentIntent) (*domain.Payment, *ports.PaymentRequestResult, error) {
+	intent.OrderID = ""
+	intent.CouponCode = ""
+	intent.PlanID = ""
+	intent.Metadata = map[string]string{domain.PaymentPurposeKey: domain.PurposeWalletTopUp}
+	return s.payments.Start(ctx, userID, gatewayName, intent)
+}
+
+// HandlePayment credits the wallet with a verified top-up payment. It is
+// registered as a payment listener.
+func (s *LedgerService) HandlePayment(ctx context.Context, payment *domain.Payment) {
+	if payment.Status != domain.PaymentVerified || !payment.IsWalletTopUp() {
+		return
+	}
+	if _, err := s.topUp(ctx, payment); ignoreDuplicate(err) != nil {
+		// The user can claim the top-up again from their wallet
+		logger.Log.Error("Failed to credit wallet top-up", zap.String("payment_id", payment.ID), zap.Error(err))
+	}
+}
+
+// TopUpFromPayment credits a user's wallet with one of their verified
+// top-up payments, for when crediting it on verification failed. A payment
+// can top up the wallet only once.
+func (s *LedgerService) TopUpFromPayment(ctx context.Context, userID, paymentID string) (*domain.JournalEntry, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, domain.ErrPaymentNotFound
+	}
+	if payment.Status != domain.PaymentVerified {
+		return nil, domain.ErrPaymentNotToppable
+	}
+	return s.topUp(ctx, payment)
+}
+
+// topUp credits the wallet of a top-up payment's user with what was paid.
+// Payments made for anything else already bought something and are refused.
+func (s *LedgerService) topUp(ctx context.Context, payment *domain.Payment) (*domain.JournalEntry, error) {
+	switch {
+	case !payment.IsWalletTopUp():
+		return nil, fmt.Errorf("%w: payment was not started as a top-up", domain.ErrPaymentNotToppable)
+	case payment.OrderID != "":
+		return nil, fmt.Errorf("%w: payment is for order %s", domain.ErrPaymentNotToppable, payment.OrderID)
+	case payment.Metadata[InvoiceMetadataKey] != "":
+		return nil, fmt.Errorf("%w: payment settles invoice %s", domain.ErrPaymentNotToppable, payment.Metadata[InvoiceMetadataKey])
+	}
+
+	return s.transfer(ctx, domain.JournalTopUp, payment.ID, "wallet top-up via "+payment.Gateway,
+		domain.AccountGatewayClearing, payment.Gateway,
+		domain.AccountUserWallet, payment.UserID,
+		payment.Amount)
+}
+
+// HoldTopUpRefund takes a refund of a top-up payment back out of the wallet
+// the payment credited, so the credit cannot be both refunded and spent or
+// paid out. It fails with domain.ErrInsufficientFunds if the credit is gone.
+// Refunds of other payments are left alone.
+func (s *LedgerService) HoldTopUpRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	// Held payments are only credited once a reviewer releases them
+	if !payment.IsWalletTopUp() || payment.Status == domain.PaymentOnHold {
+		return nil
+	}
+	// Credit the top-up first in case that failed on verification, so it
+	// cannot be claimed after the refund
+	if _, err := s.topUp(ctx, payment); ignoreDuplicate(err) != nil {
+		return err
+	}
+	_, err := s.transfer(ctx, domain.JournalTopUpRefund, refund.ID, "refund of top-up "+payment.ID,
+		domain.AccountUserWallet, payment.UserID,
+		domain.AccountGatewayClearing, payment.Gateway,
+		refund.Amount)
+	return ignoreDuplicate(err)
+}
+
+// ReleaseTopUpRefund returns the amount held for a refund that failed or
+// was rejected to the wallet
+func (s *LedgerService) ReleaseTopUpRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	if _, err := s.ledgerRepo.GetEntry(ctx, domain.JournalTopUpRefund, refund.ID); err != nil {
+		if errors.Is(err, domain.ErrEntryNotFound) {
+			return nil
+		}
+		return err
+	}
+	_, err := s.transfer(ctx, domain.JournalTopUpRefundRelease, refund.ID, "refund "+string(refund.Status),
+		domain.AccountGatewayClearing, payment.Gateway,
+		domain.AccountUserWallet, payment.UserID,
+		refund.Amount)
+	return ignoreDuplicate(err)
+}
+
+// Spend moves credit from a user's wallet to revenue. reference identifies
+// the purchase so it is never charged twice.
+func (s *LedgerService) Spend(ctx context.Context, userID string, amount int64, reference, description string) (*domain.JournalEntry, error) {
+	return s.transfer(ctx, domain.JournalSpend, reference, description,
+		domain.AccountUserWallet, userID,
+		domain.AccountRevenue, "",
+		amount)
+}
+
+// Refund gives credit the user spent on the purchase identified by reference
+// back to their wallet. It fails with domain.ErrEntryNotFound unless the
+// user spent on that purchase and with domain.ErrRefundExceedsSpend if
+// amount is more than they spent, so a wallet never holds credit that was
+// not paid in.
+func (s *LedgerService) Refund(ctx context.Context, userID string, amount int64, reference, description string) (*domain.JournalEntry, error) {
+	spend, err := s.ledgerRepo.GetEntry(ctx, domain.JournalSpend, reference)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.ledgerRepo.GetOrCreateAccount(ctx, domain.AccountUserWallet, userID)
+	if err != nil {
+		return nil, err
+	}
+	var spent int64
+	for _, p := range spend.Postings {
+		if p.AccountID == wallet.ID {
+			spent += p.Debit
+		}
+	}
+	if spent == 0 {
+		return nil, domain.ErrEntryNotFound
+	}
+	if amount > spent {
+		return nil, domain.ErrRefundExceedsSpend
+	}
+	return s.transfer(ctx, domain.JournalRefund, reference, description,
+		domain.AccountRefunds, "",
+		domain.AccountUserWallet, userID,
+		amount)
+}
+
+// HoldPayout moves a requested payout's amount out of the user's wallet so
+// it cannot be spent while the payout is reviewed and sent
+func (s *LedgerService) HoldPayout(ctx context.Context, payout *domain.Payout) error {
+	_, err := s.transfer(ctx, domain.JournalPayout, payout.ID, "payout to "+payout.Sheba,
+		domain.AccountUserWallet, payout.UserID,
+		domain.AccountPayouts, "",
+		payout.Amount)
+	return ignoreDuplicate(err)
+}
+
+// ReleasePayout returns the held amount of a payout that was rejected,
+// cancelled or failed to the user's wallet
+func (s *LedgerService) ReleasePayout(ctx context.Context, payout *domain.Payout) error {
+	_, err := s.transfer(ctx, domain.JournalPayoutReversal, payout.ID, "payout "+string(payout.Status),
+		domain.AccountPayouts, "",
+		domain.AccountUserWallet, payout.UserID,
+		payout.Amount)
+	return ignoreDuplicate(err)
+}
+
+// SettlePayout records that a payout left the funds collected by its gateway
+func (s *LedgerService) SettlePayout(ctx context.Context, payout *domain.Payout) error {
+	_, err := s.transfer(ctx, domain.JournalPayoutSettlement, payout.ID, "payout paid via "+payout.Gateway,
+		domain.AccountPayouts, "",
+		domain.AccountGatewayClearing, payout.Gateway,
+		payout.Amount)
+	return ignoreDuplicate(err)
+}
+
+// ignoreDuplicate treats an entry recorded before as recorded now, so
+// entries keyed by their subject's ID can be retried
+func ignoreDuplicate(err error) error {
+	if errors.Is(err, domain.ErrDuplicateEntry) {
+		return nil
+	}
+	return err
+}
+
+// CheckConsistency re-sums the journal
+func (s *LedgerService) CheckConsistency(ctx context.Context) ([]domain.LedgerMismatch, error) {
+	return s.ledgerRepo.CheckConsistency(ctx)
+}
+
+// transfer posts a two-account entry, retrying when a concurrent entry
+// changed one of the accounts after they were read
+func (s *LedgerService) transfer(ctx context.Context, kind domain.JournalKind, reference, description string,
+	debitType domain.AccountType, debitOwner string, creditType domain.AccountType, creditOwner string, amount int64) (*domain.JournalEntry, error) {
+	if err := domain.Rials(amount).Validate(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	for attempt := 0; attempt < ledgerRetries; attempt++ {
+		var debit, credit *domain.LedgerAccount
+		if debit, err = s.ledgerRepo.GetOrCreateAccount(ctx, debitType, debitOwner); err != nil {
+			return nil, err
+		}
+		if credit, err = s.ledgerRepo.GetOrCreateAccount(ctx, creditType, creditOwner); err != nil {
+			return nil, err
+		}
+
+		entry, err := domain.NewTransfer(kind, reference, description, debit.ID, credit.ID, amount)
+		if err != nil {
+			return nil, err
+		}
+		if err := debit.Apply(entry.Postings[0]); err != nil {
+			return nil, err
+		}
+		if err := credit.Apply(entry.Postings[1]); err != nil {
+			return nil, err
+		}
+
+		// Accounts are updated in ID order so entries moving money in
+		// opposite directions cannot deadlock
+		accounts := []*domain.LedgerAccount{debit, credit}
+		sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+		err = s.ledgerRepo.Post(ctx, entry, accounts)
+		if errors.Is(err, domain.ErrLedgerConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+	return nil, domain.ErrLedgerConflict
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+// verifiedPayment returns a verified payment of u1 through zarinpal
+func verifiedPayment(id string, amount int64, metadata map[string]string) *domain.Payment {
+	return &domain.Payment{
+		ID:       id,
+		UserID:   "u1",
+		Gateway:  GatewayZarinpal,
+		Amount:   amount,
+		Metadata: metadata,
+		Status:   domain.PaymentVerified,
+	}
+}
+
+func topUpMetadata() map[string]string {
+	return map[string]string{domain.PaymentPurposeKey: domain.PurposeWalletTopUp}
+}
+
+func newTestLedger(payments ...*domain.Payment) (*LedgerService, *memoryLedgerRepo, *memoryPaymentRepo) {
+	ledgerRepo := newMemoryLedgerRepo()
+	paymentRepo := newMemoryPaymentRepo(payments...)
+	return NewLedgerService(ledgerRepo, paymentRepo, nil), ledgerRepo, paymentRepo
+}
+
+func newTestRefunds(ledger *LedgerService, paymentRepo *memoryPaymentRepo) *RefundService {
+	payments := NewPaymentService(paymentRepo, NewGatewayRegistry(nil), nil)
+	refunds := NewRefundService(payments, newMemoryRefundRepo(), freeLock{})
+	refunds.SetLedgerService(ledger)
+	return refunds
+}
+
+func TestWalletTopUp(t *testing.T) {
+	invoice := topUpMetadata()
+	invoice[InvoiceMetadataKey] = "inv-1"
+
+	claim := func(ctx context.Context, ledger *LedgerService, _ *RefundService) error {
+		_, err := ledger.TopUpFromPayment(ctx, "u1", "p1")
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		// then runs after the payment's verification was handled
+		then        func(ctx context.Context, ledger *LedgerService, refunds *RefundService) error
+		wantErr     error
+		wantBalance int64
+	}{
+		{name: "Credited On Verification", metadata: topUpMetadata(), wantBalance: 10000},
+		{name: "Claimed Again", metadata: topUpMetadata(), then: claim, wantErr: domain.ErrDuplicateEntry, wantBalance: 10000},
+		{name: "Plain Payment", then: claim, wantErr: domain.ErrPaymentNotToppable},
+		{name: "Invoice Payment", metadata: invoice, then: claim, wantErr: domain.ErrPaymentNotToppable},
+		{
+			name:     "Refund Requested",
+			metadata: topUpMetadata(),
+			then: func(ctx context.Context, _ *LedgerService, refunds *RefundService) error {
+				_, err := refunds.Request(ctx, "p1", 4000, "changed mind", "admin")
+				return err
+			},
+			wantBalance: 6000,
+		},
+		{
+			name:     "Refund Rejected",
+			metadata: topUpMetadata(),
+			then: func(ctx context.Context, _ *LedgerService, refunds *RefundService) error {
+				refund, err := refunds.Request(ctx, "p1", 4000, "changed mind", "admin")
+				if err != nil {
+					return err
+				}
+				_, err = refunds.RejectManual(ctx, refund.ID, "not eligible", "finance")
+				return err
+			},
+			wantBalance: 10000,
+		},
+		{
+			name:     "Refund Of Spent Credit",
+			metadata: topUpMetadata(),
+			then: func(ctx context.Context, ledger *LedgerService, refunds *RefundService) error {
+				if _, err := ledger.Spend(ctx, "u1", 8000, "order-1", "purchase"); err != nil {
+					return err
+				}
+				_, err := refunds.Request(ctx, "p1", 5000, "changed mind", "admin")
+				return err
+			},
+			wantErr:     domain.ErrInsufficientFunds,
+			wantBalance: 2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			payment := verifiedPayment("p1", 10000, tt.metadata)
+			ledger, ledgerRepo, paymentRepo := newTestLedger(payment)
+
+			ledger.HandlePayment(ctx, payment)
+			var err error
+			if tt.then != nil {
+				err = tt.then(ctx, ledger, newTestRefunds(ledger, paymentRepo))
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := ledgerRepo.balance(domain.AccountUserWallet, "u1"); got != tt.wantBalance {
+				t.Errorf("wallet balance = %d, want %d", got, tt.wantBalance)
+			}
+		})
+	}
+}
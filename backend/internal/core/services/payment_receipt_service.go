@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"github.com/youruser/yourproject/pkg/persian"
+	"go.uber.org/zap"
+)
+
+// PaymentReceiptService issues numbered receipts for verified payments and
+// stores them as PDFs
+type PaymentReceiptService struct {
+	receiptRepo ports.PaymentReceiptRepository
+	paymentRepo ports.PaymentRepository
+	userRepo    ports.UserRepository
+	renderer    ports.ReceiptRenderer
+	storage     ports.FileStorage
+	// linkTTL is how long download links stay valid
+	linkTTL time.Duration
+}
+
+func NewPaymentReceiptService(receiptRepo ports.PaymentReceiptRepository, paymentRepo ports.PaymentRepository, userRepo ports.UserRepository, renderer ports.ReceiptRenderer, storage ports.FileStorage, linkTTL time.Duration) *PaymentReceiptService {
+	return &PaymentReceiptService{
+		receiptRepo: receiptRepo,
+		paymentRepo: paymentRepo,
+		userRepo:    userRepo,
+		renderer:    renderer,
+		storage:     storage,
+		linkTTL:     linkTTL,
+	}
+}
+
+// HandlePayment issues the receipt of a verified payment and stores its
+// PDF. It is registered as a payment listener; failures are logged and
+// retried when the receipt is downloaded.
+func (s *PaymentReceiptService) HandlePayment(ctx context.Context, payment *domain.Payment) {
+	if payment.Status != domain.PaymentVerified {
+		return
+	}
+
+	receipt, err := s.Issue(ctx, payment)
+	if err == nil && receipt.FileKey == "" {
+		err = s.store(ctx, receipt)
+	}
+	if err != nil {
+		logger.Log.Error("Failed to issue payment receipt", zap.String("payment_id", payment.ID), zap.Error(err))
+	}
+}
+
+// Issue returns the payment's receipt, numbering a new one in the series of
+// the Jalali year it was paid in if it has none yet
+func (s *PaymentReceiptService) Issue(ctx context.Context, payment *domain.Payment) (*domain.PaymentReceipt, error) {
+	receipt, err := s.receiptRepo.GetByPaymentID(ctx, payment.ID)
+	if !errors.Is(err, domain.ErrPaymentReceiptNotFound) {
+		return receipt, err
+	}
+
+	paidAt, err := s.paidAt(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	series := strconv.Itoa(persian.JalaliDate(paidAt).Year)
+	receipt, err = domain.NewPaymentReceipt(payment, series, paidAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.receiptRepo.Create(ctx, receipt)
+	if errors.Is(err, domain.ErrPaymentReceiptExists) {
+		// Issued concurrently, e.g. by a download racing the listener
+		return s.receiptRepo.GetByPaymentID(ctx, payment.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// paidAt is when the payment was first verified, falling back to its last
+// update for payments without that event
+func (s *PaymentReceiptService) paidAt(ctx context.Context, payment *domain.Payment) (time.Time, error) {
+	events, err := s.paymentRepo.ListEvents(ctx, payment.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, e := range events {
+		if e.ToStatus == domain.PaymentVerified {
+			return e.CreatedAt, nil
+		}
+	}
+	return payment.UpdatedAt, nil
+}
+
+// store renders the receipt and uploads the PDF
+func (s *PaymentReceiptService) store(ctx context.Context, receipt *domain.PaymentReceipt) error {
+	payer, err := s.userRepo.GetByID(ctx, receipt.UserID)
+	if err != nil {
+		return err
+	}
+	pdf, err := s.renderer.RenderReceipt(receipt, payer)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.storage.UploadFile(ctx, "receipts/"+receipt.Series+"/"+receipt.FileName(), bytes.NewReader(pdf))
+	if err != nil {
+		return err
+	}
+	if err := s.receiptRepo.SetFile(ctx, receipt.ID, key); err != nil {
+		return err
+	}
+	receipt.FileKey = key
+	return nil
+}
+
+// DownloadURL returns a short-lived link to the PDF receipt of one of the
+// user's payments, issuing and storing it first if needed
+func (s *PaymentReceiptService) DownloadURL(ctx context.Context, userID, paymentID string) (string, *domain.PaymentReceipt, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return "", nil, err
+	}
+	if payment.UserID != userID {
+		return "", nil, domain.ErrPaymentNotFound
+	}
+
+	receipt, err := s.Issue(ctx, payment)
+	if err != nil {
+		return "", nil, err
+	}
+	if receipt.FileKey == "" {
+		if err := s.store(ctx, receipt); err != nil {
+			return "", nil, err
+		}
+	}
+
+	url, err := s.storage.GenerateDownloadURL(ctx, receipt.FileKey, receipt.FileName(), int64(s.linkTTL.Seconds()))
+	if err != nil {
+		return "", nil, err
+	}
+	return url, receipt, nil
+}
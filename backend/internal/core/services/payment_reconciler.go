@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	reconcileLockName = "payments:reconcile"
+	reconcileNote     = "reconciliation"
+	// reconcileLockTTL bounds a pass; it is cut short rather than outlive
+	// the lock and let another replica in
+	reconcileLockTTL = 10 * time.Minute
+)
+
+// ReconcilerConfig controls the payment reconciler
+type ReconcilerConfig struct {
+	Interval time.Duration
+	// StaleAfter is how long a payment may wait for its callback before the
+	// reconciler verifies it
+	StaleAfter time.Duration
+	// ExpireAfter is how long an unpaid payment is retried before it expires
+	ExpireAfter time.Duration
+	BatchSize   int
+}
+
+// PaymentReconciler settles payments whose callback never arrived, e.g.
+// because the user closed the tab on the gateway's page. Every change is
+// recorded as a payment event noted "reconciliation".
+type PaymentReconciler struct {
+	payments *PaymentService
+	lock     ports.DistributedLock
+	cfg      ReconcilerConfig
+}
+
+// NewPaymentReconciler creates a reconciler; only one replica reconciles at a
+// time through lock
+func NewPaymentReconciler(payments *PaymentService, lock ports.DistributedLock, cfg ReconcilerConfig) *PaymentReconciler {
+	return &PaymentReconciler{payments: payments, lock: lock, cfg: cfg}
+}
+
+// Run reconciles payments until the context is cancelled
+func (r *PaymentReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reconcile(ctx)
+		}
+	}
+}
+
+// Reconcile runs one pass unless another replica holds the lock
+func (r *PaymentReconciler) Reconcile(ctx context.Context) {
+	unlock, acquired, err := r.lock.TryLock(ctx, reconcileLockName, reconcileLockTTL)
+	if err != nil {
+		logger.Log.Error("Failed to acquire payment reconciliation lock", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if err := unlock(ctx); err != nil {
+			logger.Log.Warn("Failed to release payment reconciliation lock", zap.Error(err))
+		}
+	}()
+
+	passCtx, cancel := context.WithTimeout(ctx, reconcileLockTTL)
+	defer cancel()
+	r.verifyUnverified(passCtx)
+	r.reconcileStale(passCtx)
+}
+
+// verifyUnverified verifies payments gateways report as paid but unverified
+func (r *PaymentReconciler) verifyUnverified(ctx context.Context) {
+	for _, info := range r.payments.gateways.List() {
+		gateway, err := r.payments.gateways.Get(info.Name)
+		if err != nil {
+			continue
+		}
+		lister, ok := gateway.(ports.UnverifiedLister)
+		if !ok {
+			continue
+		}
+
+		authorities, err := lister.ListUnverified(ctx)
+		if err != nil {
+			logger.Log.Error("Failed to list unverified payments", zap.String("gateway", info.Name), zap.Error(err))
+			continue
+		}
+
+		for _, authority := range authorities {
+			payment, err := r.payments.paymentRepo.GetByAuthority(ctx, info.Name, authority)
+			if err != nil {
+				if !errors.Is(err, domain.ErrPaymentNotFound) {
+					logger.Log.Error("Failed to load unverified payment", zap.String("authority", authority), zap.Error(err))
+				}
+				continue
+			}
+			r.settle(ctx, gateway, payment, false)
+		}
+	}
+}
+
+// reconcileStale verifies payments that waited too long for their callback
+// and expires those that stay unpaid
+func (r *PaymentReconciler) reconcileStale(ctx context.Context) {
+	now := time.Now()
+	statuses := []domain.PaymentStatus{domain.PaymentRedirected, domain.PaymentPending}
+
+	payments, err := r.payments.paymentRepo.ListStale(ctx, statuses, now.Add(-r.cfg.StaleAfter), r.cfg.BatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to list stale payments", zap.Error(err))
+		return
+	}
+
+	for _, payment := range payments {
+		// Payments left as they are go to the back of the queue
+		if err := r.payments.paymentRepo.MarkReconciled(ctx, payment.ID, now); err != nil {
+			logger.Log.Error("Failed to mark payment as reconciled", zap.String("payment_id", payment.ID), zap.Error(err))
+		}
+
+		// Gateways outside the registry, e.g. card-to-card awaiting review,
+		// are settled elsewhere
+		gateway, err := r.payments.gateways.Get(payment.Gateway)
+		if err != nil {
+			continue
+		}
+		r.settle(ctx, gateway, payment, now.Sub(payment.CreatedAt) > r.cfg.ExpireAfter)
+	}
+}
+
+// settle verifies payment and, when the gateway rejects it and expire is
+// set, moves it to expired
+func (r *PaymentReconciler) settle(ctx context.Context, gateway ports.PaymentGateway, payment *domain.Payment, expire bool) {
+	if !payment.Status.CanTransitionTo(domain.PaymentVerified) {
+		return
+	}
+	fields := []zap.Field{zap.String("payment_id", payment.ID), zap.String("gateway", payment.Gateway)}
+
+	err := r.payments.verifyAtGateway(ctx, gateway, payment, "", reconcileNote)
+	var gwErr *ports.GatewayError
+	switch {
+	case err == nil:
+		logger.Log.Info("Reconciled payment", append(fields, zap.String("status", string(payment.Status)))...)
+	case errors.As(err, &gwErr) && expire:
+		if err := r.payments.Transition(ctx, payment, domain.PaymentExpired, gwErr.Raw, joinNotes(reconcileNote, gwErr.Error())); err != nil {
+			logger.Log.Error("Failed to expire payment", append(fields, zap.Error(err))...)
+			return
+		}
+		r.payments.notify(ctx, payment)
+		logger.Log.Info("Expired unpaid payment", fields...)
+	case errors.As(err, &gwErr):
+		// Not paid yet; retried on the next pass until it expires
+	default:
+		logger.Log.Error("Failed to reconcile payment", append(fields, zap.Error(err))...)
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+)
+
+// Gateway names as stored on payments
+const (
+	GatewayZarinpal   = "zarinpal"
+	GatewayVandar     = "vandar"
+	GatewayIDPay      = "idpay"
+	GatewayPayIR      = "payir"
+	GatewayNextPay    = "nextpay"
+	GatewayMellat     = "mellat"
+	GatewaySaman      = "saman"
+	GatewaySandbox    = "sandbox"
+	GatewayCardToCard = "cardtocard"
+)
+
+// PaymentService drives payments through the domain.Payment state machine
+// and records every transition with the gateway's raw response
+type PaymentService struct {
+	paymentRepo ports.PaymentRepository
+	gateways    *GatewayRegistry
+	notifier    ports.UserNotifier
+	risk        *RiskService
+	coupons     *CouponService
+	listeners   []PaymentListener
+}
+
+// PaymentListener reacts to a payment reaching a status users are notified
+// of, e.g. to fulfil what was paid for
+type PaymentListener func(ctx context.Context, payment *domain.Payment)
+
+// NewPaymentService creates a new payment service
+func NewPaymentService(paymentRepo ports.PaymentRepository, gateways *GatewayRegistry, notifier ports.UserNotifier) *PaymentService {
+	return &PaymentService{paymentRepo: paymentRepo, gateways: gateways, notifier: notifier}
+}
+
+// SetRiskService screens new payments and the cards they were paid with
+// through risk; without one payments are not screened
+func (s *PaymentService) SetRiskService(risk *RiskService) {
+	s.risk = risk
+}
+
+// SetCouponService lets payments be discounted with coupons; without one
+// payments with a coupon code are refused
+func (s *PaymentService) SetCouponService(coupons *CouponService) {
+	s.coupons = coupons
+}
+
+// OnStatusChange registers listener to run after every status change the
+// user is notified of. Listeners must tolerate being called more than once
+// for the same payment.
+func (s *PaymentService) OnStatusChange(listener PaymentListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Payment events pushed to users and sent to webhook endpoints
+const (
+	EventPaymentVerified = domain.WebhookPaymentVerified
+	EventPaymentFailed   = domain.WebhookPaymentFailed
+	EventPaymentRefunded = domain.WebhookPaymentRefunded
+	EventPaymentHeld     = domain.WebhookPaymentHeld
+)
+
+// paymentEvent returns the event a payment's status is announced as
+func paymentEvent(payment *domain.Payment) string {
+	switch payment.Status {
+	case domain.PaymentVerified:
+		return EventPaymentVerified
+	case domain.PaymentRefunded, domain.PaymentPartiallyRefunded:
+		return EventPaymentRefunded
+	case domain.PaymentOnHold:
+		return EventPaymentHeld
+	}
+	return EventPaymentFailed
+}
+
+// Start stores a payment, requests it from the named gateway and moves it to
+// redirected, or to failed if the gateway rejects it. The amount is stored
+// in Rials and converted to the gateway's unit by its adapter. A coupon in
+// the intent is taken off the amount before the payment is stored and
+// requested. Payments started in a tenant belong to its organization.
+// Payments the risk checks block fail with domain.ErrPaymentBlocked and are
+// not stored.
+func (s *PaymentService) Start(ctx context.Context, userID, gatewayName string, intent ports.PaymentIntent) (*domain.Payment, *ports.PaymentRequestResult, error) {
+	gateway, err := s.gateways.Get(gatewayName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := intent.Amount.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	payment, err := domain.NewPayment(userID, gatewayName, intent.Amount.ToRials(), intent.Description)
+	if err != nil {
+		return nil, nil, err
+	}
+	payment.OrderID = intent.OrderID
+	payment.Metadata = intent.Metadata
+	payment.ClientIP = intent.ClientIP
+	payment.OrganizationID, _ = domain.TenantFromContext(ctx)
+
+	var coupon *domain.Coupon
+	if intent.CouponCode != "" {
+		if s.coupons == nil {
+			return nil, nil, fmt.Errorf("%w: coupons are not accepted", domain.ErrCouponNotApplicable)
+		}
+		var discount int64
+		coupon, discount, err = s.coupons.Quote(ctx, intent.CouponCode, intent.PlanID, payment.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		payment.CouponCode = coupon.Code
+		payment.Discount = discount
+		payment.Amount -= discount
+		intent.Amount = domain.Rials(payment.Amount)
+	}
+
+	if s.risk != nil {
+		decision, err := s.risk.Assess(ctx, payment, intent.PayerMobile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if decision.Action == domain.RiskBlock {
+			return nil, nil, domain.ErrPaymentBlocked
+		}
+		payment.Risk = decision
+	}
+	if err := s.create(ctx, payment); err != nil {
+		return nil, nil, err
+	}
+
+	if coupon != nil {
+		// Concurrent checkouts may have used up the coupon since the quote
+		if err := s.coupons.Redeem(ctx, coupon, payment); err != nil {
+			if tErr := s.Transition(ctx, payment, domain.PaymentCancelled, nil, "coupon: "+err.Error()); tErr != nil {
+				return payment, nil, errors.Join(err, tErr)
+			}
+			return payment, nil, err
+		}
+	}
+
+	result, err := gateway.RequestPayment(ctx, intent)
+	if err != nil {
+		if tErr := s.Transition(ctx, payment, domain.PaymentFailed, rawFromError(err), err.Error()); tErr != nil {
+			return payment, nil, errors.Join(err, tErr)
+		}
+		if coupon != nil {
+			s.coupons.HandlePayment(ctx, payment)
+		}
+		return payment, nil, err
+	}
+
+	payment.Authority = result.Authority
+	if err := s.Transition(ctx, payment, domain.PaymentRedirected, result.Raw, ""); err != nil {
+		return payment, nil, err
+	}
+	return payment, result, nil
+}
+
+// Verify settles a payment the gateway redirected back for. The payment is
+// looked up by the callback's authority and verified with the stored amount.
+// When the gateway reports the user cancelled it is cancelled without
+// calling the gateway. Transport errors leave the payment untouched so it
+// can be verified again later.
+func (s *PaymentService) Verify(ctx context.Context, gatewayName string, callback ports.PaymentCallback) (*domain.Payment, error) {
+	gateway, err := s.gateways.Get(gatewayName)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.GetByAuthority(ctx, gatewayName, callback.Authority)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == domain.PaymentVerified || payment.Status == domain.PaymentOnHold {
+		return payment, nil
+	}
+
+	if !callback.Paid {
+		if err := s.Transition(ctx, payment, domain.PaymentCancelled, nil, "cancelled at gateway"); err != nil {
+			return payment, err
+		}
+		s.notify(ctx, payment)
+		return payment, nil
+	}
+
+	err = s.verifyAtGateway(ctx, gateway, payment, callback.Reference, "")
+	var gwErr *ports.GatewayError
+	if errors.As(err, &gwErr) {
+		if tErr := s.Transition(ctx, payment, domain.PaymentFailed, gwErr.Raw, gwErr.Error()); tErr != nil {
+			return payment, s.reloadIfConcurrent(ctx, payment, tErr)
+		}
+		s.notify(ctx, payment)
+		return payment, nil
+	}
+	return payment, err
+}
+
+// verifyAtGateway verifies payment with its stored amount and moves it to
+// verified, or to on hold when the risk checks want it reviewed. The
+// callback's reference, when there is one, is passed to gateways that
+// verify with it. Gateway rejections are returned as *ports.GatewayError
+// without changing the payment, so callers decide whether they are final.
+func (s *PaymentService) verifyAtGateway(ctx context.Context, gateway ports.PaymentGateway, payment *domain.Payment, reference, note string) error {
+	// Reject payments that can no longer be verified before asking the
+	// gateway; held payments are released by a reviewer instead
+	if !payment.Status.CanTransitionTo(domain.PaymentVerified) || payment.Status == domain.PaymentOnHold {
+		return fmt.Errorf("%w: %s -> %s", domain.ErrInvalidPaymentState, payment.Status, domain.PaymentVerified)
+	}
+
+	var result *ports.PaymentVerifyResult
+	var err error
+	if verifier, ok := gateway.(ports.ReferenceVerifier); ok && reference != "" {
+		result, err = verifier.VerifyReference(ctx, payment.Authority, reference, domain.Rials(payment.Amount))
+	} else {
+		result, err = gateway.VerifyPayment(ctx, payment.Authority, domain.Rials(payment.Amount))
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.AlreadyVerified {
+		note = joinNotes(note, "already verified at gateway")
+	}
+	payment.RefID = result.RefID
+	payment.CardPan = result.CardPan
+	payment.CardHash = result.CardHash
+
+	next := domain.PaymentVerified
+	if s.risk != nil {
+		decision, err := s.risk.AssessCard(ctx, payment)
+		if err != nil {
+			// The money is taken either way; hold the payment rather than
+			// skip the check
+			decision.Flag(domain.RiskReview, "card check failed: "+err.Error())
+		}
+		payment.Risk.Merge(decision)
+	}
+	if payment.Risk.NeedsReview() {
+		next = domain.PaymentOnHold
+		note = joinNotes(note, "held for review: "+strings.Join(payment.Risk.Reasons, "; "))
+	}
+	if err := s.Transition(ctx, payment, next, result.Raw, note); err != nil {
+		return s.reloadIfConcurrent(ctx, payment, err)
+	}
+	s.notify(ctx, payment)
+	return nil
+}
+
+// ReleaseHeld verifies a payment that was held for risk review
+func (s *PaymentService) ReleaseHeld(ctx context.Context, paymentID, reviewerID, reason string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.PaymentOnHold {
+		return payment, fmt.Errorf("%w: %s payment is not held", domain.ErrInvalidPaymentState, payment.Status)
+	}
+
+	if err := s.Transition(ctx, payment, domain.PaymentVerified, nil, joinNotes("released by "+reviewerID, reason)); err != nil {
+		return payment, err
+	}
+	s.notify(ctx, payment)
+	return payment, nil
+}
+
+func joinNotes(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "; " + b
+}
+
+// reloadIfConcurrent swallows a lost race against another verification of
+// the same payment, refreshing payment with the winner's result
+func (s *PaymentService) reloadIfConcurrent(ctx context.Context, payment *domain.Payment, err error) error {
+	if !errors.Is(err, domain.ErrPaymentConcurrentUpdate) {
+		return err
+	}
+	current, gErr := s.paymentRepo.GetByID(ctx, payment.ID)
+	if gErr != nil {
+		return errors.Join(err, gErr)
+	}
+	*payment = *current
+	return nil
+}
+
+// notify pushes the payment's status to the user and runs the listeners;
+// delivery is best effort
+func (s *PaymentService) notify(ctx context.Context, payment *domain.Payment) {
+	_ = s.notifier.NotifyUser(ctx, payment.UserID, paymentEvent(payment), map[string]interface{}{
+		"payment_id": payment.ID,
+		"status":     string(payment.Status),
+		"amount":     payment.Amount,
+		"ref_id":     payment.RefID,
+		"refunded":   payment.RefundedAmount,
+	})
+	for _, listener := range s.listeners {
+		listener(ctx, payment)
+	}
+}
+
+// Transition moves a payment to next and persists it with an event. Invalid
+// transitions are rejected before anything is written.
+func (s *PaymentService) Transition(ctx context.Context, payment *domain.Payment, next domain.PaymentStatus, raw []byte, note string) error {
+	from := payment.Status
+	if err := payment.TransitionTo(next); err != nil {
+		return err
+	}
+
+	event := &domain.PaymentEvent{
+		FromStatus:  from,
+		ToStatus:    next,
+		RawResponse: raw,
+		Note:        note,
+		CreatedAt:   payment.UpdatedAt,
+	}
+	if err := s.paymentRepo.Save(ctx, payment, from, event); err != nil {
+		payment.Status = from
+		return err
+	}
+	return nil
+}
+
+func (s *PaymentService) create(ctx context.Context, payment *domain.Payment) error {
+	event := &domain.PaymentEvent{ToStatus: domain.PaymentCreated, CreatedAt: time.Now()}
+	return s.paymentRepo.Create(ctx, payment, event)
+}
+
+// rawFromError extracts the gateway response from a gateway rejection
+func rawFromError(err error) []byte {
+	var gwErr *ports.GatewayError
+	if errors.As(err, &gwErr) {
+		return gwErr.Raw
+	}
+	return nil
+}
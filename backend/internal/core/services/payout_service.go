@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	payoutDispatchLockName = "payouts:dispatch"
+	payoutSyncLockName     = "payouts:sync"
+	payoutDispatchLockTTL  = 5 * time.Minute
+)
+
+// Payout gateway names; a paid payout leaves the funds collected under the
+// same name in the ledger
+const (
+	PayoutGatewayVandar = GatewayVandar
+	PayoutGatewayManual = "manual"
+)
+
+// PayoutConfig controls payout dispatch and status tracking
+type PayoutConfig struct {
+	// SyncInterval is how often processing payouts are checked with their
+	// gateway
+	SyncInterval time.Duration
+	// BatchSize bounds the payouts sent in one dispatch and checked in one
+	// sync
+	BatchSize int
+}
+
+// PayoutBatchResult is the outcome of a dispatch
+type PayoutBatchResult struct {
+	BatchID string
+	Payouts []domain.Payout
+	// File is the bank batch file of gateways that export one
+	File     []byte
+	FileName string
+}
+
+// PayoutService pays wallet credit out to users' bank accounts. A payout
+// holds its amount in the ledger when requested, waits for a reviewer's
+// approval and is then sent through a payout gateway in a batch. Rejected,
+// cancelled and failed payouts give the amount back to the wallet.
+type PayoutService struct {
+	payoutRepo ports.PayoutRepository
+	ledger     *LedgerService
+	lock       ports.DistributedLock
+	gateways   map[string]ports.PayoutGateway
+	cfg        PayoutConfig
+}
+
+func NewPayoutService(payoutRepo ports.PayoutRepository, ledger *LedgerService, lock ports.DistributedLock, cfg PayoutConfig) *PayoutService {
+	return &PayoutService{
+		payoutRepo: payoutRepo,
+		ledger:     ledger,
+		lock:       lock,
+		gateways:   make(map[string]ports.PayoutGateway),
+		cfg:        cfg,
+	}
+}
+
+// RegisterGateway makes a payout gateway available for dispatches.
+// Gateways that report a configuration problem are logged and left out.
+func (s *PayoutService) RegisterGateway(name string, gateway ports.PayoutGateway) {
+	if checker, ok := gateway.(ports.GatewayConfigChecker); ok {
+		if err := checker.CheckConfig(); err != nil {
+			logger.Log.Warn("Payout gateway misconfigured, skipping", zap.String("gateway", name), zap.Error(err))
+			return
+		}
+	}
+	s.gateways[name] = gateway
+}
+
+// Gateways returns the names of the registered payout gateways
+func (s *PayoutService) Gateways() []string {
+	names := make([]string, 0, len(s.gateways))
+	for name := range s.gateways {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Request asks for amount Rials of a user's wallet to be paid to sheba. The
+// amount is held right away and fails with domain.ErrInsufficientFunds when
+// the wallet cannot cover it.
+func (s *PayoutService) Request(ctx context.Context, userID string, amount int64, sheba, ownerName, description string) (*domain.Payout, error) {
+	payout, err := domain.NewPayout(userID, amount, sheba, ownerName, description)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is chosen up front so the hold can reference it before the
+	// payout is stored; a payout is never stored without its hold
+	payout.ID = uuid.NewString()
+	if err := s.ledger.HoldPayout(ctx, payout); err != nil {
+		return nil, err
+	}
+	if err := s.payoutRepo.Create(ctx, payout); err != nil {
+		if rErr := s.ledger.ReleasePayout(ctx, payout); rErr != nil {
+			logger.Log.Error("Failed to return the hold of an unsaved payout",
+				zap.String("payout_id", payout.ID), zap.String("user_id", userID), zap.Error(rErr))
+		}
+		return nil, err
+	}
+	return payout, nil
+}
+
+// Cancel withdraws one of a user's payouts that was not approved yet
+func (s *PayoutService) Cancel(ctx context.Context, userID, payoutID string) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if payout.UserID != userID {
+		return nil, domain.ErrPayoutNotFound
+	}
+
+	from := payout.Status
+	if err := payout.Cancel(); err != nil {
+		return payout, err
+	}
+	return payout, s.close(ctx, payout, from)
+}
+
+// Approve lets a requested payout be dispatched
+func (s *PayoutService) Approve(ctx context.Context, payoutID, reviewerID string) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := payout.Status
+	if err := payout.Approve(reviewerID); err != nil {
+		return payout, err
+	}
+	return payout, s.payoutRepo.Update(ctx, payout, from)
+}
+
+// Reject declines a payout that was not dispatched yet
+func (s *PayoutService) Reject(ctx context.Context, payoutID, reviewerID, note string) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := payout.Status
+	if err := payout.Reject(reviewerID, note); err != nil {
+		return payout, err
+	}
+	return payout, s.close(ctx, payout, from)
+}
+
+// Dispatch sends the oldest approved payouts through the named gateway as
+// one batch. Only one dispatch runs at a time.
+func (s *PayoutService) Dispatch(ctx context.Context, gatewayName string) (*PayoutBatchResult, error) {
+	gateway, ok := s.gateways[gatewayName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrGatewayUnavailable, gatewayName)
+	}
+
+	unlock, acquired, err := s.lock.TryLock(ctx, payoutDispatchLockName, payoutDispatchLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, domain.ErrPayoutConflict
+	}
+	defer func() { _ = unlock(context.Background()) }()
+
+	approved, err := s.payoutRepo.ListByStatus(ctx, domain.PayoutApproved, s.cfg.BatchSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payouts are marked as processing before they are sent so no later
+	// dispatch can send them again
+	result := &PayoutBatchResult{BatchID: uuid.NewString()}
+	for i := range approved {
+		payout := &approved[i]
+		if err := payout.StartProcessing(gatewayName, result.BatchID); err != nil {
+			continue
+		}
+		if err := s.payoutRepo.Update(ctx, payout, domain.PayoutApproved); err != nil {
+			// Rejected meanwhile
+			if errors.Is(err, domain.ErrPayoutConflict) {
+				continue
+			}
+			return nil, err
+		}
+		result.Payouts = append(result.Payouts, *payout)
+	}
+	if len(result.Payouts) == 0 {
+		return result, nil
+	}
+
+	batch, err := gateway.SendPayouts(ctx, result.BatchID, result.Payouts)
+	if err != nil {
+		// The payouts stay processing; Sync or finance settles them
+		logger.Log.Error("Failed to send payout batch",
+			zap.String("batch_id", result.BatchID), zap.String("gateway", gatewayName), zap.Error(err))
+		return result, err
+	}
+	result.File, result.FileName = batch.File, batch.FileName
+
+	for i := range result.Payouts {
+		if i >= len(batch.Results) {
+			break
+		}
+		if err := s.apply(ctx, &result.Payouts[i], &batch.Results[i]); err != nil {
+			logger.Log.Error("Failed to record payout result",
+				zap.String("payout_id", result.Payouts[i].ID), zap.Error(err))
+		}
+	}
+	return result, nil
+}
+
+// BatchFile returns the bank batch file of a dispatch again
+func (s *PayoutService) BatchFile(ctx context.Context, batchID string) ([]byte, string, error) {
+	payouts, err := s.payoutRepo.ListByBatch(ctx, batchID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(payouts) == 0 {
+		return nil, "", domain.ErrPayoutNotFound
+	}
+
+	exporter, ok := s.gateways[payouts[0].Gateway].(ports.PayoutExporter)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s does not export batch files", domain.ErrGatewayUnavailable, payouts[0].Gateway)
+	}
+	return exporter.ExportPayouts(batchID, payouts)
+}
+
+// Complete records that finance confirmed a processing payout's transfer
+func (s *PayoutService) Complete(ctx context.Context, payoutID, reference, processedBy string) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := payout.Status
+	if err := payout.MarkPaid(reference, processedBy); err != nil {
+		return payout, err
+	}
+	return payout, s.close(ctx, payout, from)
+}
+
+// Fail records that the bank returned a processing payout
+func (s *PayoutService) Fail(ctx context.Context, payoutID, note, processedBy string) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.GetByID(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := payout.Status
+	if err := payout.MarkFailed(note, processedBy); err != nil {
+		return payout, err
+	}
+	return payout, s.close(ctx, payout, from)
+}
+
+// Run tracks processing payouts until the context is cancelled
+func (s *PayoutService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sync(ctx)
+		}
+	}
+}
+
+// Sync asks gateways how processing payouts went and records ledger entries
+// that failed to post when their payouts closed. Only one replica syncs at
+// a time.
+func (s *PayoutService) Sync(ctx context.Context) {
+	unlock, acquired, err := s.lock.TryLock(ctx, payoutSyncLockName, s.cfg.SyncInterval)
+	if err != nil {
+		logger.Log.Error("Failed to acquire payout sync lock", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if err := unlock(ctx); err != nil {
+			logger.Log.Warn("Failed to release payout sync lock", zap.Error(err))
+		}
+	}()
+
+	processing, err := s.payoutRepo.ListByStatus(ctx, domain.PayoutProcessing, s.cfg.BatchSize, 0)
+	if err != nil {
+		logger.Log.Error("Failed to list processing payouts", zap.Error(err))
+		return
+	}
+	for i := range processing {
+		payout := &processing[i]
+		checker, ok := s.gateways[payout.Gateway].(ports.PayoutStatusChecker)
+		if !ok {
+			continue
+		}
+		result, err := checker.PayoutStatus(ctx, payout)
+		if err != nil {
+			logger.Log.Warn("Failed to check payout status", zap.String("payout_id", payout.ID), zap.Error(err))
+			continue
+		}
+		if err := s.apply(ctx, payout, result); err != nil {
+			logger.Log.Error("Failed to record payout status", zap.String("payout_id", payout.ID), zap.Error(err))
+		}
+	}
+
+	unsettled, err := s.payoutRepo.ListUnsettled(ctx, s.cfg.BatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to list unsettled payouts", zap.Error(err))
+		return
+	}
+	for i := range unsettled {
+		if err := s.settle(ctx, &unsettled[i]); err != nil {
+			logger.Log.Error("Failed to settle payout in the ledger", zap.String("payout_id", unsettled[i].ID), zap.Error(err))
+		}
+	}
+}
+
+// apply records what a gateway reported for a processing payout
+func (s *PayoutService) apply(ctx context.Context, payout *domain.Payout, result *ports.PayoutResult) error {
+	var gwErr *ports.GatewayError
+	switch {
+	case errors.As(result.Err, &gwErr):
+		_ = payout.MarkFailed(gwErr.Error(), "")
+		return s.close(ctx, payout, domain.PayoutProcessing)
+	case result.Err != nil:
+		// The gateway may or may not have accepted it; finance must check
+		// before completing or failing it
+		payout.Note = "gateway outcome unknown, check before settling: " + result.Err.Error()
+	case result.Status == domain.PayoutPaid:
+		_ = payout.MarkPaid(result.Reference, "")
+		return s.close(ctx, payout, domain.PayoutProcessing)
+	case result.Status == domain.PayoutFailed:
+		_ = payout.MarkFailed(result.Note, "")
+		return s.close(ctx, payout, domain.PayoutProcessing)
+	case result.Reference == "" || result.Reference == payout.Reference:
+		return nil
+	default:
+		payout.Reference = result.Reference
+	}
+	payout.UpdatedAt = time.Now()
+	return s.payoutRepo.Update(ctx, payout, domain.PayoutProcessing)
+}
+
+// close persists a payout that reached a final status and posts its ledger
+// entry. Entries that fail to post are retried by Sync.
+func (s *PayoutService) close(ctx context.Context, payout *domain.Payout, from domain.PayoutStatus) error {
+	if err := s.payoutRepo.Update(ctx, payout, from); err != nil {
+		return err
+	}
+	return s.settle(ctx, payout)
+}
+
+// settle posts the ledger entry of a final payout: paid payouts leave the
+// gateway's funds, all others go back to the wallet
+func (s *PayoutService) settle(ctx context.Context, payout *domain.Payout) error {
+	if payout.Status == domain.PayoutPaid {
+		return s.ledger.SettlePayout(ctx, payout)
+	}
+	return s.ledger.ReleasePayout(ctx, payout)
+}
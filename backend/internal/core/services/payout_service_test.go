@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+)
+
+func TestPayoutOnlyPaysOutWalletFunds(t *testing.T) {
+	amount := domain.MinPayoutAmount
+
+	spendAndRefund := func(refund int64) func(context.Context, *LedgerService, *RefundService) error {
+		return func(ctx context.Context, ledger *LedgerService, _ *RefundService) error {
+			if _, err := ledger.Spend(ctx, "u1", amount, "order-1", "purchase"); err != nil {
+				return err
+			}
+			_, err := ledger.Refund(ctx, "u1", refund, "order-1", "returned")
+			return err
+		}
+	}
+
+	tests := []struct {
+		name         string
+		metadata     map[string]string
+		setup        func(ctx context.Context, ledger *LedgerService, refunds *RefundService) error
+		wantSetupErr error
+		wantErr      error
+	}{
+		{name: "Top-Up", metadata: topUpMetadata()},
+		{name: "Invoice Payment", metadata: map[string]string{InvoiceMetadataKey: "inv-1"}, wantErr: domain.ErrInsufficientFunds},
+		{name: "Plain Payment", wantErr: domain.ErrInsufficientFunds},
+		{
+			name:     "Refunded Top-Up",
+			metadata: topUpMetadata(),
+			setup: func(ctx context.Context, _ *LedgerService, refunds *RefundService) error {
+				_, err := refunds.Request(ctx, "p1", amount, "changed mind", "admin")
+				return err
+			},
+			wantErr: domain.ErrInsufficientFunds,
+		},
+		{
+			name: "Refund Of Nothing Spent",
+			setup: func(ctx context.Context, ledger *LedgerService, _ *RefundService) error {
+				_, err := ledger.Refund(ctx, "u1", amount, "order-1", "goodwill")
+				return err
+			},
+			wantSetupErr: domain.ErrEntryNotFound,
+			wantErr:      domain.ErrInsufficientFunds,
+		},
+		{name: "Refunded Purchase", metadata: topUpMetadata(), setup: spendAndRefund(amount)},
+		{
+			name:         "Refund Above Purchase",
+			metadata:     topUpMetadata(),
+			setup:        spendAndRefund(amount + 1),
+			wantSetupErr: domain.ErrRefundExceedsSpend,
+			wantErr:      domain.ErrInsufficientFunds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			payment := verifiedPayment("p1", amount, tt.metadata)
+			ledger, ledgerRepo, paymentRepo := newTestLedger(payment)
+			payouts := NewPayoutService(memoryPayoutRepo{}, ledger, freeLock{}, PayoutConfig{})
+
+			ledger.HandlePayment(ctx, payment)
+			if tt.setup != nil {
+				if err := tt.setup(ctx, ledger, newTestRefunds(ledger, paymentRepo)); !errors.Is(err, tt.wantSetupErr) {
+					t.Fatalf("setup error = %v, want %v", err, tt.wantSetupErr)
+				}
+			}
+
+			_, err := payouts.Request(ctx, "u1", amount, "IR820540102680020817909002", "Ali Rezaei", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Request() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := ledgerRepo.balance(domain.AccountUserWallet, "u1"); got != 0 {
+				t.Errorf("wallet balance = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrRBACSyncBlocked    = errors.New("rbac sync skipped changes that are still referenced")
+	ErrRBACSyncInProgress = errors.New("rbac sync is already running elsewhere")
+)
+
+const (
+	rbacSyncLockName = "rbac:sync"
+	// rbacSyncLockTTL outlasts a sync, so a replica that dies mid-sync only
+	// holds up the others for this long
+	rbacSyncLockTTL = time.Minute
+)
+
+// RBACSyncOptions controls how a policy is reconciled
+type RBACSyncOptions struct {
+	// DryRun computes the diff without touching the database
+	DryRun bool
+	// Force applies deletions of roles and permissions that are still in use
+	Force bool
+}
+
+// RBACSyncer reconciles the database with a declarative RBAC policy
+type RBACSyncer struct {
+	rbacRepo ports.RBACRepository
+	versions ports.PermissionVersionStore
+	lock     ports.DistributedLock
+}
+
+// NewRBACSyncer creates a new RBAC policy syncer. Applied changes invalidate
+// permissions embedded in issued tokens through versions; lock keeps
+// replicas deploying at the same time from applying the policy together.
+func NewRBACSyncer(rbacRepo ports.RBACRepository, versions ports.PermissionVersionStore, lock ports.DistributedLock) *RBACSyncer {
+	return &RBACSyncer{rbacRepo: rbacRepo, versions: versions, lock: lock}
+}
+
+// Plan computes the changes needed to match the policy
+func (s *RBACSyncer) Plan(ctx context.Context, policy *domain.RBACPolicy) (*domain.RBACPolicyDiff, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.rbacRepo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	roleUsers := make(map[string]int, len(roles))
+	for i := range roles {
+		perms, err := s.rbacRepo.GetRolePermissions(ctx, roles[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load permissions of role %s: %w", roles[i].Name, err)
+		}
+		roles[i].Permissions = perms
+
+		grants, err := s.rbacRepo.ListRoleGrants(ctx, domain.RoleGrantFilter{RoleID: roles[i].ID, IncludeExpired: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load grants of role %s: %w", roles[i].Name, err)
+		}
+		roleUsers[roles[i].Name] = len(grants)
+	}
+
+	permissions, err := s.rbacRepo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	return domain.DiffRBACPolicy(policy, roles, permissions, roleUsers), nil
+}
+
+// Sync plans and, unless DryRun is set, applies the policy. Blocked changes
+// are skipped without Force and reported through ErrRBACSyncBlocked. A sync
+// that would apply changes returns ErrRBACSyncInProgress while another one
+// holds the lock.
+func (s *RBACSyncer) Sync(ctx context.Context, policy *domain.RBACPolicy, opts RBACSyncOptions) (*domain.RBACPolicyDiff, error) {
+	if !opts.DryRun {
+		unlock, acquired, err := s.lock.TryLock(ctx, rbacSyncLockName, rbacSyncLockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire rbac sync lock: %w", err)
+		}
+		if !acquired {
+			return nil, ErrRBACSyncInProgress
+		}
+		defer func() {
+			if err := unlock(ctx); err != nil {
+				logger.Log.Warn("Failed to release rbac sync lock", zap.Error(err))
+			}
+		}()
+	}
+
+	diff, err := s.Plan(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return diff, nil
+	}
+
+	skipped := 0
+	for _, change := range diff.Changes {
+		if change.Blocked() && !opts.Force {
+			skipped++
+			continue
+		}
+		if err := s.apply(ctx, change); err != nil {
+			return diff, fmt.Errorf("failed to apply %q: %w", change.String(), err)
+		}
+	}
+
+	if skipped < len(diff.Changes) {
+		if err := s.versions.BumpAll(ctx); err != nil {
+			return diff, fmt.Errorf("failed to bump permission version: %w", err)
+		}
+	}
+
+	if skipped > 0 {
+		return diff, fmt.Errorf("%w: %d change(s)", ErrRBACSyncBlocked, skipped)
+	}
+	return diff, nil
+}
+
+func (s *RBACSyncer) apply(ctx context.Context, change domain.RBACChange) error {
+	now := time.Now()
+
+	switch change.Kind {
+	case domain.ChangeCreatePermission:
+		return s.rbacRepo.CreatePermission(ctx, &domain.Permission{
+			Name:        change.Permission,
+			Description: change.Description,
+			Resource:    change.Resource,
+			Action:      change.Action,
+			CreatedAt:   now,
+		})
+
+	case domain.ChangeUpdatePermission:
+		perm, err := s.rbacRepo.GetPermissionByName(ctx, change.Permission)
+		if err != nil {
+			return err
+		}
+		perm.Description = change.Description
+		perm.Resource = change.Resource
+		perm.Action = change.Action
+		return s.rbacRepo.UpdatePermission(ctx, perm)
+
+	case domain.ChangeDeletePermission:
+		perm, err := s.rbacRepo.GetPermissionByName(ctx, change.Permission)
+		if err != nil {
+			return err
+		}
+		return s.rbacRepo.DeletePermission(ctx, perm.ID)
+
+	case domain.ChangeCreateRole:
+		return s.rbacRepo.CreateRole(ctx, &domain.Role{
+			Name:        change.Role,
+			Description: change.Description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+
+	case domain.ChangeUpdateRole:
+		role, err := s.rbacRepo.GetRoleByName(ctx, change.Role)
+		if err != nil {
+			return err
+		}
+		role.Description = change.Description
+		role.UpdatedAt = now
+		return s.rbacRepo.UpdateRole(ctx, role)
+
+	case domain.ChangeDeleteRole:
+		role, err := s.rbacRepo.GetRoleByName(ctx, change.Role)
+		if err != nil {
+			return err
+		}
+		return s.rbacRepo.DeleteRole(ctx, role.ID)
+
+	case domain.ChangeGrantPermission, domain.ChangeRevokePermission:
+		role, err := s.rbacRepo.GetRoleByName(ctx, change.Role)
+		if err != nil {
+			return err
+		}
+		perm, err := s.rbacRepo.GetPermissionByName(ctx, change.Permission)
+		if err != nil {
+			return err
+		}
+		if change.Kind == domain.ChangeGrantPermission {
+			return s.rbacRepo.AssignPermissionToRole(ctx, role.ID, perm.ID)
+		}
+		return s.rbacRepo.RemovePermissionFromRole(ctx, role.ID, perm.ID)
+	}
+
+	return fmt.Errorf("unknown change kind %q", change.Kind)
+}
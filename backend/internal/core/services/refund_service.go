@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const refundLockTTL = time.Minute
+
+// RefundService returns money for verified payments, through the gateway
+// when it supports refunds and through a manual finance workflow otherwise
+type RefundService struct {
+	payments   *PaymentService
+	refundRepo ports.RefundRepository
+	lock       ports.DistributedLock
+	ledger     *LedgerService
+	listeners  []RefundListener
+}
+
+// RefundListener reacts to a refund being created, completed, failed or
+// rejected
+type RefundListener func(ctx context.Context, refund *domain.Refund)
+
+// NewRefundService creates a refund service; refunds of the same payment are
+// serialized through lock
+func NewRefundService(payments *PaymentService, refundRepo ports.RefundRepository, lock ports.DistributedLock) *RefundService {
+	return &RefundService{payments: payments, refundRepo: refundRepo, lock: lock}
+}
+
+// SetLedgerService makes refunds of wallet top-ups take the refunded
+// amount back out of the wallet
+func (s *RefundService) SetLedgerService(ledger *LedgerService) {
+	s.ledger = ledger
+}
+
+// OnStatusChange registers listener to run after a refund is stored in a
+// new status. Listeners must tolerate being called more than once for the
+// same status.
+func (s *RefundService) OnStatusChange(listener RefundListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// notify runs the listeners on a refund that was just stored
+func (s *RefundService) notify(ctx context.Context, refund *domain.Refund) {
+	for _, listener := range s.listeners {
+		listener(ctx, refund)
+	}
+}
+
+// lockPayment serializes refunds of a payment so their sum never exceeds it
+func (s *RefundService) lockPayment(ctx context.Context, paymentID string) (func(), error) {
+	unlock, acquired, err := s.lock.TryLock(ctx, "payments:refund:"+paymentID, refundLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, domain.ErrPaymentConcurrentUpdate
+	}
+	return func() { _ = unlock(context.Background()) }, nil
+}
+
+// Request refunds amount of a payment. Gateway refunds complete immediately;
+// refunds the gateway cannot do, or whose outcome is unknown, are left
+// pending for finance to complete manually.
+func (s *RefundService) Request(ctx context.Context, paymentID string, amount int64, reason, requestedBy string) (*domain.Refund, error) {
+	unlock, err := s.lockPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	payment, err := s.payments.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.refundRepo.OpenAmount(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > payment.Refundable()-open {
+		return nil, domain.ErrInvalidRefundAmount
+	}
+
+	var refunder ports.RefundGateway
+	if gateway, err := s.payments.gateways.Get(payment.Gateway); err == nil {
+		refunder, _ = gateway.(ports.RefundGateway)
+	}
+
+	method := domain.RefundMethodManual
+	if refunder != nil {
+		method = domain.RefundMethodGateway
+	}
+	refund, err := domain.NewRefund(paymentID, amount, reason, method, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refundRepo.Create(ctx, refund); err != nil {
+		return nil, err
+	}
+	if s.ledger != nil {
+		if err := s.ledger.HoldTopUpRefund(ctx, payment, refund); err != nil {
+			_ = refund.Fail("wallet top-up could not be taken back: " + err.Error())
+			if uErr := s.refundRepo.Update(ctx, refund, domain.RefundRequested); uErr != nil {
+				return refund, errors.Join(err, uErr)
+			}
+			s.notify(ctx, refund)
+			return refund, err
+		}
+	}
+	s.notify(ctx, refund)
+	if refunder == nil {
+		return refund, nil
+	}
+
+	result, err := refunder.Refund(ctx, payment, domain.Rials(amount), reason)
+	var gwErr *ports.GatewayError
+	switch {
+	case errors.Is(err, domain.ErrRefundUnsupported):
+		_ = refund.FallBackToManual(err.Error())
+		return refund, s.toManual(ctx, refund)
+	case errors.As(err, &gwErr):
+		_ = refund.Fail(gwErr.Error())
+		if uErr := s.refundRepo.Update(ctx, refund, domain.RefundRequested); uErr != nil {
+			return refund, errors.Join(err, uErr)
+		}
+		if rErr := s.releaseTopUp(ctx, payment, refund); rErr != nil {
+			return refund, errors.Join(err, rErr)
+		}
+		s.notify(ctx, refund)
+		return refund, err
+	case err != nil:
+		// The gateway may or may not have refunded; finance must check
+		_ = refund.FallBackToManual("gateway outcome unknown, check before completing: " + err.Error())
+		return refund, s.toManual(ctx, refund)
+	}
+
+	completed := *refund
+	_ = completed.Complete(result.Reference, requestedBy)
+	refunded := payment.RefundedAmount
+	err = s.apply(ctx, payment, &completed, domain.RefundRequested, result.Raw)
+	switch {
+	case err == nil:
+		return &completed, nil
+	case payment.RefundedAmount != refunded:
+		// The payment records the refund; only the refund itself is behind
+		logger.Log.Error("Failed to store a completed gateway refund",
+			zap.String("refund_id", refund.ID), zap.String("reference", result.Reference), zap.Error(err))
+		return &completed, err
+	}
+	// The gateway returned the money but the payment does not record it;
+	// finance completes it with the gateway's reference
+	_ = refund.FallBackToManual(fmt.Sprintf("gateway refunded it with reference %s but recording failed, complete with that reference: %v",
+		result.Reference, err))
+	if mErr := s.toManual(ctx, refund); mErr != nil {
+		return refund, errors.Join(err, mErr)
+	}
+	return refund, err
+}
+
+// toManual persists a gateway refund handed over to finance
+func (s *RefundService) toManual(ctx context.Context, refund *domain.Refund) error {
+	if err := s.refundRepo.Update(ctx, refund, domain.RefundRequested); err != nil {
+		return err
+	}
+	s.notify(ctx, refund)
+	return nil
+}
+
+// RejectHeld refunds the whole of a payment held for risk review, through
+// the gateway or the manual refund queue
+func (s *RefundService) RejectHeld(ctx context.Context, paymentID, reason, reviewerID string) (*domain.Refund, error) {
+	payment, err := s.payments.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.PaymentOnHold {
+		return nil, fmt.Errorf("%w: %s payment is not held", domain.ErrInvalidPaymentState, payment.Status)
+	}
+	return s.Request(ctx, paymentID, payment.Refundable(), reason, reviewerID)
+}
+
+// CompleteManual records that finance returned a pending manual refund
+func (s *RefundService) CompleteManual(ctx context.Context, refundID, reference, processedBy string) (*domain.Refund, error) {
+	refund, err := s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockPayment(ctx, refund.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Reload under the lock in case it was processed meanwhile
+	refund, err = s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != domain.RefundPendingManual {
+		return refund, fmt.Errorf("%w: %s -> %s", domain.ErrInvalidRefundState, refund.Status, domain.RefundCompleted)
+	}
+
+	payment, err := s.payments.paymentRepo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = refund.Complete(reference, processedBy)
+	return refund, s.apply(ctx, payment, refund, domain.RefundPendingManual, nil)
+}
+
+// RejectManual declines a pending manual refund
+func (s *RefundService) RejectManual(ctx context.Context, refundID, note, processedBy string) (*domain.Refund, error) {
+	refund, err := s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockPayment(ctx, refund.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Reload under the lock in case it was processed meanwhile
+	refund, err = s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != domain.RefundPendingManual {
+		return refund, fmt.Errorf("%w: %s -> %s", domain.ErrInvalidRefundState, refund.Status, domain.RefundRejected)
+	}
+
+	_ = refund.Reject(processedBy, note)
+	if err := s.refundRepo.Update(ctx, refund, domain.RefundPendingManual); err != nil {
+		return refund, err
+	}
+
+	payment, err := s.payments.paymentRepo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		return refund, err
+	}
+	if err := s.releaseTopUp(ctx, payment, refund); err != nil {
+		return refund, err
+	}
+	s.notify(ctx, refund)
+	return refund, nil
+}
+
+// releaseTopUp returns what a refund that did not happen took out of the
+// wallet
+func (s *RefundService) releaseTopUp(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	if s.ledger == nil {
+		return nil
+	}
+	return s.ledger.ReleaseTopUpRefund(ctx, payment, refund)
+}
+
+// apply records a completed refund, which was in status refundFrom, on its
+// payment and persists both
+func (s *RefundService) apply(ctx context.Context, payment *domain.Payment, refund *domain.Refund, refundFrom domain.RefundStatus, raw []byte) error {
+	from, refunded := payment.Status, payment.RefundedAmount
+	if err := payment.ApplyRefund(refund.Amount); err != nil {
+		return err
+	}
+
+	event := &domain.PaymentEvent{
+		FromStatus:  from,
+		ToStatus:    payment.Status,
+		RawResponse: raw,
+		Note:        fmt.Sprintf("refund %s of %d: %s", refund.ID, refund.Amount, refund.Reason),
+		CreatedAt:   payment.UpdatedAt,
+	}
+	if err := s.payments.paymentRepo.Save(ctx, payment, from, event); err != nil {
+		payment.Status, payment.RefundedAmount = from, refunded
+		return err
+	}
+	if err := s.refundRepo.Update(ctx, refund, refundFrom); err != nil {
+		return err
+	}
+
+	s.payments.notify(ctx, payment)
+	s.notify(ctx, refund)
+	return nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RiskService screens payments for fraud such as card testing: velocity
+// and amount limits, young accounts and blocklisted mobiles and cards
+type RiskService struct {
+	rules    domain.RiskRules
+	riskRepo ports.RiskRepository
+	userRepo ports.UserRepository
+}
+
+func NewRiskService(rules domain.RiskRules, riskRepo ports.RiskRepository, userRepo ports.UserRepository) *RiskService {
+	return &RiskService{rules: rules, riskRepo: riskRepo, userRepo: userRepo}
+}
+
+// Assess evaluates a payment before it is stored and sent to a gateway.
+// The payer's mobile and the account's phone are both checked against the
+// blocklist.
+func (s *RiskService) Assess(ctx context.Context, payment *domain.Payment, mobile string) (domain.RiskDecision, error) {
+	user, err := s.userRepo.GetByID(ctx, payment.UserID)
+	if err != nil {
+		return domain.RiskDecision{}, err
+	}
+
+	now := time.Now()
+	activity, err := s.riskRepo.Activity(ctx, payment.UserID, payment.ClientIP, now.Add(-s.rules.VelocityWindow), now.Add(-24*time.Hour))
+	if err != nil {
+		return domain.RiskDecision{}, err
+	}
+
+	mobileBlocked := false
+	for _, m := range []string{mobile, user.Phone} {
+		if m == "" || mobileBlocked {
+			continue
+		}
+		if mobileBlocked, err = s.riskRepo.IsBlocked(ctx, domain.BlockMobile, m); err != nil {
+			return domain.RiskDecision{}, err
+		}
+	}
+
+	decision := s.rules.Evaluate(domain.RiskSignals{
+		Amount:        payment.Amount,
+		AccountAge:    now.Sub(user.CreatedAt),
+		UserPayments:  activity.UserPayments,
+		IPPayments:    activity.IPPayments,
+		ClientIP:      payment.ClientIP,
+		DailyAmount:   activity.DailyAmount,
+		MobileBlocked: mobileBlocked,
+	})
+	s.log("Payment risk assessed", payment, decision)
+	return decision, nil
+}
+
+// AssessCard checks the card a payment was paid with against the
+// blocklist. The money is already taken, so a blocked card holds the
+// payment for review rather than declining it.
+func (s *RiskService) AssessCard(ctx context.Context, payment *domain.Payment) (domain.RiskDecision, error) {
+	decision := domain.RiskDecision{Action: domain.RiskAllow}
+	if payment.CardHash == "" {
+		return decision, nil
+	}
+
+	blocked, err := s.riskRepo.IsBlocked(ctx, domain.BlockCardHash, payment.CardHash)
+	if err != nil {
+		return decision, err
+	}
+	if blocked {
+		decision.Flag(domain.RiskReview, "card is blocklisted")
+		s.log("Payment card is blocklisted", payment, decision)
+	}
+	return decision, nil
+}
+
+func (s *RiskService) log(msg string, payment *domain.Payment, decision domain.RiskDecision) {
+	fields := []zap.Field{
+		zap.String("payment_id", payment.ID),
+		zap.String("user_id", payment.UserID),
+		zap.String("gateway", payment.Gateway),
+		zap.Int64("amount", payment.Amount),
+		zap.String("client_ip", payment.ClientIP),
+		zap.String("action", string(decision.Action)),
+		zap.Strings("reasons", decision.Reasons),
+	}
+	if decision.Action == domain.RiskAllow {
+		logger.Log.Info(msg, fields...)
+		return
+	}
+	logger.Log.Warn(msg, fields...)
+}
+
+// Block adds a mobile or card hash to the blocklist
+func (s *RiskService) Block(ctx context.Context, kind domain.BlocklistKind, value, reason, createdBy string) (*domain.BlocklistEntry, error) {
+	entry, err := domain.NewBlocklistEntry(kind, value, reason, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.riskRepo.Block(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Unblock removes a blocklist entry
+func (s *RiskService) Unblock(ctx context.Context, id string) error {
+	return s.riskRepo.Unblock(ctx, id)
+}
+
+// ListBlocked lists blocklist entries, of every kind when kind is empty
+func (s *RiskService) ListBlocked(ctx context.Context, kind domain.BlocklistKind, limit, offset int) ([]domain.BlocklistEntry, error) {
+	return s.riskRepo.ListBlocked(ctx, kind, limit, offset)
+}
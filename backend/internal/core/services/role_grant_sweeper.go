@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RoleGrantSweeper periodically removes expired role grants
+type RoleGrantSweeper struct {
+	rbacRepo ports.RBACRepository
+	versions ports.PermissionVersionStore
+	interval time.Duration
+}
+
+// NewRoleGrantSweeper creates a sweeper that runs every interval and
+// invalidates the embedded permissions of affected users
+func NewRoleGrantSweeper(rbacRepo ports.RBACRepository, versions ports.PermissionVersionStore, interval time.Duration) *RoleGrantSweeper {
+	return &RoleGrantSweeper{
+		rbacRepo: rbacRepo,
+		versions: versions,
+		interval: interval,
+	}
+}
+
+// Run sweeps expired grants until the context is cancelled
+func (s *RoleGrantSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep deletes all grants whose expiry has passed
+func (s *RoleGrantSweeper) Sweep(ctx context.Context) {
+	userIDs, err := s.rbacRepo.DeleteExpiredRoleGrants(ctx)
+	if err != nil {
+		logger.Log.Error("Failed to sweep expired role grants", zap.Error(err))
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	logger.Log.Info("Swept expired role grants", zap.Int("deleted", len(userIDs)))
+
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if err := s.versions.BumpUser(ctx, userID); err != nil {
+			logger.Log.Error("Failed to bump permission version", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+}
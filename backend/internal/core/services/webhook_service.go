@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/youruser/yourproject/internal/core/domain"
+	"github.com/youruser/yourproject/internal/core/ports"
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Refund events sent to webhook endpoints
+const (
+	EventRefundCreated   = domain.WebhookRefundCreated
+	EventRefundCompleted = domain.WebhookRefundCompleted
+	EventRefundFailed    = domain.WebhookRefundFailed
+)
+
+// webhookLease is how long a claimed delivery is hidden from other senders;
+// it outlasts any attempt so a delivery is only retried early if its
+// sender died
+const webhookLease = 2 * time.Minute
+
+// webhookEventNamespace derives event IDs from what the event is about, so
+// a listener called twice for the same change queues the event once
+var webhookEventNamespace = uuid.MustParse("b7b654e6-e515-40cd-a5a6-a58d1b9db320")
+
+// WebhookConfig controls webhook delivery
+type WebhookConfig struct {
+	// PollInterval is how often the queue is checked for due deliveries
+	// queued by other replicas and for retries
+	PollInterval time.Duration
+	// Concurrency is how many deliveries are sent at once
+	Concurrency int
+	// BatchSize bounds the deliveries sent in one pass
+	BatchSize int
+}
+
+// WebhookEvent is the JSON body posted to webhook endpoints
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type webhookPayment struct {
+	ID             string            `json:"id"`
+	OrganizationID string            `json:"organization_id,omitempty"`
+	OrderID        string            `json:"order_id,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Gateway        string            `json:"gateway"`
+	Amount         int64             `json:"amount"`
+	CouponCode     string            `json:"coupon_code,omitempty"`
+	Discount       int64             `json:"discount"`
+	RefundedAmount int64             `json:"refunded_amount"`
+	RefID          string            `json:"ref_id,omitempty"`
+	Status         string            `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type webhookRefund struct {
+	ID        string    `json:"id"`
+	PaymentID string    `json:"payment_id"`
+	Amount    int64     `json:"amount"`
+	Method    string    `json:"method"`
+	Status    string    `json:"status"`
+	Reference string    `json:"reference,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toWebhookPayment(p *domain.Payment) webhookPayment {
+	return webhookPayment{
+		ID:             p.ID,
+		OrganizationID: p.OrganizationID,
+		OrderID:        p.OrderID,
+		Metadata:       p.Metadata,
+		Gateway:        p.Gateway,
+		Amount:         p.Amount,
+		CouponCode:     p.CouponCode,
+		Discount:       p.Discount,
+		RefundedAmount: p.RefundedAmount,
+		RefID:          p.RefID,
+		Status:         string(p.Status),
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+func toWebhookRefund(r *domain.Refund) webhookRefund {
+	return webhookRefund{
+		ID:        r.ID,
+		PaymentID: r.PaymentID,
+		Amount:    r.Amount,
+		Method:    r.Method,
+		Status:    string(r.Status),
+		Reference: r.Reference,
+		Note:      r.Note,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// WebhookService tells merchant systems about payments and refunds. Events
+// are queued in the database for every subscribed endpoint of the payment's
+// organization and every app-wide endpoint, then posted with a signature
+// and retried with exponential backoff until they are accepted.
+type WebhookService struct {
+	webhookRepo ports.WebhookRepository
+	paymentRepo ports.PaymentRepository
+	sender      ports.WebhookSender
+	cfg         WebhookConfig
+	wake        chan struct{}
+}
+
+func NewWebhookService(webhookRepo ports.WebhookRepository, paymentRepo ports.PaymentRepository, sender ports.WebhookSender, cfg WebhookConfig) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		paymentRepo: paymentRepo,
+		sender:      sender,
+		cfg:         cfg,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// HandlePayment queues the payment's event. It is registered as a payment
+// listener.
+func (s *WebhookService) HandlePayment(ctx context.Context, payment *domain.Payment) {
+	eventType := paymentEvent(payment)
+	key := eventType + ":" + payment.ID
+	if eventType == EventPaymentRefunded {
+		// Every refund of a partially refunded payment is its own event
+		key += ":" + strconv.FormatInt(payment.RefundedAmount, 10)
+	}
+	s.publish(ctx, payment.OrganizationID, eventType, key, payment.UpdatedAt, map[string]interface{}{
+		"payment": toWebhookPayment(payment),
+	})
+}
+
+// HandleRefund queues the refund's event along with its payment. It is
+// registered as a refund listener.
+func (s *WebhookService) HandleRefund(ctx context.Context, refund *domain.Refund) {
+	var eventType string
+	switch refund.Status {
+	case domain.RefundRequested, domain.RefundPendingManual:
+		eventType = EventRefundCreated
+	case domain.RefundCompleted:
+		eventType = EventRefundCompleted
+	case domain.RefundFailed, domain.RefundRejected:
+		eventType = EventRefundFailed
+	default:
+		return
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		logger.Log.Error("Failed to load refunded payment for webhooks", zap.String("refund_id", refund.ID), zap.Error(err))
+		return
+	}
+	s.publish(ctx, payment.OrganizationID, eventType, eventType+":"+refund.ID, refund.UpdatedAt, map[string]interface{}{
+		"refund":  toWebhookRefund(refund),
+		"payment": toWebhookPayment(payment),
+	})
+}
+
+// publish queues an event for the endpoints subscribed to it. key names
+// what the event is about; events with the same key are queued once.
+func (s *WebhookService) publish(ctx context.Context, organizationID, eventType, key string, occurredAt time.Time, data interface{}) {
+	endpoints, err := s.webhookRepo.ListActiveEndpoints(ctx, organizationID)
+	if err != nil {
+		logger.Log.Error("Failed to list webhook endpoints", zap.String("event", eventType), zap.Error(err))
+		return
+	}
+
+	event := WebhookEvent{
+		ID:        uuid.NewSHA1(webhookEventNamespace, []byte(key)).String(),
+		Type:      eventType,
+		CreatedAt: occurredAt,
+		Data:      data,
+	}
+	var payload []byte
+	queued := false
+	for i := range endpoints {
+		if !endpoints[i].Subscribes(eventType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				logger.Log.Error("Failed to encode webhook event", zap.String("event", eventType), zap.Error(err))
+				return
+			}
+		}
+
+		ok, err := s.webhookRepo.Enqueue(ctx, domain.NewWebhookDelivery(endpoints[i].ID, event.ID, eventType, payload))
+		if err != nil {
+			logger.Log.Error("Failed to queue webhook delivery",
+				zap.String("endpoint_id", endpoints[i].ID), zap.String("event", eventType), zap.Error(err))
+			continue
+		}
+		queued = queued || ok
+	}
+	if queued {
+		s.Wake()
+	}
+}
+
+// Wake makes Run send due deliveries now instead of at its next poll
+func (s *WebhookService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Replay queues a delivery that succeeded or ran out of attempts to be sent
+// again now
+func (s *WebhookService) Replay(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if err := delivery.Replay(time.Now()); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	s.Wake()
+	return nil
+}
+
+// Run sends due deliveries until the context is cancelled
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.Dispatch(ctx)
+	}
+}
+
+// Dispatch sends up to BatchSize due deliveries. Replicas dispatch
+// concurrently; each delivery is claimed by one of them.
+func (s *WebhookService) Dispatch(ctx context.Context) {
+	var (
+		wg    sync.WaitGroup
+		taken atomic.Int64
+	)
+	for i := 0; i < s.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && taken.Add(1) <= int64(s.cfg.BatchSize) {
+				if !s.deliverNext(ctx) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliverNext sends the next due delivery and reports whether there was one
+func (s *WebhookService) deliverNext(ctx context.Context) bool {
+	delivery, err := s.webhookRepo.ClaimDue(ctx, webhookLease)
+	if errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		return false
+	}
+	if err != nil {
+		logger.Log.Error("Failed to claim webhook delivery", zap.Error(err))
+		return false
+	}
+
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		// The lease runs out and the delivery is tried again
+		logger.Log.Error("Failed to load webhook endpoint", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		return true
+	}
+
+	start := time.Now()
+	resp, sendErr := s.sender.Deliver(ctx, endpoint, delivery)
+	if ctx.Err() != nil {
+		// Shutting down; the attempt is not the endpoint's fault
+		return false
+	}
+
+	var statusCode int
+	var body string
+	if resp != nil {
+		statusCode, body = resp.StatusCode, resp.Body
+	}
+	attempt, err := delivery.RecordAttempt(statusCode, sendErr, time.Now())
+	if err != nil {
+		logger.Log.Error("Failed to record webhook attempt", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		return true
+	}
+	attempt.ResponseBody = body
+	attempt.Duration = time.Since(start)
+
+	if err := s.webhookRepo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		logger.Log.Error("Failed to save webhook attempt", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		return true
+	}
+	if delivery.Status == domain.WebhookFailed {
+		logger.Log.Warn("Webhook delivery failed for good",
+			zap.String("delivery_id", delivery.ID), zap.String("endpoint_id", endpoint.ID), zap.String("error", delivery.LastError))
+	}
+	return true
+}
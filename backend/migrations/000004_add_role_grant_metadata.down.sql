@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_user_roles_granted_by;
+DROP INDEX IF EXISTS idx_user_roles_expires_at;
+
+-- Collapse scoped grants back into a single global grant per role
+DELETE FROM user_roles WHERE scope <> '';
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+ALTER TABLE user_roles ADD PRIMARY KEY (user_id, role_id);
+
+ALTER TABLE user_roles DROP COLUMN IF EXISTS created_at;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS granted_by;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS scope;
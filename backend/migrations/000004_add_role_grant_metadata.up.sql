@@ -0,0 +1,12 @@
+-- Extend user_roles with grant metadata for time-bound and scoped assignments
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS scope VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS granted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- The same role may now be granted once per scope ('' means global)
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+ALTER TABLE user_roles ADD PRIMARY KEY (user_id, role_id, scope);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_expires_at ON user_roles(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_user_roles_granted_by ON user_roles(granted_by);
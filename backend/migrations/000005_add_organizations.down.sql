@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_roles_scope;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
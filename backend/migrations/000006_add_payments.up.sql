@@ -0,0 +1,29 @@
+-- Create payments table
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    gateway VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    description TEXT,
+    authority VARCHAR(255),
+    ref_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'created',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create payment_events table (status history with raw gateway responses)
+CREATE TABLE IF NOT EXISTS payment_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    raw_response JSONB,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payments_user_id ON payments(user_id);
+CREATE INDEX idx_payments_status ON payments(status);
+CREATE UNIQUE INDEX idx_payments_gateway_authority ON payments(gateway, authority) WHERE authority IS NOT NULL;
+CREATE INDEX idx_payment_events_payment_id ON payment_events(payment_id);
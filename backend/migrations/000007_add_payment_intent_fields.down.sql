@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payments_order_id;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS order_id;
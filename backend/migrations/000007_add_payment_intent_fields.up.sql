@@ -0,0 +1,6 @@
+-- Caller references recorded from the payment intent
+ALTER TABLE payments
+    ADD COLUMN order_id VARCHAR(255),
+    ADD COLUMN metadata JSONB;
+
+CREATE INDEX idx_payments_order_id ON payments(order_id) WHERE order_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_refunded_amount_check,
+    DROP COLUMN IF EXISTS refunded_amount;
@@ -0,0 +1,23 @@
+-- Track how much of a payment has been returned
+ALTER TABLE payments
+    ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT payments_refunded_amount_check CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+-- Create refunds table
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE RESTRICT,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    reason TEXT,
+    method VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reference VARCHAR(255),
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    processed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+CREATE INDEX idx_refunds_status ON refunds(status);
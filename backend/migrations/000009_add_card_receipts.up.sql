@@ -0,0 +1,24 @@
+-- Create card_receipts table (card-to-card transfers awaiting review)
+CREATE TABLE IF NOT EXISTS card_receipts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    tracking_number VARCHAR(64) NOT NULL,
+    image_key VARCHAR(1024) NOT NULL,
+    paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    reviewer_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_reason TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_card_receipts_payment_id ON card_receipts(payment_id);
+CREATE INDEX idx_card_receipts_status ON card_receipts(status, created_at);
+CREATE INDEX idx_card_receipts_user_id ON card_receipts(user_id);
+CREATE INDEX idx_card_receipts_tracking_number ON card_receipts(tracking_number);
+CREATE INDEX idx_card_receipts_amount_paid_at ON card_receipts(amount, paid_at);
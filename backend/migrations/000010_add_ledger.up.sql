@@ -0,0 +1,49 @@
+-- Create ledger_accounts table (balances are cached sums of postings)
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(30) NOT NULL,
+    owner_id VARCHAR(255) NOT NULL DEFAULT '',
+    balance BIGINT NOT NULL DEFAULT 0,
+    version BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (type, owner_id),
+    CHECK (type <> 'user_wallet' OR balance >= 0)
+);
+
+-- Create journal_entries table
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (kind, reference)
+);
+
+-- Create journal_postings table
+CREATE TABLE IF NOT EXISTS journal_postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    debit BIGINT NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit BIGINT NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    CHECK ((debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX idx_journal_postings_entry_id ON journal_postings(entry_id);
+CREATE INDEX idx_journal_postings_account_id ON journal_postings(account_id);
+
+-- The journal is append-only
+CREATE OR REPLACE FUNCTION prevent_journal_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'journal is immutable: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_immutable
+    BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION prevent_journal_change();
+
+CREATE TRIGGER journal_postings_immutable
+    BEFORE UPDATE OR DELETE ON journal_postings
+    FOR EACH ROW EXECUTE FUNCTION prevent_journal_change();
@@ -0,0 +1,56 @@
+-- Create plans table
+CREATE TABLE IF NOT EXISTS plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    period_days INTEGER NOT NULL CHECK (period_days > 0),
+    grace_days INTEGER NOT NULL DEFAULT 0 CHECK (grace_days >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create subscriptions table
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE RESTRICT,
+    pending_plan_id UUID REFERENCES plans(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL,
+    current_period_start TIMESTAMP WITH TIME ZONE,
+    current_period_end TIMESTAMP WITH TIME ZONE,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user has at most one current subscription
+CREATE UNIQUE INDEX idx_subscriptions_current_user ON subscriptions(user_id)
+    WHERE status IN ('pending', 'active', 'past_due');
+CREATE INDEX idx_subscriptions_period_end ON subscriptions(status, current_period_end);
+
+-- Create invoices table
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE RESTRICT,
+    kind VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    reminded_at TIMESTAMP WITH TIME ZONE,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The scheduler issues each renewal once however often it runs
+CREATE UNIQUE INDEX idx_invoices_period ON invoices(subscription_id, kind, period_start)
+    WHERE status <> 'void';
+CREATE INDEX idx_invoices_user_id ON invoices(user_id, created_at);
+CREATE INDEX idx_invoices_open ON invoices(status, reminded_at);
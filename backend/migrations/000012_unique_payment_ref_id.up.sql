@@ -0,0 +1,4 @@
+-- Bank PSPs verify by the reference posted to the callback, so a reference
+-- may only ever settle one payment. Card-to-card tracking numbers are typed
+-- in by users and are not unique.
+CREATE UNIQUE INDEX idx_payments_gateway_ref_id ON payments(gateway, ref_id) WHERE ref_id IS NOT NULL AND gateway <> 'cardtocard';
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS risk_blocklist;
+
+DROP INDEX IF EXISTS idx_payments_card_hash;
+DROP INDEX IF EXISTS idx_payments_client_ip_created_at;
+DROP INDEX IF EXISTS idx_payments_user_created_at;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS risk_reasons,
+    DROP COLUMN IF EXISTS risk_action,
+    DROP COLUMN IF EXISTS client_ip,
+    DROP COLUMN IF EXISTS card_hash,
+    DROP COLUMN IF EXISTS card_pan;
@@ -0,0 +1,24 @@
+-- Card details reported on verification and the payment's risk decision
+ALTER TABLE payments
+    ADD COLUMN card_pan VARCHAR(32),
+    ADD COLUMN card_hash VARCHAR(128),
+    ADD COLUMN client_ip VARCHAR(45),
+    ADD COLUMN risk_action VARCHAR(10),
+    ADD COLUMN risk_reasons TEXT[] NOT NULL DEFAULT '{}';
+
+-- Velocity rules count recent payments by user and by IP
+CREATE INDEX idx_payments_user_created_at ON payments(user_id, created_at);
+CREATE INDEX idx_payments_client_ip_created_at ON payments(client_ip, created_at) WHERE client_ip IS NOT NULL;
+CREATE INDEX idx_payments_card_hash ON payments(card_hash) WHERE card_hash IS NOT NULL;
+
+-- Mobiles and cards payments are refused or held for
+CREATE TABLE IF NOT EXISTS risk_blocklist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL,
+    value VARCHAR(128) NOT NULL,
+    reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_risk_blocklist_kind_value ON risk_blocklist(kind, value);
@@ -0,0 +1,27 @@
+-- Last receipt number issued in each series. Numbers are taken by updating
+-- the series row in the transaction that stores the receipt, so a failed
+-- insert rolls the number back and sequences have no gaps.
+CREATE TABLE IF NOT EXISTS receipt_sequences (
+    series VARCHAR(10) PRIMARY KEY,
+    last_value BIGINT NOT NULL
+);
+
+-- Official receipts for verified payments
+CREATE TABLE IF NOT EXISTS payment_receipts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    series VARCHAR(10) NOT NULL,
+    sequence BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    description TEXT,
+    gateway VARCHAR(50) NOT NULL,
+    ref_id VARCHAR(255),
+    card_pan VARCHAR(32),
+    paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    file_key TEXT,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payment_receipts_payment_id ON payment_receipts(payment_id);
+CREATE UNIQUE INDEX idx_payment_receipts_number ON payment_receipts(series, sequence);
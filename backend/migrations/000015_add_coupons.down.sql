@@ -0,0 +1,6 @@
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS coupon_code;
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,42 @@
+-- Promo codes discounting payments
+CREATE TABLE IF NOT EXISTS coupons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL,
+    value BIGINT NOT NULL,
+    max_discount BIGINT NOT NULL DEFAULT 0,
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    per_user_limit INTEGER NOT NULL DEFAULT 0,
+    -- Payments holding or having used the coupon; raised and checked against
+    -- max_redemptions in one statement so concurrent checkouts cannot
+    -- exceed the cap
+    redemptions INTEGER NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    plan_ids UUID[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_coupons_code ON coupons(code);
+
+-- A coupon's use by a payment
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    coupon_id UUID NOT NULL REFERENCES coupons(id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    discount BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_coupon_redemptions_payment_id ON coupon_redemptions(payment_id);
+CREATE INDEX idx_coupon_redemptions_coupon_user ON coupon_redemptions(coupon_id, user_id);
+
+ALTER TABLE payments
+    ADD COLUMN coupon_code VARCHAR(32),
+    ADD COLUMN discount BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,24 @@
+-- Create payouts table (amounts are held in the ledger while a payout is open)
+CREATE TABLE IF NOT EXISTS payouts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    sheba CHAR(26) NOT NULL,
+    bank_code CHAR(3) NOT NULL,
+    owner_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL,
+    gateway VARCHAR(50),
+    batch_id UUID,
+    reference VARCHAR(255),
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    processed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payouts_user_id ON payouts(user_id, created_at DESC);
+CREATE INDEX idx_payouts_status ON payouts(status, created_at);
+CREATE INDEX idx_payouts_batch_id ON payouts(batch_id) WHERE batch_id IS NOT NULL;
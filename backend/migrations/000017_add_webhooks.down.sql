@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS webhook_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+
+DROP INDEX IF EXISTS idx_payments_organization_id;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,61 @@
+-- Payments started in an organization notify its webhook endpoints
+ALTER TABLE payments
+    ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_payments_organization_id ON payments(organization_id, created_at DESC) WHERE organization_id IS NOT NULL;
+
+-- Merchant URLs payment and refund events are posted to; endpoints without
+-- an organization receive the events of every payment
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    description TEXT,
+    -- Event types the endpoint subscribes to; empty means all
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(128) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_endpoints_organization_id ON webhook_endpoints(organization_id);
+
+-- The delivery queue: one row per event and endpoint, retried until it
+-- succeeds or runs out of attempts
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    -- JSON rather than JSONB keeps the body byte for byte as it is signed
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Events are queued at most once per endpoint
+CREATE UNIQUE INDEX idx_webhook_deliveries_endpoint_event ON webhook_deliveries(endpoint_id, event_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_endpoint_created ON webhook_deliveries(endpoint_id, created_at DESC);
+
+-- The delivery log: every request sent and what the endpoint answered
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    response_body TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_attempts_delivery_id ON webhook_attempts(delivery_id, attempt);
@@ -0,0 +1,4 @@
+-- Remove the seeded roles and permissions; their grants cascade
+DELETE FROM roles WHERE name IN ('seller', 'org_owner', 'org_member');
+DELETE FROM permissions WHERE name IN ('payments:refund', 'payments:review', 'billing:manage', 'coupons:manage',
+    'payouts:request', 'payouts:approve', 'payouts:process', 'webhooks:manage', 'orgs:read', 'orgs:invite', 'orgs:manage');
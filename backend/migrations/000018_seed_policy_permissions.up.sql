@@ -0,0 +1,53 @@
+-- Seed the permissions and roles added to rbac.yaml after 000003, so their
+-- routes work before the policy is first synced
+
+-- Insert permissions
+INSERT INTO permissions (name, description, resource, action) VALUES
+    ('payments:refund', 'Refund payments and process manual refunds', 'payments', 'refund'),
+    ('payments:review', 'Review card-to-card receipts', 'payments', 'review'),
+    ('billing:manage', 'Manage subscription plans', 'billing', 'manage'),
+    ('coupons:manage', 'Manage discount codes', 'coupons', 'manage'),
+    ('payouts:request', 'Withdraw wallet credit to a bank account', 'payouts', 'request'),
+    ('payouts:approve', 'Approve and reject payout requests', 'payouts', 'approve'),
+    ('payouts:process', 'Dispatch payouts and confirm bank transfers', 'payouts', 'process'),
+    ('webhooks:manage', 'Manage webhook endpoints and replay their deliveries', 'webhooks', 'manage'),
+    ('orgs:read', 'View an organization and its members', 'orgs', 'read'),
+    ('orgs:invite', 'Invite members to an organization', 'orgs', 'invite'),
+    ('orgs:manage', 'Manage organization members', 'orgs', 'manage')
+ON CONFLICT (name) DO NOTHING;
+
+-- Insert roles
+INSERT INTO roles (name, description) VALUES
+    ('seller', 'Marketplace seller paid out from their wallet'),
+    ('org_owner', 'Owner of an organization'),
+    ('org_member', 'Member of an organization')
+ON CONFLICT (name) DO NOTHING;
+
+-- Assign permissions to admin role (all permissions)
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- Assign seller permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'seller' AND p.name IN ('payments:read', 'payments:write', 'payouts:request')
+ON CONFLICT DO NOTHING;
+
+-- Assign organization owner permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'org_owner' AND p.name IN ('orgs:read', 'orgs:invite', 'orgs:manage', 'files:read', 'files:write', 'files:delete',
+    'payments:read', 'payments:write', 'webhooks:manage')
+ON CONFLICT DO NOTHING;
+
+-- Assign organization member permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'org_member' AND p.name IN ('orgs:read', 'files:read', 'files:write', 'payments:read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payments_stale;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS reconciled_at;
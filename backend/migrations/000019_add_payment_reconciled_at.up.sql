@@ -0,0 +1,5 @@
+-- When the reconciler last checked a payment; stale payments are checked
+-- least recently checked first so unpaid ones cannot starve newer ones
+ALTER TABLE payments ADD COLUMN reconciled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_payments_stale ON payments(reconciled_at NULLS FIRST, updated_at) WHERE status IN ('redirected', 'pending');
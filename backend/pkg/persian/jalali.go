@@ -0,0 +1,73 @@
+package persian
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tehran is Iran Standard Time; Iran has not observed daylight saving since
+// 2022, so a fixed zone avoids depending on the tz database being installed
+var Tehran = time.FixedZone("IRST", 3*60*60+30*60)
+
+var monthNames = [12]string{
+	"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور",
+	"مهر", "آبان", "آذر", "دی", "بهمن", "اسفند",
+}
+
+// Date is a day in the Solar Hijri (Jalali) calendar
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// JalaliDate returns the Jalali date of t in Tehran
+func JalaliDate(t time.Time) Date {
+	gy, gm, gd := t.In(Tehran).Date()
+	return fromGregorian(gy, int(gm), gd)
+}
+
+// fromGregorian converts a Gregorian date using the 33-year cycle
+// arithmetic, which matches the official calendar for 1178 to 1633 SH
+func fromGregorian(gy, gm, gd int) Date {
+	daysBeforeMonth := [12]int{0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+
+	// Leap days are counted up to the current year once February is over
+	gy2 := gy
+	if gm > 2 {
+		gy2 = gy + 1
+	}
+	days := 355666 + 365*gy + (gy2+3)/4 - (gy2+99)/100 + (gy2+399)/400 + gd + daysBeforeMonth[gm-1]
+
+	jy := -1595 + 33*(days/12053)
+	days %= 12053
+	jy += 4 * (days / 1461)
+	days %= 1461
+	if days > 365 {
+		jy += (days - 1) / 365
+		days = (days - 1) % 365
+	}
+
+	if days < 186 {
+		return Date{Year: jy, Month: 1 + days/31, Day: 1 + days%31}
+	}
+	return Date{Year: jy, Month: 7 + (days-186)/30, Day: 1 + (days-186)%30}
+}
+
+// String formats the date as 1403/01/01 in Latin digits
+func (d Date) String() string {
+	return fmt.Sprintf("%04d/%02d/%02d", d.Year, d.Month, d.Day)
+}
+
+// Long formats the date as "۱ فروردین ۱۴۰۳"
+func (d Date) Long() string {
+	return Digits(fmt.Sprintf("%d %s %d", d.Day, MonthName(d.Month), d.Year))
+}
+
+// MonthName returns the Persian name of a Jalali month, 1 to 12
+func MonthName(month int) string {
+	if month < 1 || month > 12 {
+		return ""
+	}
+	return monthNames[month-1]
+}
@@ -0,0 +1,39 @@
+package persian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJalaliDate(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want Date
+	}{
+		{"Nowruz 1402", time.Date(2023, 3, 21, 12, 0, 0, 0, Tehran), Date{1402, 1, 1}},
+		{"Nowruz 1403", time.Date(2024, 3, 20, 12, 0, 0, 0, Tehran), Date{1403, 1, 1}},
+		{"Last Day Of Leap Year", time.Date(2025, 3, 20, 12, 0, 0, 0, Tehran), Date{1403, 12, 30}},
+		{"Nowruz 1404", time.Date(2025, 3, 21, 12, 0, 0, 0, Tehran), Date{1404, 1, 1}},
+		{"Start Of Autumn", time.Date(2024, 9, 22, 12, 0, 0, 0, Tehran), Date{1403, 7, 1}},
+		{"Gregorian Leap Day", time.Date(2024, 2, 29, 12, 0, 0, 0, Tehran), Date{1402, 12, 10}},
+		{"Year 2000", time.Date(2000, 1, 1, 12, 0, 0, 0, Tehran), Date{1378, 10, 11}},
+		{"UTC Evening Is Next Day In Tehran", time.Date(2024, 3, 19, 21, 0, 0, 0, time.UTC), Date{1403, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, JalaliDate(tt.time))
+		})
+	}
+}
+
+func TestDateFormat(t *testing.T) {
+	d := Date{Year: 1403, Month: 7, Day: 5}
+
+	assert.Equal(t, "1403/07/05", d.String())
+	assert.Equal(t, "۵ مهر ۱۴۰۳", d.Long())
+	assert.Equal(t, "", MonthName(13))
+}
@@ -0,0 +1,231 @@
+package persian
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const zwnj = '‌'
+
+// Digits replaces Latin digits with Persian ones
+func Digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '۰' + (r - '0')
+		}
+		return r
+	}, s)
+}
+
+// FormatAmount formats n with Persian digits and thousands separators, e.g.
+// ۱۲٬۵۰۰٬۰۰۰
+func FormatAmount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune('٬')
+		}
+		b.WriteRune(r)
+	}
+	return sign + Digits(b.String())
+}
+
+// letterForms are a letter's isolated, final, initial and medial
+// presentation forms. Right-joining letters have no initial or medial form.
+type letterForms [4]rune
+
+const (
+	formIsolated = iota
+	formFinal
+	formInitial
+	formMedial
+)
+
+var letters = map[rune]letterForms{
+	'ء': {0xFE80, 0, 0, 0},
+	'آ': {0xFE81, 0xFE82, 0, 0},
+	'أ': {0xFE83, 0xFE84, 0, 0},
+	'ؤ': {0xFE85, 0xFE86, 0, 0},
+	'إ': {0xFE87, 0xFE88, 0, 0},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ا': {0xFE8D, 0xFE8E, 0, 0},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'ة': {0xFE93, 0xFE94, 0, 0},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA, 0, 0},
+	'ذ': {0xFEAB, 0xFEAC, 0, 0},
+	'ر': {0xFEAD, 0xFEAE, 0, 0},
+	'ز': {0xFEAF, 0xFEB0, 0, 0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE, 0, 0},
+	'ى': {0xFEEF, 0xFEF0, 0, 0},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	'پ': {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	'چ': {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	'ژ': {0xFB8A, 0xFB8B, 0, 0},
+	'ک': {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	'گ': {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	'ی': {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlef are the isolated and final lam-alef ligatures by alef
+var lamAlef = map[rune][2]rune{
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+	'ا': {0xFEFB, 0xFEFC},
+}
+
+// joinsNext reports whether r connects to the letter after it
+func joinsNext(r rune) bool {
+	return letters[r][formInitial] != 0
+}
+
+// isTransparent reports whether r is a mark that does not interrupt joining
+func isTransparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// Shape replaces Arabic-script letters with the presentation forms their
+// neighbours call for, so fonts without shaping tables join them. Text stays
+// in logical order; zero-width non-joiners are dropped once applied.
+func Shape(s string) string {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+
+	// neighbour finds the closest letter before (step -1) or after (step 1)
+	// i, skipping marks
+	neighbour := func(i, step int) rune {
+		for j := i + step; j >= 0 && j < len(runes); j += step {
+			if !isTransparent(runes[j]) {
+				return runes[j]
+			}
+		}
+		return 0
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == zwnj {
+			continue
+		}
+		forms, ok := letters[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+
+		prev := neighbour(i, -1)
+		joinedBefore := joinsNext(prev)
+
+		if r == 'ل' && i+1 < len(runes) {
+			if lig, ok := lamAlef[runes[i+1]]; ok {
+				if joinedBefore {
+					out = append(out, lig[formFinal])
+				} else {
+					out = append(out, lig[formIsolated])
+				}
+				i++
+				continue
+			}
+		}
+
+		_, nextIsLetter := letters[neighbour(i, 1)]
+		joinedAfter := joinsNext(r) && nextIsLetter
+
+		switch {
+		case joinedBefore && joinedAfter:
+			out = append(out, forms[formMedial])
+		case joinedBefore:
+			out = append(out, forms[formFinal])
+		case joinedAfter:
+			out = append(out, forms[formInitial])
+		default:
+			out = append(out, forms[formIsolated])
+		}
+	}
+	return string(out)
+}
+
+// isRTL reports whether r is a strong right-to-left character
+func isRTL(r rune) bool {
+	return (unicode.Is(unicode.Arabic, r) || unicode.Is(unicode.Hebrew, r)) && (unicode.IsLetter(r) || unicode.IsMark(r))
+}
+
+// isLTR reports whether r starts or ends a left-to-right run: Latin letters
+// and digits, including Persian digits, which are written left to right
+func isLTR(r rune) bool {
+	return unicode.IsDigit(r) || (unicode.IsLetter(r) && !isRTL(r))
+}
+
+var mirrored = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+}
+
+// Visual reorders a single line of right-to-left text for renderers that
+// draw characters left to right. Runs of Latin text and numbers, with the
+// punctuation inside them, keep their order; everything else is reversed
+// with brackets mirrored. It is a simplification of the Unicode
+// bidirectional algorithm that suffices for labels and short values.
+func Visual(s string) string {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+
+	for end := len(runes); end > 0; {
+		if !isLTR(runes[end-1]) {
+			r := runes[end-1]
+			if m, ok := mirrored[r]; ok {
+				r = m
+			}
+			out = append(out, r)
+			end--
+			continue
+		}
+
+		// Extend the left-to-right run back to its first strong character
+		start := end - 1
+		for j := start - 1; j >= 0 && !isRTL(runes[j]); j-- {
+			if isLTR(runes[j]) {
+				start = j
+			}
+		}
+		out = append(out, runes[start:end]...)
+		end = start
+	}
+	return string(out)
+}
+
+// Display shapes and reorders a line of Persian text for drawing left to
+// right
+func Display(s string) string {
+	return Visual(Shape(s))
+}
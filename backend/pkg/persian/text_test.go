@@ -0,0 +1,67 @@
+package persian
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigits(t *testing.T) {
+	assert.Equal(t, "۱۴۰۳/۰۷/۰۵", Digits("1403/07/05"))
+	assert.Equal(t, "ref ۱۲", Digits("ref 12"))
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "۰", FormatAmount(0))
+	assert.Equal(t, "۹۹۹", FormatAmount(999))
+	assert.Equal(t, "۱٬۰۰۰", FormatAmount(1000))
+	assert.Equal(t, "۱۲٬۵۰۰٬۰۰۰", FormatAmount(12500000))
+	assert.Equal(t, "-۱۰۰٬۰۰۰", FormatAmount(-100000))
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []rune
+	}{
+		// ب initial, ه final
+		{"Joined Word", "به", []rune{0xFE91, 0xFEEA}},
+		// ر does not join the letter after it, so ا stays isolated
+		{"Right Joining Letter", "را", []rune{0xFEAD, 0xFE8D}},
+		// م initial, ی medial, ز final
+		{"Persian Letters", "میز", []rune{0xFEE3, 0xFBFF, 0xFEB0}},
+		{"Lam Alef", "لا", []rune{0xFEFB}},
+		{"Lam Alef Joined", "فلا", []rune{0xFED3, 0xFEFC}},
+		// The non-joiner splits می from شود and is dropped; و does not join د
+		{"Zero Width Non Joiner", "می‌شود", []rune{0xFEE3, 0xFBFD, 0xFEB7, 0xFEEE, 0xFEA9}},
+		{"Latin Untouched", "IR 12", []rune("IR 12")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, string(tt.want), Shape(tt.in))
+		})
+	}
+}
+
+func TestVisual(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Persian Reversed", "ابپ", "پبا"},
+		{"Number Keeps Order", "ابپ 1403/07/05", "1403/07/05 پبا"},
+		{"Persian Digits Keep Order", "مبلغ ۱۲٬۵۰۰", "۱۲٬۵۰۰ غلبم"},
+		{"Latin Run Keeps Order", "درگاه Zarinpal Pay", "Zarinpal Pay هاگرد"},
+		{"Brackets Mirrored", "(ریال)", "(لایر)"},
+		{"Latin Only", "Ref 12-34", "Ref 12-34"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Visual(tt.in))
+		})
+	}
+}
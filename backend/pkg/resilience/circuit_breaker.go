@@ -1,62 +1,359 @@
 package resilience
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"sync"
 	"time"
 )
 
-// Simple Circuit Breaker implementation
+// ErrCircuitOpen rejects calls while the breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrTooManyProbes rejects calls beyond the half-open probe limit; it
+// matches ErrCircuitOpen
+var ErrTooManyProbes = fmt.Errorf("%w: probe limit reached", ErrCircuitOpen)
+
+// State is a circuit breaker's state
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateOpen rejects every call until the reset timeout passes
+	StateOpen
+	// StateHalfOpen lets a bounded number of probes through
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "CLOSED"
+	case StateOpen:
+		return "OPEN"
+	case StateHalfOpen:
+		return "HALF_OPEN"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Settings configures a CircuitBreaker. Zero values take the defaults.
+type Settings struct {
+	// Window is the span calls are counted over, kept in Buckets slices
+	// that expire one at a time
+	Window  time.Duration
+	Buckets int
+	// FailureRate is the share of failed calls in the window, between 0 and
+	// 1, that opens the breaker once at least MinRequests were made
+	FailureRate float64
+	MinRequests int
+	// ResetTimeout is how long the breaker stays open before probing
+	ResetTimeout time.Duration
+	// HalfOpenProbes calls are let through at a time while half-open; the
+	// breaker closes after that many succeed and reopens on any failure
+	HalfOpenProbes int
+	// IsFailure decides whether an error counts against the dependency;
+	// DefaultIsFailure when nil
+	IsFailure func(err error) bool
+	// OnStateChange is called after every state change, outside the lock
+	OnStateChange func(name string, from, to State)
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Window:         time.Minute,
+		Buckets:        10,
+		FailureRate:    0.5,
+		MinRequests:    10,
+		ResetTimeout:   30 * time.Second,
+		HalfOpenProbes: 3,
+		IsFailure:      DefaultIsFailure,
+	}
+}
+
+func (s Settings) withDefaults() Settings {
+	defaults := DefaultSettings()
+	if s.Window <= 0 {
+		s.Window = defaults.Window
+	}
+	if s.Buckets <= 0 {
+		s.Buckets = defaults.Buckets
+	}
+	if s.FailureRate <= 0 || s.FailureRate > 1 {
+		s.FailureRate = defaults.FailureRate
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = defaults.MinRequests
+	}
+	if s.ResetTimeout <= 0 {
+		s.ResetTimeout = defaults.ResetTimeout
+	}
+	if s.HalfOpenProbes <= 0 {
+		s.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = defaults.IsFailure
+	}
+	return s
+}
+
+// StatusError reports an HTTP response that was not successful
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server responded with status %d", e.StatusCode)
+}
+
+// DefaultIsFailure counts every error except the caller canceling and
+// 4xx responses, which are the dependency answering a bad request. Timeouts
+// (408) and rate limiting (429) still count.
+func DefaultIsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode < 500 {
+		return statusErr.StatusCode == http.StatusRequestTimeout || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+type bucket struct {
+	start    time.Time
+	requests int
+	failures int
+}
+
+// window counts calls over a rolling period split into buckets
+type window struct {
+	buckets []bucket
+	width   time.Duration
+}
+
+func newWindow(span time.Duration, buckets int) window {
+	width := span / time.Duration(buckets)
+	if width <= 0 {
+		width = 1
+	}
+	return window{buckets: make([]bucket, buckets), width: width}
+}
+
+func (w *window) add(now time.Time, failure bool) {
+	start := now.Truncate(w.width)
+	b := &w.buckets[int(start.UnixNano()/int64(w.width))%len(w.buckets)]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.requests++
+	if failure {
+		b.failures++
+	}
+}
+
+func (w *window) totals(now time.Time) (requests, failures int) {
+	span := w.width * time.Duration(len(w.buckets))
+	for _, b := range w.buckets {
+		if now.Sub(b.start) < span {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
+
+func (w *window) reset() {
+	clear(w.buckets)
+}
+
+type transition struct {
+	from, to State
+}
+
+// CircuitBreaker stops calling a dependency whose failure rate over a
+// rolling window crosses a threshold, then probes it with a bounded number
+// of calls before closing again.
 type CircuitBreaker struct {
-	mu           sync.RWMutex
-	failureCount int
-	threshold    int
-	resetTimeout time.Duration
-	lastFailure  time.Time
-	state        string // "CLOSED", "OPEN", "HALF_OPEN"
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu             sync.Mutex
+	state          State
+	since          time.Time
+	generation     uint64
+	window         window
+	probes         int
+	probeSuccesses int
+	pending        []transition
 }
 
-func NewCircuitBreaker(threshold int, resetTimeout time.Duration) *CircuitBreaker {
+func NewCircuitBreaker(name string, settings Settings) *CircuitBreaker {
+	settings = settings.withDefaults()
 	return &CircuitBreaker{
-		threshold:    threshold,
-		resetTimeout: resetTimeout,
-		state:        "CLOSED",
+		name:     name,
+		settings: settings,
+		now:      time.Now,
+		since:    time.Now(),
+		window:   newWindow(settings.Window, settings.Buckets),
+	}
+}
+
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// State returns the current state; an open breaker whose reset timeout has
+// passed reports half-open
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.refresh(cb.now())
+	return cb.state
+}
+
+// Status is a snapshot of a breaker
+type Status struct {
+	Name  string
+	State State
+	Since time.Time
+	// Requests and Failures are counted over the window while closed
+	Requests int
+	Failures int
+}
+
+func (cb *CircuitBreaker) Status() Status {
+	cb.mu.Lock()
+	defer cb.unlock()
+	now := cb.now()
+	cb.refresh(now)
+	requests, failures := cb.window.totals(now)
+	return Status{Name: cb.name, State: cb.state, Since: cb.since, Requests: requests, Failures: failures}
+}
+
+// Execute calls fn through the breaker. Errors the classifier does not
+// count as failures are returned without affecting the breaker.
+func Execute[T any](cb *CircuitBreaker, fn func() (T, error)) (T, error) {
+	generation, err := cb.before()
+	if err != nil {
+		var zero T
+		return zero, err
 	}
+
+	finished := false
+	defer func() {
+		if !finished {
+			// fn panicked
+			cb.after(generation, true)
+		}
+	}()
+	result, err := fn()
+	finished = true
+	cb.after(generation, cb.settings.IsFailure(err))
+	return result, err
 }
 
+// Execute calls fn through the breaker, see the Execute function
 func (cb *CircuitBreaker) Execute(fn func() error) error {
+	_, err := Execute(cb, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// IsFailure reports whether the breaker counts err as a failure
+func (cb *CircuitBreaker) IsFailure(err error) bool {
+	return cb.settings.IsFailure(err)
+}
+
+func (cb *CircuitBreaker) before() (uint64, error) {
 	cb.mu.Lock()
-	if cb.state == "OPEN" {
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			cb.state = "HALF_OPEN"
-		} else {
-			cb.mu.Unlock()
-			return errors.New("circuit breaker is open")
+	defer cb.unlock()
+	cb.refresh(cb.now())
+
+	switch cb.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.probes >= cb.settings.HalfOpenProbes {
+			return 0, ErrTooManyProbes
 		}
+		cb.probes++
 	}
-	cb.mu.Unlock()
-
-	err := fn()
+	return cb.generation, nil
+}
 
+func (cb *CircuitBreaker) after(generation uint64, failure bool) {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	defer cb.unlock()
+	now := cb.now()
+	cb.refresh(now)
+	if generation != cb.generation {
+		// The state changed while the call ran; its result says nothing
+		// about the new state
+		return
+	}
 
-	if err != nil {
-		cb.failureCount++
-		cb.lastFailure = time.Now()
-		if cb.failureCount >= cb.threshold {
-			cb.state = "OPEN"
+	switch cb.state {
+	case StateClosed:
+		cb.window.add(now, failure)
+		if !failure {
+			return
+		}
+		requests, failures := cb.window.totals(now)
+		if requests >= cb.settings.MinRequests && float64(failures) >= cb.settings.FailureRate*float64(requests) {
+			cb.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.probes--
+		if failure {
+			cb.setState(StateOpen, now)
+			return
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.settings.HalfOpenProbes {
+			cb.setState(StateClosed, now)
 		}
-		return err
 	}
+}
 
-	// Success
-	if cb.state == "HALF_OPEN" {
-		cb.state = "CLOSED"
-		cb.failureCount = 0
-	} else if cb.state == "CLOSED" {
-		cb.failureCount = 0
+// refresh moves an open breaker to half-open once the reset timeout
+// passed; mu must be held
+func (cb *CircuitBreaker) refresh(now time.Time) {
+	if cb.state == StateOpen && now.Sub(cb.since) >= cb.settings.ResetTimeout {
+		cb.setState(StateHalfOpen, now)
 	}
+}
 
-	return nil
+// setState must be called with mu held; the hook runs once it is released
+func (cb *CircuitBreaker) setState(state State, now time.Time) {
+	if cb.state == state {
+		return
+	}
+	cb.pending = append(cb.pending, transition{from: cb.state, to: state})
+	cb.state = state
+	cb.since = now
+	cb.generation++
+	cb.window.reset()
+	cb.probes = 0
+	cb.probeSuccesses = 0
+}
+
+// unlock releases mu and runs the hook for the state changes made while it
+// was held
+func (cb *CircuitBreaker) unlock() {
+	pending := cb.pending
+	cb.pending = nil
+	cb.mu.Unlock()
+
+	if cb.settings.OnStateChange == nil {
+		return
+	}
+	for _, t := range pending {
+		cb.settings.OnStateChange(cb.name, t.from, t.to)
+	}
 }
@@ -0,0 +1,201 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func fail() error    { return errUnavailable }
+func succeed() error { return nil }
+
+func callN(cb *CircuitBreaker, n int, fn func() error) {
+	for i := 0; i < n; i++ {
+		_ = cb.Execute(fn)
+	}
+}
+
+func newTestBreaker(settings Settings) (*CircuitBreaker, *clock) {
+	c := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker("test", settings)
+	cb.now = c.Now
+	cb.since = c.Now()
+	return cb, c
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		failures  int
+		wantState State
+	}{
+		{name: "Below Minimum Volume", failures: 4, wantState: StateClosed},
+		{name: "Below Failure Rate", successes: 6, failures: 4, wantState: StateClosed},
+		{name: "At Failure Rate", successes: 5, failures: 5, wantState: StateOpen},
+		{name: "All Failing", failures: 10, wantState: StateOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, _ := newTestBreaker(Settings{FailureRate: 0.5, MinRequests: 10})
+			callN(cb, tt.successes, succeed)
+			callN(cb, tt.failures, fail)
+			assert.Equal(t, tt.wantState, cb.State())
+		})
+	}
+}
+
+func TestCircuitBreakerWindowExpires(t *testing.T) {
+	cb, c := newTestBreaker(Settings{Window: time.Minute, Buckets: 6, FailureRate: 0.5, MinRequests: 4})
+
+	callN(cb, 3, fail)
+	c.Advance(time.Minute)
+	callN(cb, 1, fail)
+	assert.Equal(t, StateClosed, cb.State(), "failures older than the window no longer count")
+
+	c.Advance(10 * time.Second)
+	callN(cb, 3, fail)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestCircuitBreakerClassifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		failure bool
+	}{
+		{name: "Nil", err: nil, failure: false},
+		{name: "Transport Error", err: errUnavailable, failure: true},
+		{name: "Server Error", err: &StatusError{StatusCode: 503}, failure: true},
+		{name: "Wrapped Server Error", err: fmt.Errorf("verify: %w", &StatusError{StatusCode: 500}), failure: true},
+		{name: "Client Error", err: &StatusError{StatusCode: 404}, failure: false},
+		{name: "Rate Limited", err: &StatusError{StatusCode: 429}, failure: true},
+		{name: "Request Timeout", err: &StatusError{StatusCode: 408}, failure: true},
+		{name: "Deadline", err: context.DeadlineExceeded, failure: true},
+		{name: "Canceled", err: context.Canceled, failure: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.failure, DefaultIsFailure(tt.err))
+
+			cb, _ := newTestBreaker(Settings{FailureRate: 1, MinRequests: 1})
+			err := cb.Execute(func() error { return tt.err })
+			assert.Equal(t, tt.err, err, "the error is returned either way")
+			wantState := StateClosed
+			if tt.failure {
+				wantState = StateOpen
+			}
+			assert.Equal(t, wantState, cb.State())
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		probes    []error
+		wantState State
+	}{
+		{name: "Probes Succeed", probes: []error{nil, nil}, wantState: StateClosed},
+		{name: "Probe Fails", probes: []error{nil, errUnavailable}, wantState: StateOpen},
+		{name: "Probes Pending", probes: []error{nil}, wantState: StateHalfOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, c := newTestBreaker(Settings{FailureRate: 1, MinRequests: 1, ResetTimeout: 30 * time.Second, HalfOpenProbes: 2})
+			callN(cb, 1, fail)
+			assert.True(t, errors.Is(cb.Execute(succeed), ErrCircuitOpen))
+
+			c.Advance(30 * time.Second)
+			assert.Equal(t, StateHalfOpen, cb.State())
+			for _, err := range tt.probes {
+				_ = cb.Execute(func() error { return err })
+			}
+			assert.Equal(t, tt.wantState, cb.State())
+		})
+	}
+}
+
+func TestCircuitBreakerLimitsProbes(t *testing.T) {
+	cb, c := newTestBreaker(Settings{FailureRate: 1, MinRequests: 1, ResetTimeout: time.Second, HalfOpenProbes: 1})
+	callN(cb, 1, fail)
+	c.Advance(time.Second)
+
+	var nested error
+	err := cb.Execute(func() error {
+		nested = cb.Execute(succeed)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(nested, ErrTooManyProbes))
+	assert.True(t, errors.Is(nested, ErrCircuitOpen))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestCircuitBreakerStateChangeHook(t *testing.T) {
+	var transitions []string
+	cb, c := newTestBreaker(Settings{
+		FailureRate:    1,
+		MinRequests:    1,
+		ResetTimeout:   time.Second,
+		HalfOpenProbes: 1,
+		OnStateChange: func(name string, from, to State) {
+			transitions = append(transitions, fmt.Sprintf("%s:%s->%s", name, from, to))
+		},
+	})
+
+	callN(cb, 1, fail)
+	c.Advance(time.Second)
+	callN(cb, 1, succeed)
+
+	assert.Equal(t, []string{"test:CLOSED->OPEN", "test:OPEN->HALF_OPEN", "test:HALF_OPEN->CLOSED"}, transitions)
+}
+
+func TestExecuteReturnsResult(t *testing.T) {
+	cb, _ := newTestBreaker(Settings{FailureRate: 0.5, MinRequests: 2})
+
+	result, err := Execute(cb, func() (int, error) { return 42, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+
+	_, _ = Execute(cb, func() (int, error) { return 0, errUnavailable })
+	result, err = Execute(cb, func() (int, error) { return 42, nil })
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 0, result)
+}
+
+func TestRegistryStatuses(t *testing.T) {
+	registry := NewRegistry()
+	zarinpal := NewCircuitBreaker("zarinpal", Settings{FailureRate: 1, MinRequests: 1})
+	registry.Register(zarinpal)
+	registry.Register(NewCircuitBreaker("idpay", Settings{}))
+	_ = zarinpal.Execute(fail)
+
+	statuses := registry.Statuses()
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, "idpay", statuses[0].Name)
+		assert.Equal(t, StateClosed, statuses[0].State)
+		assert.Equal(t, "zarinpal", statuses[1].Name)
+		assert.Equal(t, StateOpen, statuses[1].State)
+	}
+
+	cb, ok := registry.Get("zarinpal")
+	assert.True(t, ok)
+	assert.Same(t, zarinpal, cb)
+}
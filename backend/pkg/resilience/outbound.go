@@ -0,0 +1,192 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/youruser/yourproject/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Policy configures calls to one outbound dependency
+type Policy struct {
+	// Timeout bounds each attempt; a shorter deadline on the caller's
+	// context still wins
+	Timeout time.Duration
+	// Breaker configures the dependency's circuit breaker; the Dependency
+	// sets its OnStateChange
+	Breaker Settings
+	// MaxAttempts is how often idempotent calls are tried, including the
+	// first; other calls are tried once
+	MaxAttempts int
+	// BaseDelay doubles after every retry up to MaxDelay; the actual delay is
+	// picked at random below it
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Timeout:     5 * time.Second,
+		Breaker:     DefaultSettings(),
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+}
+
+type idempotentKey struct{}
+
+// Idempotent marks calls made with ctx as safe to repeat, e.g. verifying a
+// payment, so they are retried
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// IsIdempotent reports whether ctx was marked with Idempotent
+func IsIdempotent(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+type metrics struct {
+	calls       metric.Int64Counter
+	retries     metric.Int64Counter
+	transitions metric.Int64Counter
+}
+
+func newMetrics() metrics {
+	meter := otel.Meter("github.com/youruser/yourproject/pkg/resilience")
+	calls, _ := meter.Int64Counter("outbound.calls", metric.WithDescription("Outbound call attempts by dependency and outcome"))
+	retries, _ := meter.Int64Counter("outbound.retries", metric.WithDescription("Retried outbound calls by dependency"))
+	transitions, _ := meter.Int64Counter("outbound.breaker.transitions", metric.WithDescription("Circuit breaker state changes by dependency"))
+	return metrics{calls: calls, retries: retries, transitions: transitions}
+}
+
+// Dependency guards calls to one external service with a circuit breaker,
+// per-attempt timeouts and, for idempotent calls, retries with exponential
+// backoff and jitter. Breaker state changes are logged and counted.
+type Dependency struct {
+	Name    string
+	policy  Policy
+	breaker *CircuitBreaker
+	metrics metrics
+	log     *zap.Logger
+}
+
+func NewDependency(name string, policy Policy) *Dependency {
+	log := logger.Log
+	if log == nil {
+		log = zap.NewNop()
+	}
+	d := &Dependency{
+		Name:    name,
+		policy:  policy,
+		metrics: newMetrics(),
+		log:     log,
+	}
+	settings := policy.Breaker
+	settings.OnStateChange = d.stateChanged
+	d.breaker = NewCircuitBreaker(name, settings)
+	return d
+}
+
+// Breaker returns the dependency's circuit breaker
+func (d *Dependency) Breaker() *CircuitBreaker {
+	return d.breaker
+}
+
+// State returns the breaker's state
+func (d *Dependency) State() State {
+	return d.breaker.State()
+}
+
+func (d *Dependency) stateChanged(_ string, from, to State) {
+	fields := []zap.Field{zap.String("dependency", d.Name), zap.Stringer("from", from), zap.Stringer("to", to)}
+	if to == StateOpen {
+		d.log.Warn("Circuit breaker opened", fields...)
+	} else {
+		d.log.Info("Circuit breaker state changed", fields...)
+	}
+	d.metrics.transitions.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("dependency", d.Name), attribute.String("from", from.String()), attribute.String("to", to.String()),
+	))
+}
+
+// Do calls fn through the breaker with a context bounded by the policy's
+// timeout. Calls made with an Idempotent context are retried while fn fails
+// with an error the breaker counts, the breaker lets calls through and the
+// caller's deadline leaves room for the backoff.
+func (d *Dependency) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := 1
+	if IsIdempotent(ctx) && d.policy.MaxAttempts > 1 {
+		attempts = d.policy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := d.breaker.Execute(func() error {
+			if d.policy.Timeout <= 0 {
+				return fn(ctx)
+			}
+			attemptCtx, cancel := context.WithTimeout(ctx, d.policy.Timeout)
+			defer cancel()
+			return fn(attemptCtx)
+		})
+		d.record(ctx, err)
+		if err == nil || attempt >= attempts || !d.retryable(ctx, err) {
+			return err
+		}
+
+		delay := d.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		d.log.Info("Retrying outbound call", zap.String("dependency", d.Name), zap.Int("attempt", attempt+1), zap.Duration("delay", delay), zap.Error(err))
+		d.metrics.retries.Add(ctx, 1, metric.WithAttributes(attribute.String("dependency", d.Name)))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *Dependency) record(ctx context.Context, err error) {
+	outcome := "success"
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		outcome = "rejected"
+	case d.breaker.IsFailure(err):
+		outcome = "failure"
+	case err != nil:
+		outcome = "error"
+	}
+	d.metrics.calls.Add(ctx, 1, metric.WithAttributes(attribute.String("dependency", d.Name), attribute.String("outcome", outcome)))
+}
+
+// retryable rejects errors retrying cannot fix: an open breaker, errors the
+// breaker does not count, such as 4xx responses, and the caller giving up
+func (d *Dependency) retryable(ctx context.Context, err error) bool {
+	return !errors.Is(err, ErrCircuitOpen) && d.breaker.IsFailure(err) && ctx.Err() == nil
+}
+
+// backoff returns a random delay below BaseDelay*2^(attempt-1), capped at
+// MaxDelay ("full jitter")
+func (d *Dependency) backoff(attempt int) time.Duration {
+	ceiling := d.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > d.policy.MaxDelay {
+		ceiling = d.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
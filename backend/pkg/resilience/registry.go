@@ -0,0 +1,48 @@
+package resilience
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry keeps the process's circuit breakers by name so their state can
+// be inspected
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Register adds cb, replacing any breaker with the same name
+func (r *Registry) Register(cb *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[cb.Name()] = cb
+}
+
+func (r *Registry) Get(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// Statuses returns a snapshot of every breaker, ordered by name
+func (r *Registry) Statuses() []Status {
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(breakers))
+	for _, cb := range breakers {
+		statuses = append(statuses, cb.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
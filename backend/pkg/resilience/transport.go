@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Transport sends HTTP requests through a Dependency. Responses of 400 and
+// above are reported to the breaker as a StatusError, which its classifier
+// may ignore, and are still returned to the caller.
+//
+// Responses are read in full within the attempt so the attempt timeout
+// covers the body. Requests are retried only when their context is marked
+// Idempotent and their body can be replayed.
+type Transport struct {
+	Dependency *Dependency
+	// Base sends the requests; http.DefaultTransport when nil
+	Base http.RoundTripper
+}
+
+func NewTransport(dependency *Dependency) *Transport {
+	return &Transport{Dependency: dependency}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil && IsIdempotent(ctx) {
+		// The body cannot be sent twice
+		ctx = context.WithValue(ctx, idempotentKey{}, false)
+	}
+
+	var resp *http.Response
+	first := true
+	err := t.Dependency.Do(ctx, func(ctx context.Context) error {
+		attempt := req.Clone(ctx)
+		if !first && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			attempt.Body = body
+		}
+		first = false
+
+		r, err := base.RoundTrip(attempt)
+		if err != nil {
+			resp = nil
+			return err
+		}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			resp = nil
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		resp = r
+
+		if r.StatusCode >= 400 {
+			return &StatusError{StatusCode: r.StatusCode}
+		}
+		return nil
+	})
+
+	// A failing response is still the dependency's answer, so the caller
+	// gets the last one
+	var statusErr *StatusError
+	if resp != nil && (err == nil || errors.As(err, &statusErr)) {
+		return resp, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,171 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPolicy() Policy {
+	return Policy{
+		Timeout:     time.Second,
+		Breaker:     Settings{FailureRate: 1, MinRequests: 3, ResetTimeout: time.Minute},
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+}
+
+// newTestServer answers requests with the statuses in order, repeating the
+// last one, and counts the requests it received
+func newTestServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.WriteHeader(statuses[n-1])
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		idempotent bool
+		wantStatus int
+		wantHits   int32
+	}{
+		{name: "Success", statuses: []int{200}, idempotent: true, wantStatus: 200, wantHits: 1},
+		{name: "Idempotent Retried", statuses: []int{503, 502, 200}, idempotent: true, wantStatus: 200, wantHits: 3},
+		{name: "Idempotent Gives Up", statuses: []int{500}, idempotent: true, wantStatus: 500, wantHits: 3},
+		{name: "Rate Limited Retried", statuses: []int{429, 200}, idempotent: true, wantStatus: 200, wantHits: 2},
+		{name: "Client Error Not Retried", statuses: []int{400, 200}, idempotent: true, wantStatus: 400, wantHits: 1},
+		{name: "Not Idempotent", statuses: []int{503, 200}, wantStatus: 503, wantHits: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, hits := newTestServer(t, tt.statuses...)
+			client := &http.Client{Transport: NewTransport(NewDependency("test", testPolicy()))}
+
+			ctx := context.Background()
+			if tt.idempotent {
+				ctx = Idempotent(ctx)
+			}
+			req, _ := http.NewRequestWithContext(ctx, "POST", server.URL, bytes.NewBufferString(`{"authority":"abc"}`))
+			resp, err := client.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, `{"authority":"abc"}`, string(body), "the body is replayed on every attempt")
+			assert.Equal(t, tt.wantHits, hits.Load())
+		})
+	}
+}
+
+func TestTransportUnreplayableBody(t *testing.T) {
+	server, hits := newTestServer(t, 503, 200)
+	client := &http.Client{Transport: NewTransport(NewDependency("test", testPolicy()))}
+
+	req, _ := http.NewRequestWithContext(Idempotent(context.Background()), "POST", server.URL, io.NopCloser(bytes.NewBufferString("data")))
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestTransportOpensBreaker(t *testing.T) {
+	server, hits := newTestServer(t, 500)
+	dependency := NewDependency("test", testPolicy())
+	client := &http.Client{Transport: NewTransport(dependency)}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, StateOpen, dependency.State())
+
+	_, err := client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen), "calls are rejected while the breaker is open")
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestTransportAttemptTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	policy := testPolicy()
+	policy.Timeout = 20 * time.Millisecond
+	client := &http.Client{Transport: NewTransport(NewDependency("test", policy))}
+
+	start := time.Now()
+	_, err := client.Get(server.URL)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestDoRespectsCallerDeadline(t *testing.T) {
+	policy := testPolicy()
+	policy.BaseDelay = time.Second
+	policy.MaxDelay = time.Second
+	dependency := NewDependency("test", policy)
+
+	ctx, cancel := context.WithTimeout(Idempotent(context.Background()), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	failure := errors.New("unavailable")
+	err := dependency.Do(ctx, func(ctx context.Context) error {
+		calls++
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, calls, "no retry when the backoff would outlive the caller's deadline")
+}
+
+func TestBackoff(t *testing.T) {
+	dependency := NewDependency("test", Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond})
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 300 * time.Millisecond},
+		{attempt: 10, max: 300 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			delay := dependency.backoff(tt.attempt)
+			assert.Greater(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, tt.max)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signedAt = time.Unix(1700000000, 0)
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	assert.NoError(t, err)
+	b, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(a, SecretPrefix))
+	assert.Len(t, a, len(SecretPrefix)+48)
+	assert.NotEqual(t, a, b)
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("whsec_test", "1700000000.{\"id\":\"ev1\"}")
+	assert.Equal(t, "2ef21d9b0dc37e2e3bb5d9645556fee8725dd0aa257779dc2acd911493c8c365", Sign("whsec_test", signedAt, []byte(`{"id":"ev1"}`)))
+	assert.Equal(t, "t=1700000000,v1=2ef21d9b0dc37e2e3bb5d9645556fee8725dd0aa257779dc2acd911493c8c365", Header("whsec_test", signedAt, []byte(`{"id":"ev1"}`)))
+	assert.NotEqual(t, Sign("whsec_test", signedAt, []byte(`{}`)), Sign("whsec_other", signedAt, []byte(`{}`)))
+	assert.NotEqual(t, Sign("whsec_test", signedAt, []byte(`{}`)), Sign("whsec_test", signedAt.Add(time.Second), []byte(`{}`)))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"ev1","type":"payment.verified"}`)
+	header := Header("whsec_test", signedAt, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Valid", secret: "whsec_test", header: header, body: body, now: signedAt.Add(time.Minute)},
+		{name: "Rotated Secret", secret: "whsec_test", header: header + ",v1=" + Sign("whsec_old", signedAt, body), body: body, now: signedAt},
+		{name: "Spaces", secret: "whsec_test", header: strings.ReplaceAll(header, ",", ", "), body: body, now: signedAt},
+		{name: "Wrong Secret", secret: "whsec_other", header: header, body: body, now: signedAt, wantErr: ErrInvalidSignature},
+		{name: "Tampered Body", secret: "whsec_test", header: header, body: []byte(`{"id":"ev2","type":"payment.verified"}`), now: signedAt, wantErr: ErrInvalidSignature},
+		{name: "Tampered Timestamp", secret: "whsec_test", header: strings.Replace(header, "t=1700000000", "t=1700000001", 1), body: body, now: signedAt, wantErr: ErrInvalidSignature},
+		{name: "Too Old", secret: "whsec_test", header: header, body: body, now: signedAt.Add(DefaultTolerance + time.Second), wantErr: ErrSignatureExpired},
+		{name: "From The Future", secret: "whsec_test", header: header, body: body, now: signedAt.Add(-DefaultTolerance - time.Second), wantErr: ErrSignatureExpired},
+		{name: "No Signature", secret: "whsec_test", header: "t=1700000000", body: body, now: signedAt, wantErr: ErrMissingSignature},
+		{name: "Bad Timestamp", secret: "whsec_test", header: "t=yesterday,v1=abc", body: body, now: signedAt, wantErr: ErrMissingSignature},
+		{name: "Empty", secret: "whsec_test", header: "", body: body, now: signedAt, wantErr: ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, DefaultTolerance, tt.now)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}